@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/art"
+	"github.com/sethgrid/familiar/internal/cast"
+	"github.com/sethgrid/familiar/internal/output"
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/sprite"
+	"github.com/sethgrid/familiar/internal/storage"
+	"github.com/sethgrid/familiar/internal/term"
+	"github.com/spf13/cobra"
+)
+
+var adminArtImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a PNG or GIF sprite as a pixel animation",
+	Long: `Import a PNG, animated GIF or sprite sheet as the pixel animation of a state.
+
+The image is downscaled to --grid (by default it keeps its size, shrunk to fit
+32 pixels), its colours are reduced to --max-colors, and pixels less than half
+opaque become transparent. Each GIF frame keeps its delay. A sprite sheet is
+split with --sheet COLSxROWS and read left to right, top to bottom.
+
+The animation replaces any existing one for the state in your familiar's
+pet.toml, or in a template with --type. The rest of the file is kept as is.
+
+Examples:
+  familiar admin art import happy.gif --state happy
+  familiar admin art import walk.png --state default --sheet 4x1 --grid 16x16
+  familiar admin art import hungry.png --state hungry --evolution 2 --type pixel`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, _ := cmd.Flags().GetString("state")
+		evolution, _ := cmd.Flags().GetInt("evolution")
+		gridFlag, _ := cmd.Flags().GetString("grid")
+		sheetFlag, _ := cmd.Flags().GetString("sheet")
+		maxColors, _ := cmd.Flags().GetInt("max-colors")
+		fps, _ := cmd.Flags().GetInt("fps")
+		loops, _ := cmd.Flags().GetInt("loops")
+		typeOverride, _ := cmd.Flags().GetString("type")
+
+		if state == "" {
+			return output.Errorf(output.CodeUsage, "--state is required, e.g. --state happy")
+		}
+
+		opts := sprite.Options{MaxColors: maxColors}
+		var err error
+		if gridFlag != "" {
+			if opts.Grid, err = sprite.ParseSize(gridFlag); err != nil {
+				return output.WithCode(output.CodeUsage, err)
+			}
+		}
+		if sheetFlag != "" {
+			if opts.Sheet, err = sprite.ParseSize(sheetFlag); err != nil {
+				return output.WithCode(output.CodeUsage, err)
+			}
+		}
+
+		key := state
+		if evolution > 0 {
+			key = fmt.Sprintf("e%d:%s", evolution, state)
+		}
+
+		p, configPath, err := artConfig(typeOverride)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open image: %w", err)
+		}
+		defer f.Close()
+		frames, err := sprite.Import(f, opts)
+		if err != nil {
+			return err
+		}
+
+		// Keep the timing of the animation being replaced unless told otherwise
+		anim := pet.AnimationConfig{Source: "pixel", FPS: 4, Loops: -1, Frames: frames}
+		if existing, ok := p.Config.Animations[key]; ok && existing.FPS > 0 {
+			anim.FPS, anim.Loops = existing.FPS, existing.Loops
+		}
+		if cmd.Flags().Changed("fps") {
+			anim.FPS = fps
+		}
+		if cmd.Flags().Changed("loops") {
+			anim.Loops = loops
+		}
+
+		if err := storage.SetAnimation(configPath, key, anim); err != nil {
+			return err
+		}
+
+		size := image.Pt(len(frames[0].Pixels[0]), len(frames[0].Pixels))
+		say("Imported %d frame(s) at %dx%d with %d colour(s) as '%s' in %s", len(frames), size.X, size.Y, countColors(frames), key, configPath)
+		a := &output.Action{Action: "art-import"}
+		if typeOverride == "" {
+			a.Familiar = output.DisplayName(p)
+		}
+		return emitAction(a)
+	},
+}
+
+var adminArtExportCmd = &cobra.Command{
+	Use:   "export <state>",
+	Short: "Export an animation as a GIF, APNG or SVG",
+	Long: `Export the animation shown for a state as an image, to preview art outside a
+terminal. The format follows the file extension of -o: .gif, .png or .apng
+(an animated PNG), or .svg.
+
+Pixel frames are drawn as blocks of --scale pixels (default 8). Inline frames
+are drawn as light monospace text on a dark background, with colour escapes
+removed, and --scale enlarges the glyphs (default 2). Frames keep their own
+delay or the animation's FPS, and the image plays as many times as the
+animation loops.
+
+The state is chosen as for "familiar admin art", so --evolution and --type
+work the same way.
+
+Examples:
+  familiar admin art export happy -o happy.gif
+  familiar admin art export default -o walk.png --type pixel --scale 4
+  familiar admin art export lonely -o lonely.svg --evolution 2`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state := args[0]
+		path, _ := cmd.Flags().GetString("file")
+		evolutionOverride, _ := cmd.Flags().GetInt("evolution")
+		typeOverride, _ := cmd.Flags().GetString("type")
+		scale, _ := cmd.Flags().GetInt("scale")
+
+		if path == "" {
+			return output.Errorf(output.CodeUsage, "--file is required, e.g. -o %s.gif", state)
+		}
+		format, err := sprite.FormatFor(path)
+		if err != nil {
+			return output.WithCode(output.CodeUsage, err)
+		}
+
+		p, _, err := loadArtPet(typeOverride)
+		if err != nil {
+			return err
+		}
+		key, anim, err := chooseArt(p, state, artEvolution(p, typeOverride, evolutionOverride))
+		if err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		if err := sprite.Export(&b, anim, sprite.ExportOptions{Format: format, Scale: scale}); err != nil {
+			return fmt.Errorf("failed to export '%s': %w", key, err)
+		}
+		if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+
+		say("Exported %d frame(s) of '%s' to %s", len(anim.Frames), key, path)
+		a := &output.Action{Action: "art-export"}
+		if typeOverride == "" {
+			a.Familiar = output.DisplayName(p)
+		}
+		return emitAction(a)
+	},
+}
+
+var adminArtRecordCmd = &cobra.Command{
+	Use:   "record <state>",
+	Short: "Record an animation as an asciicast file",
+	Long: `Record the animation shown for a state as an asciicast v2 file, the format
+played by asciinema. The cast holds the exact escape sequences written to the
+terminal, with each frame at the time it would appear, but nothing is drawn
+and no time passes while recording. An animation that loops forever is
+recorded once through.
+
+Pixel art is recorded at the colour depth of your terminal (see --color).
+The state is chosen as for "familiar admin art", so --evolution and --type
+work the same way.
+
+Examples:
+  familiar admin art record happy -o happy.cast
+  familiar admin art record default -o walk.cast --type pixel
+  asciinema play happy.cast`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state := args[0]
+		path, _ := cmd.Flags().GetString("file")
+		evolutionOverride, _ := cmd.Flags().GetInt("evolution")
+		typeOverride, _ := cmd.Flags().GetString("type")
+		title, _ := cmd.Flags().GetString("title")
+
+		if path == "" {
+			return output.Errorf(output.CodeUsage, "--file is required, e.g. -o %s.cast", state)
+		}
+
+		p, _, err := loadArtPet(typeOverride)
+		if err != nil {
+			return err
+		}
+		key, anim, err := chooseArt(p, state, artEvolution(p, typeOverride, evolutionOverride))
+		if err != nil {
+			return err
+		}
+
+		var r cast.Recorder
+		art.PlayTo(&r, r.Sleep, anim, term.PromptColorDepth())
+
+		// The player makes room for the tallest frame before drawing and ends
+		// on the line below it, so the recorded terminal needs one more row
+		width, height := 0, 0
+		for _, frame := range anim.Frames {
+			plain := art.PlainFrame(anim, frame)
+			width = max(width, art.Width(plain))
+			height = max(height, strings.Count(strings.TrimRight(plain, "\n"), "\n")+1)
+		}
+		h := cast.Header{
+			Width:     max(80, width),
+			Height:    max(24, height+1),
+			Timestamp: time.Now().Unix(),
+			Title:     title,
+			Env:       map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+		}
+		var b bytes.Buffer
+		if err := r.Encode(&b, h); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+
+		events := r.Events()
+		say("Recorded %d frame(s) of '%s' (%.1fs) to %s", len(anim.Frames), key, events[len(events)-1].Time.Seconds(), path)
+		a := &output.Action{Action: "art-record"}
+		if typeOverride == "" {
+			a.Familiar = output.DisplayName(p)
+		}
+		return emitAction(a)
+	},
+}
+
+var adminArtConvertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Rewrite pixel animations as text grids or as pixel arrays",
+	Long: `Rewrite the pixel animations of your familiar's pet.toml, or of a template
+with --type, in another frame format:
+
+  grid    rows of characters, with a shared [legend] table mapping each
+          character to a colour or palette symbol and . for transparent
+  pixels  arrays of quoted colours, one per pixel
+
+Converting to grids adds a character to [legend] for every colour that has
+none. Animations already in the format asked for, accessories and the rest of
+the file are left as they are.
+
+Examples:
+  familiar admin art convert --to grid
+  familiar admin art convert --to pixels --type pixel`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to, _ := cmd.Flags().GetString("to")
+		typeOverride, _ := cmd.Flags().GetString("type")
+		if to != "grid" && to != "pixels" {
+			return output.Errorf(output.CodeUsage, "--to must be grid or pixels")
+		}
+
+		p, configPath, err := artConfig(typeOverride)
+		if err != nil {
+			return err
+		}
+
+		// Inline pixel animations in the other format, in a stable order
+		var keys []string
+		var frames []pet.Frame
+		for key, anim := range p.Config.Animations {
+			if anim.Source != "pixel" || len(anim.Frames) == 0 || (len(anim.Frames[0].Grid) > 0) == (to == "grid") {
+				continue
+			}
+			keys = append(keys, key)
+			frames = append(frames, anim.Frames...)
+		}
+		sort.Strings(keys)
+
+		if to == "grid" && len(keys) > 0 {
+			legend := pet.GridLegend(frames, p.Config.Legend)
+			if len(legend) != len(p.Config.Legend) {
+				if err := storage.SetLegend(configPath, legend); err != nil {
+					return err
+				}
+			}
+			p.Config.Legend = legend
+		}
+		for _, key := range keys {
+			anim := p.Config.Animations[key]
+			if to == "grid" {
+				if anim.Frames, err = pet.GridFrames(anim.Frames, p.Config.Legend); err != nil {
+					return fmt.Errorf("failed to convert %s: %w", key, err)
+				}
+			} else {
+				// The pixels were filled in from the grids when loading
+				for i := range anim.Frames {
+					anim.Frames[i].Grid = nil
+				}
+				anim.Legend = nil
+			}
+			if err := storage.SetAnimation(configPath, key, anim); err != nil {
+				return err
+			}
+		}
+
+		say("Converted %d animation(s) in %s to %s", len(keys), configPath, to)
+		a := &output.Action{Action: "art-convert"}
+		if typeOverride == "" {
+			a.Familiar = output.DisplayName(p)
+		}
+		return emitAction(a)
+	},
+}
+
+// artConfig loads the installed familiar, or the template with --type, and
+// the path of the config file its animations are written to
+func artConfig(typeOverride string) (*pet.Pet, string, error) {
+	if typeOverride == "" {
+		p, configPath, _, err := loadPet()
+		return p, configPath, err
+	}
+	p, err := storage.LoadTemplateConfig(typeOverride)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load template '%s': %w", typeOverride, err)
+	}
+	libDir, err := storage.FindLibDir()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find lib directory: %w", err)
+	}
+	return p, filepath.Join(libDir, typeOverride+".toml"), nil
+}
+
+// countColors counts the distinct opaque colours of pixel frames
+func countColors(frames []pet.Frame) int {
+	seen := map[string]bool{}
+	for _, frame := range frames {
+		for _, row := range frame.Pixels {
+			for _, c := range row {
+				if c != "" {
+					seen[c] = true
+				}
+			}
+		}
+	}
+	return len(seen)
+}
+
+func init() {
+	adminArtCmd.AddCommand(adminArtImportCmd)
+	adminArtImportCmd.Flags().String("state", "", "State the animation is shown for, e.g. happy")
+	adminArtImportCmd.Flags().IntP("evolution", "e", 0, "Evolution the animation is for (stored as e<N>:<state>)")
+	adminArtImportCmd.Flags().String("grid", "", "Frame size in pixels, e.g. 16x16 (default: the image's, fit within 32)")
+	adminArtImportCmd.Flags().String("sheet", "", "Split a sprite sheet into COLSxROWS frames, e.g. 4x2")
+	adminArtImportCmd.Flags().Int("max-colors", sprite.DefaultMaxColors, "Largest palette to keep (0 keeps every colour)")
+	adminArtImportCmd.Flags().Int("fps", 4, "Frames per second for frames without their own delay; unset keeps the replaced animation's")
+	adminArtImportCmd.Flags().Int("loops", -1, "Times to play the animation, -1 for forever; unset keeps the replaced animation's")
+	adminArtImportCmd.Flags().String("type", "", "Write into the lib template of this type (cat, dancer, pixel) instead of your familiar")
+
+	adminArtCmd.AddCommand(adminArtExportCmd)
+	adminArtExportCmd.Flags().StringP("file", "o", "", "File to write; its extension picks the format (.gif, .png, .apng, .svg)")
+	adminArtExportCmd.Flags().IntP("evolution", "e", -1, "Evolution level to export (default: current evolution for installed pet, 1 for templates)")
+	adminArtExportCmd.Flags().StringP("type", "t", "", "Pet type template to use (cat, dancer, pixel) - ignores installed familiar")
+	adminArtExportCmd.Flags().Int("scale", 0, "Image pixels per art pixel, or text magnification (default 8 for pixel art, 2 for text)")
+
+	adminArtCmd.AddCommand(adminArtConvertCmd)
+	adminArtConvertCmd.Flags().String("to", "grid", "Frame format to write: grid or pixels")
+	adminArtConvertCmd.Flags().String("type", "", "Convert the lib template of this type (cat, dancer, pixel) instead of your familiar")
+
+	adminArtCmd.AddCommand(adminArtRecordCmd)
+	adminArtRecordCmd.Flags().StringP("file", "o", "", "File to write, e.g. demo.cast")
+	adminArtRecordCmd.Flags().IntP("evolution", "e", -1, "Evolution level to record (default: current evolution for installed pet, 1 for templates)")
+	adminArtRecordCmd.Flags().StringP("type", "t", "", "Pet type template to use (cat, dancer, pixel) - ignores installed familiar")
+	adminArtRecordCmd.Flags().String("title", "", "Title stored in the cast")
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/output"
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/spf13/cobra"
+)
+
+// maxGateBypasses bounds the bypass log kept in the state file
+const maxGateBypasses = 50
+
+var gateCmd = &cobra.Command{
+	Use:   "gate",
+	Short: "Fail while unacknowledged messages at or above a severity are pending",
+	Long: `Exit non-zero and print the pending messages when you have unacknowledged
+messages at or above the gate severity (default: critical, or [gate] severity
+in pet.toml). Use it in CI or let the git hooks run it by listing them in
+pet.toml:
+
+  [gate]
+  severity = "critical"
+  hooks = ["pre-push"]
+
+A message can be let through explicitly with --allow <id> (or
+FAMILIAR_GATE_ALLOW=<id>,<id> for hooks); every bypass is logged in the state.`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		severityFlag, _ := cmd.Flags().GetString("severity")
+		allow, _ := cmd.Flags().GetStringSlice("allow")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		return runGate(severityFlag, allow, "", quiet)
+	},
+}
+
+func init() {
+	gateCmd.Flags().String("severity", "", "Minimum severity that blocks (default from [gate] in pet.toml, else critical)")
+	gateCmd.Flags().StringSlice("allow", nil, "Let a message through without acknowledging it (repeatable, logged)")
+	gateCmd.Flags().BoolP("quiet", "q", false, "Only report blocking messages")
+}
+
+// runGate returns an error listing the messages that block; hook names the git
+// hook running the gate and is recorded with any bypass
+func runGate(severityFlag string, allow []string, hook string, quiet bool) error {
+	if env := os.Getenv("FAMILIAR_GATE_ALLOW"); env != "" {
+		allow = append(allow, strings.Split(env, ",")...)
+	}
+
+	doc := output.Gate{Blocking: []output.Message{}, Bypassed: []output.Message{}}
+	// A closed gate is returned after the state is saved, so bypasses of the
+	// messages it let through are still logged
+	var closed error
+	err := executeStatefulCommand(func(p *pet.Pet) error {
+		now := time.Now()
+
+		name := severityFlag
+		if name == "" {
+			name = string(p.Config.Gate.Severity)
+		}
+		threshold := pet.SeverityCritical
+		if name != "" {
+			var err error
+			if threshold, err = pet.ParseSeverity(name); err != nil {
+				return output.WithCode(output.CodeUsage, err)
+			}
+		}
+		doc.Severity = string(threshold)
+
+		// Resolve --allow ids up front so typos fail loudly
+		allowed := make(map[string]bool)
+		for _, id := range allow {
+			id = strings.TrimSpace(id)
+			if id == "" {
+				continue
+			}
+			m, err := p.State.FindMessage(id)
+			if err != nil {
+				return err
+			}
+			allowed[m.ID] = true
+		}
+
+		var blocking []pet.Message
+		for _, m := range p.PendingMessages(now) {
+			if m.Severity.Rank() < threshold.Rank() {
+				continue
+			}
+			if allowed[m.ID] {
+				p.State.GateBypasses = append(p.State.GateBypasses, pet.GateBypass{
+					MessageID: m.ID,
+					User:      p.User,
+					At:        now,
+					Context:   hook,
+				})
+				doc.Bypassed = append(doc.Bypassed, output.NewMessage(p, m))
+				if !structured() {
+					fmt.Fprintf(os.Stderr, "familiar gate: bypassing [%s] %s (logged)\n", m.ID, m.Text)
+				}
+				continue
+			}
+			blocking = append(blocking, m)
+			doc.Blocking = append(doc.Blocking, output.NewMessage(p, m))
+		}
+		if len(p.State.GateBypasses) > maxGateBypasses {
+			p.State.GateBypasses = p.State.GateBypasses[len(p.State.GateBypasses)-maxGateBypasses:]
+		}
+
+		if len(blocking) == 0 {
+			doc.Open = true
+			if !quiet && !structured() {
+				fmt.Printf("No unacknowledged %s messages\n", threshold)
+			}
+			return nil
+		}
+		closed = output.Errorf(output.CodeGateClosed, "gate closed: %d unacknowledged message(s)", len(blocking))
+		if structured() {
+			return nil
+		}
+
+		fmt.Fprintf(os.Stderr, "Unacknowledged messages at or above %s:\n", threshold)
+		for _, m := range blocking {
+			fmt.Fprintf(os.Stderr, "  %s\n", formatMessageLine(m, now))
+		}
+		fmt.Fprintf(os.Stderr, "\nRead them with 'familiar status', then 'familiar acknowledge <id>'.\n")
+		fmt.Fprintf(os.Stderr, "To proceed without acknowledging, use --allow <id> (or FAMILIAR_GATE_ALLOW=<id>).\n")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if closed != nil && !structured() {
+		return closed
+	}
+	if err := emit(output.KindGate, doc); err != nil {
+		return err
+	}
+	if closed != nil {
+		// The gate document already lists the blocking messages
+		return reportedError{closed}
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/git"
+	"github.com/sethgrid/familiar/internal/hooks"
+	"github.com/sethgrid/familiar/internal/output"
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/spf13/cobra"
+)
+
+var adminHooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage the git hooks that deliver messages and visits",
+	Long: `Manage git hooks that call familiar on pull, checkout, commit and push:
+
+  post-merge     check watched paths, record a visit
+  post-checkout  check watched paths on branch switches, record a visit
+  post-commit    record a visit
+  pre-commit     run 'familiar gate' if listed in [gate] hooks
+  pre-push       record a visit; run 'familiar gate' if listed in [gate] hooks
+
+Hooks are written to the repository's hooks directory, honouring
+core.hooksPath. Existing hooks are kept: familiar inserts a marked block after
+the shebang of shell hooks and chains hooks written in other languages.`,
+}
+
+var adminHooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install or refresh familiar's git hooks",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := hooksDir()
+		if err != nil {
+			return err
+		}
+		statuses, err := hooks.Install(dir, hooks.Names)
+		if err != nil {
+			return err
+		}
+		if structured() {
+			return emit(output.KindHooks, hooksDocument(dir, statuses))
+		}
+		fmt.Printf("Hooks directory: %s\n", dir)
+		for _, st := range statuses {
+			fmt.Printf("  %-14s %s%s\n", st.Name, st.State, chainedNote(st))
+		}
+		return nil
+	},
+}
+
+var adminHooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove familiar's git hooks, restoring any hooks they chained",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := hooksDir()
+		if err != nil {
+			return err
+		}
+		statuses, err := hooks.Uninstall(dir, hooks.Names)
+		if err != nil {
+			return err
+		}
+		if structured() {
+			return emit(output.KindHooks, hooksDocument(dir, statuses))
+		}
+		fmt.Printf("Hooks directory: %s\n", dir)
+		for _, st := range statuses {
+			if st.Changed {
+				fmt.Printf("  %-14s removed\n", st.Name)
+			} else {
+				fmt.Printf("  %-14s %s\n", st.Name, st.State)
+			}
+		}
+		return nil
+	},
+}
+
+var adminHooksStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether familiar's git hooks are installed and current",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := hooksDir()
+		if err != nil {
+			return err
+		}
+		statuses, err := hooks.Inspect(dir, hooks.Names)
+		if err != nil {
+			return err
+		}
+		if structured() {
+			return emit(output.KindHooks, hooksDocument(dir, statuses))
+		}
+
+		fmt.Printf("Hooks directory: %s\n", dir)
+		if hooksPath := git.Config(".", "core.hooksPath"); hooksPath != "" {
+			fmt.Printf("core.hooksPath: %s\n", hooksPath)
+		}
+		drift := false
+		for _, st := range statuses {
+			fmt.Printf("  %-14s %s%s\n", st.Name, st.State, chainedNote(st))
+			if st.State != hooks.StateInstalled {
+				drift = true
+			}
+		}
+		if drift {
+			fmt.Println("\nRun 'familiar admin hooks install' to bring the hooks up to date.")
+		}
+		return nil
+	},
+}
+
+// adminHooksRunCmd is what the installed hooks call. Failures are reported but
+// never block git.
+var adminHooksRunCmd = &cobra.Command{
+	Use:           "run <hook> [args...]",
+	Short:         "Run familiar's handling for a git hook (called by the installed hooks)",
+	Hidden:        true,
+	Args:          cobra.MinimumNArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Repositories without a familiar have nothing to do
+		p, _, _, err := loadPet()
+		if err != nil {
+			return nil
+		}
+		hook := args[0]
+
+		var steps []func() error
+		visit := true
+		switch hook {
+		case "post-merge":
+			steps = append(steps, func() error { return runWatchCheck("", true, nil) })
+		case "post-checkout":
+			// post-checkout <prev> <new> <branch-flag>; file checkouts pass 0
+			if len(args) < 4 || args[3] == "1" {
+				steps = append(steps, func() error { return runWatchCheck("", true, nil) })
+			}
+		case "pre-commit":
+			// post-commit records the visit
+			visit = false
+		case "post-commit", "pre-push":
+		default:
+			return fmt.Errorf("unknown hook '%s'", hook)
+		}
+		if visit {
+			steps = append(steps, func() error {
+				return executeStatefulCommand(func(p *pet.Pet) error {
+					recordVisit(p, time.Now())
+					return nil
+				})
+			})
+		}
+
+		for _, step := range steps {
+			if err := step(); err != nil {
+				fmt.Fprintf(os.Stderr, "familiar: %v\n", err)
+			}
+		}
+
+		// The gate is the only step allowed to block git
+		for _, gated := range p.Config.Gate.Hooks {
+			if gated == hook {
+				return runGate("", nil, hook, true)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	adminHooksCmd.AddCommand(adminHooksInstallCmd)
+	adminHooksCmd.AddCommand(adminHooksUninstallCmd)
+	adminHooksCmd.AddCommand(adminHooksStatusCmd)
+	adminHooksCmd.AddCommand(adminHooksRunCmd)
+}
+
+func hooksDir() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	dir, err := git.HooksDir(cwd)
+	if err != nil {
+		return "", fmt.Errorf("not in a git repository: %w", err)
+	}
+	return dir, nil
+}
+
+// hooksDocument describes the hooks for --output
+func hooksDocument(dir string, statuses []hooks.Status) output.Hooks {
+	doc := output.Hooks{Dir: dir, HooksPath: git.Config(".", "core.hooksPath"), Hooks: []output.Hook{}}
+	for _, st := range statuses {
+		doc.Hooks = append(doc.Hooks, output.Hook{Name: st.Name, State: string(st.State), Changed: st.Changed, Chained: st.Chained})
+	}
+	return doc
+}
+
+func chainedNote(st hooks.Status) string {
+	if st.Chained {
+		return " (chains existing hook)"
+	}
+	return ""
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"math/rand"
 	"os"
@@ -13,14 +14,20 @@ import (
 	"github.com/sethgrid/familiar/internal/art"
 	"github.com/sethgrid/familiar/internal/conditions"
 	"github.com/sethgrid/familiar/internal/discovery"
+	"github.com/sethgrid/familiar/internal/duration"
 	"github.com/sethgrid/familiar/internal/health"
+	"github.com/sethgrid/familiar/internal/identity"
+	"github.com/sethgrid/familiar/internal/output"
 	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/prompt"
 	"github.com/sethgrid/familiar/internal/storage"
+	"github.com/sethgrid/familiar/internal/term"
 	"github.com/spf13/cobra"
 )
 
 var (
-	configPath string
+	configPath   string
+	graphicsFlag string // --graphics, overriding the pet's graphics setting
 )
 
 const Version = "v0.4.0"
@@ -42,18 +49,49 @@ func main() {
 	rootCmd := &cobra.Command{
 		Use:   "familiar",
 		Short: "PromptFamiliar - A terminal pet that lives in your prompt",
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			// If version flag is set, print version and exit
 			if version, _ := cmd.Flags().GetBool("version"); version {
+				if structured() {
+					return emit(output.KindVersion, output.Version{Version: Version, SchemaVersion: output.SchemaVersion})
+				}
 				fmt.Println(Version)
-				return
+				return nil
 			}
 			// Otherwise show help
-			cmd.Help()
+			return cmd.Help()
 		},
 	}
 
 	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to pet config file")
+	rootCmd.PersistentFlags().StringVar(&graphicsFlag, "graphics", "", "Pixel art rendering: auto, blocks, braille, kitty, iterm or sixel (default: the familiar's graphics setting)")
+	rootCmd.PersistentFlags().String("color", term.ColorAuto, "Colour output: auto, always or never (auto honours NO_COLOR, FORCE_COLOR, COLORTERM and TERM)")
+	rootCmd.PersistentFlags().StringVar(&outputFlag, "output", string(output.Text), "Output format: text, json or yaml (see 'familiar admin schema')")
+	// Runs once flags are parsed, so a structured run can silence cobra's own text
+	cobra.OnInitialize(func() { initOutput(rootCmd) })
+	// Flag errors come before the initializers; --output may already be parsed
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		initOutput(rootCmd)
+		return err
+	})
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if _, err := output.ParseFormat(outputFlag); err != nil {
+			return err
+		}
+		mode, _ := cmd.Flags().GetString("color")
+		// Documents never carry escape sequences
+		if structured() {
+			mode = term.ColorNever
+		}
+		if err := term.SetColorMode(mode); err != nil {
+			return err
+		}
+		if err := term.SetGraphicsMode(graphicsFlag); err != nil {
+			return err
+		}
+		started = true
+		return nil
+	}
 	rootCmd.Flags().BoolP("version", "v", false, "Print version information")
 
 	rootCmd.AddCommand(summonCmd)
@@ -62,16 +100,26 @@ func main() {
 	rootCmd.AddCommand(playCmd)
 	rootCmd.AddCommand(restCmd)
 	rootCmd.AddCommand(healCmd)
+	rootCmd.AddCommand(visitCmd)
 	rootCmd.AddCommand(adminCmd)
 	rootCmd.AddCommand(messageCmd)
+	rootCmd.AddCommand(messagesCmd)
 	rootCmd.AddCommand(acknowledgeCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(scheduleCmd)
+	rootCmd.AddCommand(remindCmd)
+	rootCmd.AddCommand(remindersCmd)
+	rootCmd.AddCommand(gateCmd)
+	rootCmd.AddCommand(shellCmd)
 	rootCmd.AddCommand(awakenCmd)
 	rootCmd.AddCommand(ossifyCmd)
 	rootCmd.AddCommand(dismissCmd)
 	rootCmd.AddCommand(banishCmd)
+	rootCmd.AddCommand(wearCmd)
+	rootCmd.AddCommand(recolorCmd)
 
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		reportError(err)
 		os.Exit(1)
 	}
 }
@@ -103,7 +151,7 @@ var summonCmd = &cobra.Command{
 		// Check if pet already exists
 		statePath := filepath.Join(petDir, "pet.state.toml")
 		if _, err := os.Stat(statePath); err == nil {
-			return fmt.Errorf("a familiar already exists. Use 'dismiss' to soft-delete it first")
+			return output.Errorf(output.CodeExists, "a familiar already exists. Use 'dismiss' to soft-delete it first")
 		}
 
 		var petType string
@@ -128,12 +176,9 @@ var summonCmd = &cobra.Command{
 				if err != nil {
 					return fmt.Errorf("failed to load restored familiar: %w", err)
 				}
-				petName := p.Config.Name
-				if p.State.NameOverride != "" {
-					petName = p.State.NameOverride
-				}
-				fmt.Printf("Familiar '%s' restored!\n", petName)
-				return nil
+				petName := output.DisplayName(p)
+				say("Familiar '%s' restored!", petName)
+				return emitAction(&output.Action{Action: "summon", Familiar: petName})
 			}
 		} else if len(args) == 1 {
 			// One arg - could be name to restore or name for new pet
@@ -144,8 +189,8 @@ var summonCmd = &cobra.Command{
 				if err := storage.RestoreReleased(petDir, releasedPath); err != nil {
 					return fmt.Errorf("failed to restore familiar: %w", err)
 				}
-				fmt.Printf("Familiar '%s' restored!\n", args[0])
-				return nil
+				say("Familiar '%s' restored!", args[0])
+				return emitAction(&output.Action{Action: "summon", Familiar: args[0]})
 			}
 			// Not found, treat as new pet name
 			petType = "cat"
@@ -156,46 +201,75 @@ var summonCmd = &cobra.Command{
 			name = args[1]
 		}
 
+		palette, _ := cmd.Flags().GetString("palette")
+		if palette != "" {
+			template, err := storage.LoadTemplateConfig(petType)
+			if err != nil {
+				return fmt.Errorf("failed to load template '%s': %w", petType, err)
+			}
+			if _, ok := template.Config.Palettes[palette]; !ok && palette != pet.DefaultPalette {
+				return output.Errorf(output.CodeNotFound, "the %s template has no palette '%s'", petType, palette)
+			}
+		}
+
 		if err := storage.InitPet(global, petType, name, baseDir); err != nil {
 			return fmt.Errorf("failed to summon familiar: %w", err)
 		}
+		if palette != "" && palette != pet.DefaultPalette {
+			configPath := filepath.Join(petDir, "pet.toml")
+			p, err := storage.LoadPet(configPath, statePath)
+			if err != nil {
+				return fmt.Errorf("failed to load familiar: %w", err)
+			}
+			p.State.Palette = palette
+			if err := storage.SavePetState(p, statePath); err != nil {
+				return fmt.Errorf("failed to save familiar: %w", err)
+			}
+		}
 
-		fmt.Printf("Familiar '%s' summoned!\n", name)
-		return nil
+		say("Familiar '%s' summoned!", name)
+		return emitAction(&output.Action{Action: "summon", Familiar: name})
 	},
 }
 
 func init() {
 	summonCmd.Flags().Bool("global", false, "Create global familiar")
+	summonCmd.Flags().String("palette", "", "Colour a new familiar with one of its template's palettes (see 'familiar recolor')")
 }
 
-func loadPet() (*pet.Pet, string, string, error) {
-	var statePath string
-	var found bool
-	var err error
-
+// findStatePath locates the state file of the familiar to use: --config,
+// the nearest project familiar, or the global one
+func findStatePath() (string, error) {
 	if configPath != "" {
 		// Use provided config path (treating it as state path for now)
-		statePath = configPath
-		if _, err := os.Stat(statePath); err != nil {
-			return nil, "", "", fmt.Errorf("state file not found: %s", statePath)
-		}
-		found = true
-	} else {
-		// Discover pet
-		cwd, _ := os.Getwd()
-		statePath, found, err = discovery.FindStateFile(cwd)
-		if err != nil {
-			return nil, "", "", err
+		if _, err := os.Stat(configPath); err != nil {
+			return "", output.Errorf(output.CodeNoFamiliar, "state file not found: %s", configPath)
 		}
+		return configPath, nil
 	}
 
-	if !found {
-		// Try global
-		statePath = discovery.GlobalPetStatePath()
-		if _, err := os.Stat(statePath); err != nil {
-			return nil, "", "", fmt.Errorf("no familiar found. Run 'familiar init' to create one")
-		}
+	// Discover pet
+	cwd, _ := os.Getwd()
+	statePath, found, err := discovery.FindStateFile(cwd)
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return statePath, nil
+	}
+
+	// Try global
+	statePath = discovery.GlobalPetStatePath()
+	if _, err := os.Stat(statePath); err != nil {
+		return "", output.Errorf(output.CodeNoFamiliar, "no familiar found. Run 'familiar init' to create one")
+	}
+	return statePath, nil
+}
+
+func loadPet() (*pet.Pet, string, string, error) {
+	statePath, err := findStatePath()
+	if err != nil {
+		return nil, "", "", err
 	}
 
 	petConfigPath := discovery.GetConfigPathFromState(statePath)
@@ -203,10 +277,51 @@ func loadPet() (*pet.Pet, string, string, error) {
 	if err != nil {
 		return nil, "", "", fmt.Errorf("failed to load familiar: %w", err)
 	}
+	if graphicsFlag == "" {
+		if err := term.SetGraphicsMode(p.Config.Graphics); err != nil {
+			return nil, "", "", fmt.Errorf("failed to load familiar: %w", err)
+		}
+	}
+	art.SetBraille(p.Config.Braille)
+
+	// Messages are acknowledged per user
+	p.User = identity.Current().Key()
+	if p.Config.AckStore == pet.AckStoreUser {
+		p.UserAcks, err = storage.LoadUserAcks(filepath.Dir(statePath), p.User)
+		if err != nil {
+			return nil, "", "", err
+		}
+	}
+
+	// Personal reminders live in the user's local state, outside the repository
+	local, err := storage.LoadLocalState(filepath.Dir(statePath))
+	if err != nil {
+		return nil, "", "", err
+	}
+	p.Reminders = local.Reminders
 
 	return p, petConfigPath, statePath, nil
 }
 
+// savePet writes the shared state and, when acknowledgements are kept per
+// user, the current user's acknowledgement file
+func savePet(p *pet.Pet, statePath string) error {
+	if err := storage.SavePetState(p, statePath); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+
+	if p.Config.AckStore == pet.AckStoreUser && len(p.UserAcks) > 0 {
+		keep := make(map[string]bool, len(p.State.Messages))
+		for _, m := range p.State.Messages {
+			keep[m.ID] = true
+		}
+		if err := storage.SaveUserAcks(filepath.Dir(statePath), p.User, p.UserAcks, keep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func executeStatefulCommand(fn func(*pet.Pet) error) error {
 	p, _, statePath, err := loadPet()
 	if err != nil {
@@ -225,11 +340,7 @@ func executeStatefulCommand(fn func(*pet.Pet) error) error {
 	}
 
 	// Save state
-	if err := storage.SavePetState(p, statePath); err != nil {
-		return fmt.Errorf("failed to save state: %w", err)
-	}
-
-	return nil
+	return savePet(p, statePath)
 }
 
 var statusCmd = &cobra.Command{
@@ -256,6 +367,14 @@ var statusCmd = &cobra.Command{
 
 		health := health.ComputeHealth(p.State.Hunger, p.State.Happiness, p.State.Energy, health.ComputationMode(p.Config.HealthComputation))
 		status := conditions.DeriveStatus(p, now, health)
+		loadFrames(p, filepath.Dir(statePath), art.ChooseAnimationKey(status.Conditions, p.State.Evolution, p.Config.Animations))
+
+		if structured() {
+			if err := savePet(p, statePath); err != nil {
+				return err
+			}
+			return emit(output.KindStatus, output.NewStatus(p, status, now, art.PlainStaticArt(p, status)))
+		}
 
 		name := p.Config.Name
 		if p.State.NameOverride != "" {
@@ -282,18 +401,18 @@ var statusCmd = &cobra.Command{
 			}
 		}
 
-		fmt.Println(art.GetStaticArt(p, status))
+		// On a terminal the familiar says its messages in a speech bubble
+		pending := p.PendingMessages(now)
+		bubble := messageBubble(p, status, pending)
+		fmt.Println(art.GetStaticArtWithBubble(p, status, bubble))
 
-		if p.State.Message != "" {
-			fmt.Printf("\nMessage: %s\n", p.State.Message)
+		if bubble == nil {
+			printMessageSummary(pending, now)
 		}
+		printReminderSummary(p.DueReminders(now), now)
 
 		// Save state
-		if err := storage.SavePetState(p, statePath); err != nil {
-			return fmt.Errorf("failed to save state: %w", err)
-		}
-
-		return nil
+		return savePet(p, statePath)
 	},
 }
 
@@ -305,7 +424,7 @@ var feedCmd = &cobra.Command{
 	Use:   "feed",
 	Short: "Feed your familiar",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return executeStatefulCommand(func(p *pet.Pet) error {
+		return runAction("feed", func(p *pet.Pet, a *output.Action) error {
 			now := time.Now()
 
 			petName := p.Config.Name
@@ -317,29 +436,24 @@ var feedCmd = &cobra.Command{
 			if p.State.IsAsleep {
 				p.State.SleepAttempts++
 				if p.State.SleepAttempts == 1 {
-					fmt.Printf("%s is asleep\n", petName)
+					say("%s is asleep", petName)
 					return nil
 				} else if p.State.SleepAttempts == 2 {
-					fmt.Printf("%s is still asleep\n", petName)
+					say("%s is still asleep", petName)
 					return nil
 				} else {
 					// Third attempt - wake up and take action
 					p.State.IsAsleep = false
 					p.State.SleepUntil = time.Time{}
 					p.State.SleepAttempts = 0
-					fmt.Printf("%s wakes up!\n", petName)
+					say("%s wakes up!", petName)
 					// Continue to feed action below
 				}
 			}
 
 			// Cannot feed if stone
 			if p.State.IsStone {
-				return fmt.Errorf("your familiar is stone. Use 'awaken' first")
-			}
-
-			// Evolve from egg (0) to first evolution (1) on first interaction
-			if p.State.Evolution == 0 {
-				p.State.Evolution = 1
+				return output.Errorf(output.CodeState, "your familiar is stone. Use 'awaken' first")
 			}
 
 			// Decrease hunger (lower is better) and increase happiness
@@ -352,8 +466,11 @@ var feedCmd = &cobra.Command{
 				Time:   now,
 				Action: pet.InteractionFeed,
 			})
+			p.State.Feeds++
+			// An egg hatches on its first interaction; by-age stages follow
+			p.Evolve(now)
 
-			fmt.Println("Fed your familiar!")
+			say("Fed your familiar!")
 			return nil
 		})
 	},
@@ -363,7 +480,7 @@ var playCmd = &cobra.Command{
 	Use:   "play",
 	Short: "Play with your familiar",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return executeStatefulCommand(func(p *pet.Pet) error {
+		return runAction("play", func(p *pet.Pet, a *output.Action) error {
 			now := time.Now()
 
 			petName := p.Config.Name
@@ -375,29 +492,24 @@ var playCmd = &cobra.Command{
 			if p.State.IsAsleep {
 				p.State.SleepAttempts++
 				if p.State.SleepAttempts == 1 {
-					fmt.Printf("%s is asleep\n", petName)
+					say("%s is asleep", petName)
 					return nil
 				} else if p.State.SleepAttempts == 2 {
-					fmt.Printf("%s is still asleep\n", petName)
+					say("%s is still asleep", petName)
 					return nil
 				} else {
 					// Third attempt - wake up and take action
 					p.State.IsAsleep = false
 					p.State.SleepUntil = time.Time{}
 					p.State.SleepAttempts = 0
-					fmt.Printf("%s wakes up!\n", petName)
+					say("%s wakes up!", petName)
 					// Continue to play action below
 				}
 			}
 
 			// Cannot play if stone
 			if p.State.IsStone {
-				return fmt.Errorf("your familiar is stone. Use 'awaken' first")
-			}
-
-			// Evolve from egg (0) to first evolution (1) on first interaction
-			if p.State.Evolution == 0 {
-				p.State.Evolution = 1
+				return output.Errorf(output.CodeState, "your familiar is stone. Use 'awaken' first")
 			}
 
 			// Increase happiness, decrease energy
@@ -410,8 +522,11 @@ var playCmd = &cobra.Command{
 				Time:   now,
 				Action: pet.InteractionPlay,
 			})
+			p.State.Plays++
+			// An egg hatches on its first interaction; by-age stages follow
+			p.Evolve(now)
 
-			fmt.Println("Played with your familiar!")
+			say("Played with your familiar!")
 			return nil
 		})
 	},
@@ -421,7 +536,7 @@ var restCmd = &cobra.Command{
 	Use:   "rest",
 	Short: "Put your familiar to sleep (restorative sleep)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return executeStatefulCommand(func(p *pet.Pet) error {
+		return runAction("rest", func(p *pet.Pet, a *output.Action) error {
 			now := time.Now()
 
 			// If already asleep, do nothing
@@ -430,13 +545,13 @@ var restCmd = &cobra.Command{
 				if p.State.NameOverride != "" {
 					petName = p.State.NameOverride
 				}
-				fmt.Printf("%s is already asleep\n", petName)
+				say("%s is already asleep", petName)
 				return nil
 			}
 
 			// Cannot sleep if stone
 			if p.State.IsStone {
-				return fmt.Errorf("your familiar is stone. Use 'awaken' first")
+				return output.Errorf(output.CodeState, "your familiar is stone. Use 'awaken' first")
 			}
 
 			// Set sleep duration
@@ -455,12 +570,56 @@ var restCmd = &cobra.Command{
 				petName = p.State.NameOverride
 			}
 
-			fmt.Printf("%s has fallen asleep (will wake in %s)\n", petName, sleepDuration)
+			say("%s has fallen asleep (will wake in %s)", petName, sleepDuration)
+			return nil
+		})
+	},
+}
+
+var visitCmd = &cobra.Command{
+	Use:   "visit",
+	Short: "Record a visit to your familiar (used by git and shell hooks)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		silent, _ := cmd.Flags().GetBool("silent")
+		if throttleFlag, _ := cmd.Flags().GetString("throttle"); throttleFlag != "" {
+			throttle, err := duration.Parse(throttleFlag)
+			if err != nil {
+				return fmt.Errorf("invalid --throttle: %w", err)
+			}
+			// Shell hooks call visit on every directory change; skip without writing
+			p, _, _, err := loadPet()
+			if err != nil {
+				return err
+			}
+			if time.Since(p.State.LastVisited) < throttle {
+				return emitAction(&output.Action{Action: "visit", Familiar: output.DisplayName(p), Skipped: true})
+			}
+		}
+		return runAction("visit", func(p *pet.Pet, a *output.Action) error {
+			recordVisit(p, time.Now())
+			if !silent {
+				say("%s noticed you stopping by", a.Familiar)
+			}
 			return nil
 		})
 	},
 }
 
+func init() {
+	visitCmd.Flags().BoolP("silent", "s", false, "Silent mode: no output")
+	visitCmd.Flags().String("throttle", "", "Skip the visit if the last one was more recent than this, e.g. 1h")
+}
+
+// recordVisit counts a visit towards keeping the familiar from getting lonely
+func recordVisit(p *pet.Pet, now time.Time) {
+	p.State.LastVisited = now
+	p.State.LastVisits = appendInteraction(p.State.LastVisits, pet.Interaction{
+		Time:   now,
+		Action: pet.InteractionVisit,
+	})
+	p.State.Visits++
+}
+
 var adminCmd = &cobra.Command{
 	Use:   "admin",
 	Short: "Administrative commands for familiar management",
@@ -469,50 +628,123 @@ var adminCmd = &cobra.Command{
 var adminHealthCmd = &cobra.Command{
 	Use:   "health",
 	Short: "Get health status for prompt",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return executeStatefulCommand(func(p *pet.Pet) error {
-			health := health.ComputeHealth(p.State.Hunger, p.State.Happiness, p.State.Energy, health.ComputationMode(p.Config.HealthComputation))
+	Long: `Print the familiar's prompt segment: its icon and a state glyph.
 
-			const resetCode = "\033[0m"
+The segment is cached per user for up to cacheTTL from pet.toml, and
+recomputed as soon as the state, config or your reminders change, or when a
+message, reminder, schedule or decay would change it. Recomputing saves the
+familiar's state only when decay or a schedule changed it. Set cacheTTL = 0 to
+disable the cache.
 
-			// Always show 🐾
-			icon := "🐾"
+--format takes a preset that escapes colours for your prompt (ansi, bash,
+zsh, fish, starship, tmux, json) or a Go template over .Name, .Health,
+.Condition, .State, .Icon, .Glyph, .Color, .Count and .Reminders, with the
+functions fg, reset, bash, zsh, tmux, tmuxText, hex and json.
 
-			// Determine color based on stone state or health percentage
-			// Check stone state same way conditions does: IsStone OR health < threshold
-			isStone := p.State.IsStone || health < p.Config.StoneThreshold
-			var colorCode string
-			if isStone {
-				colorCode = "\033[90m" // Gray - Stone
-			} else {
-				switch {
-				case health >= 80:
-					colorCode = "\033[32m" // Green - Excellent
-				case health >= 60:
-					colorCode = "\033[33m" // Yellow - Good
-				case health >= 40:
-					colorCode = "\033[93m" // Bright Yellow - Fair
-				case health >= 20:
-					colorCode = "\033[38;5;208m" // Orange - Poor
-				default:
-					colorCode = "\033[31m" // Red - Critical
-				}
-			}
+Examples:
+  PS1='$(familiar admin health --format bash) \w$ '
+  familiar admin health --format '{{.Name}} {{.Health}}% {{.Condition}}'
+  familiar admin health --format '{{bash (fg .Color)}}{{.Name}}{{bash reset}}'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		statePath, err := findStatePath()
+		if err != nil {
+			return err
+		}
 
-			// Single character to represent health or message
-			var healthChar string
-			if p.State.Message != "" {
-				healthChar = "▲" // Triangle for message
-			} else {
-				healthChar = "●" // Circle for health
+		now := time.Now()
+		format, _ := cmd.Flags().GetString("format")
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		if !refresh {
+			if c, err := storage.LoadPromptCache(filepath.Dir(statePath)); err == nil && c.Fresh(now) {
+				return printPrompt(c.Segment, format)
 			}
-
-			fmt.Printf("%s %s%s%s", icon, colorCode, healthChar, resetCode)
-			return nil
-		})
+		}
+		return renderPrompt(statePath, format, now)
 	},
 }
 
+func init() {
+	adminHealthCmd.Flags().String("format", "ansi", "Preset ("+strings.Join(prompt.PresetNames(), ", ")+") or Go template")
+	adminHealthCmd.Flags().Bool("refresh", false, "Recompute the segment instead of using the cache")
+}
+
+// printPrompt prints the segment with a preset or template, or as a prompt
+// document for --output. Colours are reduced to what the terminal supports,
+// except in JSON.
+func printPrompt(seg prompt.Segment, format string) error {
+	if structured() {
+		return emit(output.KindPrompt, output.NewPrompt(seg))
+	}
+	if format != "json" {
+		seg.Color = term.DownsampleSGR(seg.Color, term.PromptColorDepth())
+	}
+	out, err := prompt.Format(seg, format)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// renderPrompt computes and prints the prompt segment and caches it. The time
+// step is saved when it changed more than LastChecked, so schedules first seen
+// here keep their baseline, unless another command wrote the state meanwhile.
+func renderPrompt(statePath, format string, now time.Time) error {
+	petDir := filepath.Dir(statePath)
+	// Stamp the inputs before reading them, so a write in between invalidates the cache
+	inputs, err := storage.PromptInputs(petDir, discovery.GetConfigPathFromState(statePath), statePath)
+	if err != nil {
+		return err
+	}
+	stamps := storage.StampFiles(inputs...)
+
+	p, _, _, err := loadPet()
+	if err != nil {
+		return err
+	}
+	lastChecked := p.State.LastChecked
+	before, _ := toml.Marshal(p.State)
+	if err := pet.ApplyTimeStep(p, now); err != nil {
+		return fmt.Errorf("failed to apply time step: %w", err)
+	}
+
+	// Glyphs and colours come from the [prompt] theme in pet.toml
+	segment := prompt.Build(p, now)
+	if err := printPrompt(segment, format); err != nil {
+		return err
+	}
+
+	checked := p.State.LastChecked
+	p.State.LastChecked = lastChecked
+	after, _ := toml.Marshal(p.State)
+	p.State.LastChecked = checked
+	if !bytes.Equal(before, after) && storage.Unchanged(stamps) {
+		// A prompt must not fail because the state could not be written
+		if err := savePet(p, statePath); err == nil {
+			stamps = storage.StampFiles(inputs...)
+		}
+	}
+
+	if p.Config.CacheTTL <= 0 {
+		return nil
+	}
+	if p.Config.AckStore == pet.AckStoreUser {
+		stamps = append(stamps, storage.StampFiles(storage.UserAcksPath(petDir, p.User))...)
+	}
+	validUntil := now.Add(p.Config.CacheTTL)
+	if next := prompt.Expiry(p, now); !next.IsZero() && next.Before(validUntil) {
+		validUntil = next
+	}
+	// A prompt must not fail because the cache could not be written
+	_ = storage.SavePromptCache(petDir, &storage.PromptCache{
+		Segment:    segment,
+		ComputedAt: now,
+		ValidUntil: validUntil,
+		Inputs:     stamps,
+	})
+	return nil
+}
+
 var adminCompletionCmd = &cobra.Command{
 	Use:   "completion [bash|zsh|fish|powershell]",
 	Short: "Generate shell completion script",
@@ -670,6 +902,13 @@ If pet-type is not specified, it will be auto-detected from your current pet.
 			}
 		}
 
+		if err := templateConfig.ExpandGrids(); err != nil {
+			return fmt.Errorf("failed to read pixel grids in template: %w", err)
+		}
+		if err := templateConfig.ValidatePalettes(); err != nil {
+			return fmt.Errorf("failed to read palettes in template: %w", err)
+		}
+
 		// Merge: preserve user values, update from template
 		mergedConfig := mergeConfig(p.Config, templateConfig)
 
@@ -688,13 +927,9 @@ If pet-type is not specified, it will be auto-detected from your current pet.
 			return fmt.Errorf("failed to write config: %w", err)
 		}
 
-		petName := p.Config.Name
-		if p.State.NameOverride != "" {
-			petName = p.State.NameOverride
-		}
-
-		fmt.Printf("%s's config has been updated from %s template\n", petName, petType)
-		return nil
+		petName := output.DisplayName(p)
+		say("%s's config has been updated from %s template", petName, petType)
+		return emitAction(&output.Action{Action: "update", Familiar: petName})
 	},
 }
 
@@ -723,156 +958,197 @@ Use --type flag to view animations from a template type (cat, dancer, pixel) wit
 		evolutionOverride, _ := cmd.Flags().GetInt("evolution")
 		typeOverride, _ := cmd.Flags().GetString("type")
 
-		var p *pet.Pet
-		var err error
-
-		// If --type is specified, load from template instead of installed pet
-		if typeOverride != "" {
-			p, err = storage.LoadTemplateConfig(typeOverride)
-			if err != nil {
-				return fmt.Errorf("failed to load template '%s': %w", typeOverride, err)
-			}
-			// For templates, default to evolution 1 if not specified
-			if evolutionOverride < 0 {
-				evolutionOverride = 1
-			}
-		} else {
-			// Load installed pet
-			p, _, _, err = loadPet()
-			if err != nil {
-				return err
-			}
+		p, keys, err := loadArtPet(typeOverride)
+		if err != nil {
+			return err
 		}
 
 		// Handle "list" command
 		if state == "list" {
+			if structured() {
+				states := output.ArtStates{States: []output.ArtState{}}
+				for _, key := range keys {
+					anim := p.Config.Animations[key]
+					states.States = append(states.States, output.ArtState{Key: key, Source: artSource(anim), Frames: len(anim.Frames)})
+				}
+				return emit(output.KindArtStates, states)
+			}
 			if p.Config.Animations == nil || len(p.Config.Animations) == 0 {
 				fmt.Println("No animations available")
 				return nil
 			}
 
-			// Collect and sort animation keys
-			keys := make([]string, 0, len(p.Config.Animations))
-			for k := range p.Config.Animations {
-				keys = append(keys, k)
-			}
-
-			// Sort keys for consistent output
-			sort.Strings(keys)
-
 			fmt.Println("Available animation states:")
 			for _, key := range keys {
 				anim := p.Config.Animations[key]
-				frameCount := len(anim.Frames)
-				source := anim.Source
-				if source == "" {
-					source = "inline"
-				}
-				fmt.Printf("  %s (%s, %d frame(s))\n", key, source, frameCount)
+				fmt.Printf("  %s (%s, %d frame(s))\n", key, artSource(anim), len(anim.Frames))
 			}
 			return nil
 		}
 
-		// Determine which evolution to use
-		evolution := 1 // Default for templates
-		if typeOverride == "" {
-			// For installed pets, use their current evolution
-			evolution = p.State.Evolution
+		key, anim, err := chooseArt(p, state, artEvolution(p, typeOverride, evolutionOverride))
+		if err != nil {
+			return err
+		}
+		return displayArt(state, key, anim)
+	},
+}
+
+// loadArtPet loads the installed familiar, or the template with --type, with
+// the frames of its file and url animations, and its animation keys sorted
+func loadArtPet(typeOverride string) (*pet.Pet, []string, error) {
+	var p *pet.Pet
+	var baseDir string
+	var err error
+
+	// If --type is specified, load from template instead of installed pet
+	if typeOverride != "" {
+		p, err = storage.LoadTemplateConfig(typeOverride)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load template '%s': %w", typeOverride, err)
 		}
-		if evolutionOverride >= 0 {
-			evolution = evolutionOverride
+		baseDir, _ = storage.FindLibDir()
+	} else {
+		// Load installed pet
+		var statePath string
+		p, _, statePath, err = loadPet()
+		if err != nil {
+			return nil, nil, err
 		}
+		baseDir = filepath.Dir(statePath)
+	}
 
-		// Evolution 0 always shows egg (unless it's a special state like stone egg)
-		if evolution == 0 {
-			// For egg, check if there's a state-specific egg (like stone+egg)
-			// But for simplicity, just show egg art
-			if state == "egg" || state == "default" {
-				anim, exists := p.Config.Animations["egg"]
-				if exists && len(anim.Frames) > 0 {
-					return displayArt(anim)
-				}
-			}
-			// For other states at evolution 0, still show egg
+	keys := make([]string, 0, len(p.Config.Animations))
+	for k := range p.Config.Animations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	loadFrames(p, baseDir, keys...)
+
+	return p, keys, nil
+}
+
+// artEvolution is the evolution to show: --evolution, else the familiar's
+// current one, or 1 for templates
+func artEvolution(p *pet.Pet, typeOverride string, evolutionOverride int) int {
+	evolution := 1 // Default for templates
+	if typeOverride == "" {
+		// For installed pets, use their current evolution
+		evolution = p.State.Evolution
+	}
+	if evolutionOverride >= 0 {
+		evolution = evolutionOverride
+	}
+	return evolution
+}
+
+// chooseArt picks the animation shown for state at evolution, as status would,
+// dressed in the accessories the familiar wears and in its palette's colours
+func chooseArt(p *pet.Pet, state string, evolution int) (string, pet.AnimationConfig, error) {
+	key, anim, err := findArt(p, state, evolution)
+	if err != nil {
+		return "", anim, err
+	}
+	return key, p.ActivePalette().Apply(art.Dress(p, key, anim)), nil
+}
+
+// findArt is chooseArt without the accessories the familiar wears
+func findArt(p *pet.Pet, state string, evolution int) (string, pet.AnimationConfig, error) {
+	// Evolution 0 always shows egg (unless it's a special state like stone egg)
+	if evolution == 0 {
+		// For egg, check if there's a state-specific egg (like stone+egg)
+		// But for simplicity, just show egg art
+		if state == "egg" || state == "default" {
 			anim, exists := p.Config.Animations["egg"]
 			if exists && len(anim.Frames) > 0 {
-				return displayArt(anim)
-			}
-			return fmt.Errorf("egg animation not found")
-		}
-
-		// For evolution > 0, use ChooseAnimationKey to find the right animation
-		// Create a fake status with the requested condition
-		conds := make(map[conditions.Condition]bool)
-
-		// Map state string to condition
-		switch state {
-		case "has-message":
-			conds[conditions.CondHasMessage] = true
-		case "stone":
-			conds[conditions.CondStone] = true
-		case "asleep":
-			conds[conditions.CondAsleep] = true
-		case "infirm":
-			conds[conditions.CondInfirm] = true
-		case "lonely":
-			conds[conditions.CondLonely] = true
-		case "hungry":
-			conds[conditions.CondHungry] = true
-		case "tired":
-			conds[conditions.CondTired] = true
-		case "sad":
-			conds[conditions.CondSad] = true
-		case "happy":
-			conds[conditions.CondHappy] = true
-		case "default":
-			// No conditions - will use default
-		default:
-			// Try to find animation directly by state name first
-			anim, exists := p.Config.Animations[state]
-			if exists && len(anim.Frames) > 0 {
-				return displayArt(anim)
+				return "egg", anim, nil
 			}
-			return fmt.Errorf("unknown state '%s'. Use 'familiar admin art list' to see available states", state)
 		}
+		// For other states at evolution 0, still show egg
+		anim, exists := p.Config.Animations["egg"]
+		if exists && len(anim.Frames) > 0 {
+			return "egg", anim, nil
+		}
+		return "", pet.AnimationConfig{}, output.Errorf(output.CodeNotFound, "egg animation not found")
+	}
+
+	// For evolution > 0, use ChooseAnimationKey to find the right animation
+	// Create a fake status with the requested condition
+	conds := make(map[conditions.Condition]bool)
+
+	// Map state string to condition
+	switch state {
+	case "has-message":
+		conds[conditions.CondHasMessage] = true
+	case "stone":
+		conds[conditions.CondStone] = true
+	case "asleep":
+		conds[conditions.CondAsleep] = true
+	case "infirm":
+		conds[conditions.CondInfirm] = true
+	case "lonely":
+		conds[conditions.CondLonely] = true
+	case "hungry":
+		conds[conditions.CondHungry] = true
+	case "tired":
+		conds[conditions.CondTired] = true
+	case "sad":
+		conds[conditions.CondSad] = true
+	case "happy":
+		conds[conditions.CondHappy] = true
+	case "default":
+		// No conditions - will use default
+	default:
+		// Try to find animation directly by state name first
+		anim, exists := p.Config.Animations[state]
+		if exists && len(anim.Frames) > 0 {
+			return state, anim, nil
+		}
+		return "", pet.AnimationConfig{}, output.Errorf(output.CodeNotFound, "unknown state '%s'. Use 'familiar admin art list' to see available states", state)
+	}
 
-		// Use ChooseAnimationKey to find the right animation key for this evolution
-		key := art.ChooseAnimationKey(conds, evolution, p.Config.Animations)
+	// Use ChooseAnimationKey to find the right animation key for this evolution
+	key := art.ChooseAnimationKey(conds, evolution, p.Config.Animations)
 
-		// Try to get the animation
-		anim, exists := p.Config.Animations[key]
+	// Try to get the animation
+	anim, exists := p.Config.Animations[key]
+	if !exists {
+		// Fallback: try the state name directly
+		key = state
+		anim, exists = p.Config.Animations[state]
 		if !exists {
-			// Fallback: try the state name directly
-			anim, exists = p.Config.Animations[state]
-			if !exists {
-				return fmt.Errorf("animation for state '%s' at evolution %d not found. Use 'familiar admin art list' to see available states", state, evolution)
-			}
+			return "", pet.AnimationConfig{}, output.Errorf(output.CodeNotFound, "animation for state '%s' at evolution %d not found. Use 'familiar admin art list' to see available states", state, evolution)
 		}
+	}
 
-		if len(anim.Frames) == 0 {
-			return fmt.Errorf("animation state '%s' has no frames", state)
-		}
+	if len(anim.Frames) == 0 {
+		return "", pet.AnimationConfig{}, output.Errorf(output.CodeNotFound, "animation state '%s' has no frames", state)
+	}
 
-		return displayArt(anim)
-	},
+	return key, anim, nil
 }
 
-func displayArt(anim pet.AnimationConfig) error {
+// displayArt plays or prints the animation key chosen for the requested state
+func displayArt(state, key string, anim pet.AnimationConfig) error {
+	if structured() {
+		doc := output.Art{State: state, Key: key, Source: artSource(anim), FPS: anim.FPS, Loops: anim.Loops, PingPong: anim.PingPong, Frames: []output.Frame{}}
+		for _, frame := range anim.Frames {
+			doc.Frames = append(doc.Frames, output.Frame{Lines: output.Lines(art.PlainFrame(anim, frame)), MS: frame.MS})
+		}
+		return emit(output.KindArt, doc)
+	}
+
 	// If animation has multiple frames, play the animation
 	// Otherwise just show the first frame
 	if len(anim.Frames) > 1 {
-		// Play animation
-		if anim.Source == "pixel" {
-			art.PlayPixelAnimation(anim)
-		} else {
-			art.PlayAnimation(anim)
-		}
+		// Play animation, until Ctrl-C when it loops forever
+		art.PlayAnimation(anim)
 		return nil
 	}
 
 	// Single frame - just display it
 	if len(anim.Frames) > 0 {
-		if anim.Source == "pixel" {
+		if anim.Pixel() {
 			// Render pixel art
 			rendered := art.RenderPixelArt(anim.Frames[0])
 			fmt.Print(rendered)
@@ -891,85 +1167,47 @@ func displayArt(anim pet.AnimationConfig) error {
 	return nil
 }
 
+// loadFrames loads the frames of the file and url animations among keys, with
+// file paths relative to baseDir. A failure is reported on stderr and leaves
+// the animation without frames, so the built-in art is shown instead.
+func loadFrames(p *pet.Pet, baseDir string, keys ...string) {
+	for _, key := range keys {
+		anim, exists := p.Config.Animations[key]
+		if !exists || !anim.External() {
+			continue
+		}
+		frames, err := storage.LoadFrames(anim, baseDir, p.Config.CacheTTL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "familiar: animation '%s': %v\n", key, err)
+		}
+		anim.Frames = frames
+		if err := p.Config.Palette(pet.DefaultPalette).Check(anim); err != nil {
+			fmt.Fprintf(os.Stderr, "familiar: animation '%s': %v\n", key, err)
+			anim.Frames = nil
+		}
+		p.Config.Animations[key] = anim
+	}
+}
+
+// artSource is an animation's source, which defaults to inline
+func artSource(anim pet.AnimationConfig) string {
+	if anim.Source == "" {
+		return "inline"
+	}
+	return anim.Source
+}
+
 func init() {
 	adminCmd.AddCommand(adminHealthCmd)
 	adminCmd.AddCommand(adminCompletionCmd)
 	adminCmd.AddCommand(adminUpdateCmd)
+	adminCmd.AddCommand(adminHooksCmd)
+	adminCmd.AddCommand(adminSchemaCmd)
 	adminArtCmd.Flags().IntP("evolution", "e", -1, "Evolution level to preview (default: current evolution for installed pet, 1 for templates)")
 	adminArtCmd.Flags().StringP("type", "t", "", "Pet type template to use (cat, dancer, pixel) - ignores installed familiar")
 	adminCmd.AddCommand(adminArtCmd)
 }
 
-var messageCmd = &cobra.Command{
-	Use:   "message [text]",
-	Short: "Set a message for your familiar",
-	Args:  cobra.MinimumNArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return executeStatefulCommand(func(p *pet.Pet) error {
-			message := args[0]
-			p.State.Message = message
-			fmt.Printf("Message set: %s\n", message)
-			return nil
-		})
-	},
-}
-
-var acknowledgeCmd = &cobra.Command{
-	Use:   "acknowledge",
-	Short: "Acknowledge your familiar (clears message, improves mood)",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		silent, _ := cmd.Flags().GetBool("silent")
-		return executeStatefulCommand(func(p *pet.Pet) error {
-			hadMessage := p.State.Message != ""
-			p.State.Message = ""
-
-			if hadMessage {
-				// If there was a message, boost everything to 100
-				p.State.Hunger = 0 // 0 = not hungry (best)
-				p.State.Happiness = 100
-				p.State.Energy = 100
-			} else {
-				// If no message, boost by 5
-				p.State.Hunger = max(0, p.State.Hunger-5) // Decrease hunger (lower is better)
-				p.State.Happiness = min(100, p.State.Happiness+5)
-				p.State.Energy = min(100, p.State.Energy+5)
-			}
-
-			if silent {
-				// Silent mode: no output
-				return nil
-			}
-
-			// Normal mode: show name, condition, art, and confirmation
-			now := time.Now()
-			health := health.ComputeHealth(p.State.Hunger, p.State.Happiness, p.State.Energy, health.ComputationMode(p.Config.HealthComputation))
-			status := conditions.DeriveStatus(p, now, health)
-
-			name := p.Config.Name
-			if p.State.NameOverride != "" {
-				name = p.State.NameOverride
-			}
-
-			// After acknowledge, show "happy" if stats are good (as per README)
-			displayCondition := status.Primary
-			if p.State.Hunger < 30 && p.State.Happiness > 70 && p.State.Energy > 50 {
-				displayCondition = conditions.CondHappy
-			}
-
-			fmt.Printf("%s\n", name)
-			fmt.Printf("%s\n\n", displayCondition)
-			fmt.Println(art.GetStaticArt(p, status))
-			fmt.Printf("%s feels acknowledged\n", name)
-
-			return nil
-		})
-	},
-}
-
-func init() {
-	acknowledgeCmd.Flags().BoolP("silent", "s", false, "Silent mode: no output")
-}
-
 var dismissCmd = &cobra.Command{
 	Use:   "dismiss",
 	Short: "Dismiss your familiar (soft delete - can be restored)",
@@ -989,8 +1227,8 @@ var dismissCmd = &cobra.Command{
 			return fmt.Errorf("failed to dismiss familiar: %w", err)
 		}
 
-		fmt.Printf("Familiar '%s' has been dismissed (can be restored with 'summon')\n", petName)
-		return nil
+		say("Familiar '%s' has been dismissed (can be restored with 'summon')", petName)
+		return emitAction(&output.Action{Action: "dismiss", Familiar: petName})
 	},
 }
 
@@ -998,7 +1236,7 @@ var awakenCmd = &cobra.Command{
 	Use:   "awaken",
 	Short: "Awaken your familiar from stone or sleep state",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return executeStatefulCommand(func(p *pet.Pet) error {
+		return runAction("awaken", func(p *pet.Pet, a *output.Action) error {
 			now := time.Now()
 			health := health.ComputeHealth(p.State.Hunger, p.State.Happiness, p.State.Energy, health.ComputationMode(p.Config.HealthComputation))
 			status := conditions.DeriveStatus(p, now, health)
@@ -1009,7 +1247,7 @@ var awakenCmd = &cobra.Command{
 
 			if !isStone && !isAsleep {
 				currentState := conditions.FormatConditions(status.AllOrdered)
-				return fmt.Errorf("your familiar is not stone or asleep. It is %s", currentState)
+				return output.Errorf(output.CodeState, "your familiar is not stone or asleep. It is %s", currentState)
 			}
 
 			petName := p.Config.Name
@@ -1037,7 +1275,7 @@ var awakenCmd = &cobra.Command{
 				p.State.Happiness = targetHealth
 				p.State.Energy = targetHealth
 
-				fmt.Printf("%s has awakened from stone!\n", petName)
+				say("%s has awakened from stone!", petName)
 			}
 
 			if isAsleep {
@@ -1046,7 +1284,7 @@ var awakenCmd = &cobra.Command{
 				p.State.SleepUntil = time.Time{}
 				p.State.SleepAttempts = 0
 
-				fmt.Printf("%s has awakened from sleep!\n", petName)
+				say("%s has awakened from sleep!", petName)
 			}
 
 			return nil
@@ -1058,9 +1296,9 @@ var ossifyCmd = &cobra.Command{
 	Use:   "ossify",
 	Short: "Turn your familiar to stone",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return executeStatefulCommand(func(p *pet.Pet) error {
+		return runAction("ossify", func(p *pet.Pet, a *output.Action) error {
 			if p.State.IsStone {
-				return fmt.Errorf("your familiar is already stone")
+				return output.Errorf(output.CodeState, "your familiar is already stone")
 			}
 
 			// Set to stone state (overrides sleep)
@@ -1074,7 +1312,7 @@ var ossifyCmd = &cobra.Command{
 				petName = p.State.NameOverride
 			}
 
-			fmt.Printf("%s has turned to stone\n", petName)
+			say("%s has turned to stone", petName)
 			return nil
 		})
 	},
@@ -1084,7 +1322,7 @@ var healCmd = &cobra.Command{
 	Use:   "heal",
 	Short: "Heal your familiar (boost energy and happiness, remove infirm)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return executeStatefulCommand(func(p *pet.Pet) error {
+		return runAction("heal", func(p *pet.Pet, a *output.Action) error {
 			// Boost energy and happiness by 3
 			p.State.Energy = min(100, p.State.Energy+3)
 			p.State.Happiness = min(100, p.State.Happiness+3)
@@ -1099,7 +1337,7 @@ var healCmd = &cobra.Command{
 				petName = p.State.NameOverride
 			}
 
-			fmt.Printf("%s has been healed\n", petName)
+			say("%s has been healed", petName)
 			return nil
 		})
 	},
@@ -1124,8 +1362,8 @@ var banishCmd = &cobra.Command{
 			return fmt.Errorf("failed to banish familiar: %w", err)
 		}
 
-		fmt.Printf("Familiar '%s' has been banished (permanently deleted)\n", petName)
-		return nil
+		say("Familiar '%s' has been banished (permanently deleted)", petName)
+		return emitAction(&output.Action{Action: "banish", Familiar: petName})
 	},
 }
 
@@ -1203,6 +1441,12 @@ func detectPetType(p *pet.Pet) (string, error) {
 		if err := toml.Unmarshal([]byte(templateContent), &templateConfig); err != nil {
 			continue
 		}
+		if err := templateConfig.ExpandGrids(); err != nil {
+			continue
+		}
+		if err := templateConfig.ValidatePalettes(); err != nil {
+			continue
+		}
 
 		// Compare default animations to see if they match
 		if matchesPetType(p, &templateConfig) {
@@ -1293,6 +1537,13 @@ func mergeConfig(existing, template pet.PetConfig) pet.PetConfig {
 
 	// Preserve user's animation preferences
 	merged.AllowAnsiAnimations = existing.AllowAnsiAnimations
+	if existing.SpeechBubble != "" {
+		merged.SpeechBubble = existing.SpeechBubble
+	}
+	if existing.Graphics != "" {
+		merged.Graphics = existing.Graphics
+	}
+	merged.Braille = existing.Braille
 
 	// Preserve user customizations for decay/behavior settings
 	// These are things users might have tuned for their pet
@@ -1318,6 +1569,23 @@ func mergeConfig(existing, template pet.PetConfig) pet.PetConfig {
 	merged.HealthComputation = existing.HealthComputation
 	merged.InteractionThreshold = existing.InteractionThreshold
 	merged.CacheTTL = existing.CacheTTL
+	merged.AckStore = existing.AckStore
+
+	// Watches, schedules and the gate are project-specific; keep the user's unless they have none yet
+	if len(existing.Watches) > 0 {
+		merged.Watches = existing.Watches
+	}
+	if len(existing.Schedules) > 0 {
+		merged.Schedules = existing.Schedules
+	}
+
+	// A customised prompt wins; otherwise pick up the template's icon
+	if !existing.Prompt.IsZero() {
+		merged.Prompt = existing.Prompt
+	}
+	if existing.Gate.Severity != "" || len(existing.Gate.Hooks) > 0 {
+		merged.Gate = existing.Gate
+	}
 
 	return merged
 }
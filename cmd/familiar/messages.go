@@ -0,0 +1,529 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/art"
+	"github.com/sethgrid/familiar/internal/conditions"
+	"github.com/sethgrid/familiar/internal/duration"
+	"github.com/sethgrid/familiar/internal/health"
+	"github.com/sethgrid/familiar/internal/identity"
+	"github.com/sethgrid/familiar/internal/markdown"
+	"github.com/sethgrid/familiar/internal/output"
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/storage"
+	"github.com/sethgrid/familiar/internal/term"
+	"github.com/spf13/cobra"
+)
+
+var messageCmd = &cobra.Command{
+	Use:   "message [text]",
+	Short: "Add a message for your familiar to deliver",
+	Long: `Add a message to your familiar's queue.
+
+The text is the arguments joined by spaces, a file (--file, "-" for stdin), or
+standard input when it is piped. Messages may use Markdown; 'familiar status'
+renders headings, emphasis, lists, code and links for the terminal.
+
+Examples:
+  familiar message ship is red
+  familiar message add --severity critical --expires 3d "rotate your API keys"
+  familiar message add --link https://example.com/runbook "deploy freeze today"
+  familiar message -f NOTES.md
+  git log -1 --format=%B | familiar message`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		if len(args) == 0 && file == "" && !term.IsRedirected(os.Stdin) {
+			return cmd.Help()
+		}
+		return runMessageAdd(cmd, args)
+	},
+}
+
+var messageAddCmd = &cobra.Command{
+	Use:   "add [text]",
+	Short: "Add a message to the queue",
+	Args:  cobra.ArbitraryArgs,
+	RunE:  runMessageAdd,
+}
+
+// maxMessageBytes keeps long-form messages from bloating the shared state file
+const maxMessageBytes = 16 * 1024
+
+func addMessageFlags(cmd *cobra.Command) {
+	cmd.Flags().String("severity", "info", "Message severity: info, warn or critical")
+	cmd.Flags().String("expires", "", "Expire the message after a duration (e.g. 12h, 3d, 1w)")
+	cmd.Flags().StringSlice("link", nil, "Link to attach to the message (repeatable)")
+	cmd.Flags().StringP("file", "f", "", "Read the message from a file (\"-\" for stdin)")
+}
+
+// readMessageText returns the message text from --file, the arguments or piped stdin
+func readMessageText(cmd *cobra.Command, args []string) (string, error) {
+	file, _ := cmd.Flags().GetString("file")
+	if file != "" && len(args) > 0 {
+		return "", fmt.Errorf("pass the message as arguments or with --file, not both")
+	}
+
+	var text string
+	switch {
+	case file == "-" || (file == "" && len(args) == 0 && term.IsRedirected(os.Stdin)):
+		data, err := io.ReadAll(io.LimitReader(os.Stdin, maxMessageBytes+1))
+		if err != nil {
+			return "", fmt.Errorf("failed to read message from stdin: %w", err)
+		}
+		text = string(data)
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read message file: %w", err)
+		}
+		text = string(data)
+	default:
+		text = strings.Join(args, " ")
+	}
+
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return "", fmt.Errorf("message is empty")
+	}
+	if len(text) > maxMessageBytes {
+		return "", fmt.Errorf("message is longer than %d KB", maxMessageBytes/1024)
+	}
+	return text, nil
+}
+
+func runMessageAdd(cmd *cobra.Command, args []string) error {
+	severityFlag, _ := cmd.Flags().GetString("severity")
+	expiresFlag, _ := cmd.Flags().GetString("expires")
+	links, _ := cmd.Flags().GetStringSlice("link")
+
+	text, err := readMessageText(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	severity, err := pet.ParseSeverity(severityFlag)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var expiresAt time.Time
+	if expiresFlag != "" {
+		d, err := duration.Parse(expiresFlag)
+		if err != nil {
+			return err
+		}
+		expiresAt = now.Add(d)
+	}
+
+	return runAction("message-add", func(p *pet.Pet, a *output.Action) error {
+		m := p.State.AddMessage(pet.Message{
+			Text:      text,
+			Severity:  severity,
+			Author:    identity.Current().String(),
+			CreatedAt: now,
+			ExpiresAt: expiresAt,
+			Links:     links,
+		})
+		// The author has obviously read their own message
+		m, err := p.AcknowledgeMessage(m.ID, now)
+		if err != nil {
+			return err
+		}
+		a.Messages = append(a.Messages, output.NewMessage(p, m))
+		say("Message set [%s]: %s", m.ID, messageTitle(m.Text))
+		return nil
+	})
+}
+
+var messagesCmd = &cobra.Command{
+	Use:   "messages",
+	Short: "Inspect your familiar's message queue",
+}
+
+var messagesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List queued messages",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		var doc output.Messages
+		err := executeStatefulCommand(func(p *pet.Pet) error {
+			now := time.Now()
+			messages := p.PendingMessages(now)
+			if all {
+				messages = p.State.ActiveMessages(now)
+			}
+			if structured() {
+				doc.Messages = output.NewMessages(p, messages)
+				return nil
+			}
+			if len(messages) == 0 {
+				fmt.Println("No messages")
+				return nil
+			}
+			for _, m := range messages {
+				line := formatMessageLine(m, now)
+				if all && p.Acknowledged(m) {
+					line += " ✓"
+				}
+				fmt.Println(line)
+				for _, link := range m.Links {
+					fmt.Printf("         %s\n", link)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return emit(output.KindMessages, doc)
+	},
+}
+
+var messageRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Remove a message from the queue for everyone",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAction("message-remove", func(p *pet.Pet, a *output.Action) error {
+			m, err := p.State.RemoveMessage(args[0])
+			if err != nil {
+				return err
+			}
+			a.Messages = append(a.Messages, output.NewMessage(p, m))
+			say("Message removed [%s]: %s", m.ID, messageTitle(m.Text))
+			return nil
+		})
+	},
+}
+
+var messageStatsCmd = &cobra.Command{
+	Use:   "stats <id>",
+	Short: "Show who has and has not acknowledged a message",
+	Long: `Show who has and has not acknowledged a message.
+
+Acknowledgements are read from the shared state and from every per-user file
+in .familiar/acks. People who have not acknowledged are drawn from those users
+and from commit authors active in the repository from 30 days before the
+message was posted onwards.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, _, statePath, err := loadPet()
+		if err != nil {
+			return err
+		}
+
+		m, err := p.State.FindMessage(args[0])
+		if err != nil {
+			return err
+		}
+
+		userAcks, err := storage.LoadAllUserAcks(filepath.Dir(statePath))
+		if err != nil {
+			return err
+		}
+
+		acked := make(map[string]time.Time)
+		for _, a := range m.AckedBy {
+			acked[a.User] = a.At
+		}
+		for user, acks := range userAcks {
+			if at, ok := acks[m.ID]; ok {
+				acked[user] = at
+			}
+		}
+
+		// Everyone we know about: users with acknowledgement files and recent committers
+		var pending []string
+		seen := make(map[string]bool)
+		candidates := identity.RecentContributors(m.CreatedAt.Add(-30 * 24 * time.Hour))
+		for user := range userAcks {
+			candidates = append(candidates, user)
+		}
+		for _, user := range candidates {
+			if seen[user] {
+				continue
+			}
+			seen[user] = true
+			if _, ok := acked[user]; !ok {
+				pending = append(pending, user)
+			}
+		}
+		sort.Strings(pending)
+
+		ackedUsers := make([]string, 0, len(acked))
+		for user := range acked {
+			ackedUsers = append(ackedUsers, user)
+		}
+		sort.Slice(ackedUsers, func(i, j int) bool {
+			return acked[ackedUsers[i]].Before(acked[ackedUsers[j]])
+		})
+
+		if structured() {
+			stats := output.MessageStats{Message: output.NewMessage(p, *m), Acknowledged: []output.Acknowledgement{}, Pending: []string{}}
+			for _, user := range ackedUsers {
+				stats.Acknowledged = append(stats.Acknowledged, output.Acknowledgement{User: user, At: acked[user]})
+			}
+			stats.Pending = append(stats.Pending, pending...)
+			return emit(output.KindMessageStats, stats)
+		}
+
+		fmt.Println(formatMessageLine(*m, time.Now()))
+		fmt.Printf("\nAcknowledged (%d):\n", len(ackedUsers))
+		for _, user := range ackedUsers {
+			fmt.Printf("  %s  %s\n", user, acked[user].Local().Format("2006-01-02 15:04"))
+		}
+		fmt.Printf("\nNot acknowledged (%d):\n", len(pending))
+		for _, user := range pending {
+			fmt.Printf("  %s\n", user)
+		}
+		return nil
+	},
+}
+
+func init() {
+	addMessageFlags(messageCmd)
+	addMessageFlags(messageAddCmd)
+	messageCmd.AddCommand(messageAddCmd)
+	messageCmd.AddCommand(messageRemoveCmd)
+	messageCmd.AddCommand(messageStatsCmd)
+	messagesListCmd.Flags().Bool("all", false, "Include messages you have already acknowledged")
+	messagesCmd.AddCommand(messagesListCmd)
+}
+
+var acknowledgeCmd = &cobra.Command{
+	Use:   "acknowledge [id...]",
+	Short: "Acknowledge your familiar (clears messages, improves mood)",
+	Long: `Acknowledge messages and give your familiar some attention.
+
+With no arguments a single pending message is acknowledged; when several
+are queued, pass their ids or use --all. Acknowledgements are per user, so
+teammates keep seeing a message until they have acknowledged it themselves.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		silent, _ := cmd.Flags().GetBool("silent")
+		all, _ := cmd.Flags().GetBool("all")
+		return runAction("acknowledge", func(p *pet.Pet, a *output.Action) error {
+			now := time.Now()
+			active := p.PendingMessages(now)
+
+			ids := args
+			if all || (len(ids) == 0 && len(active) == 1) {
+				ids = nil
+				for _, m := range active {
+					ids = append(ids, m.ID)
+				}
+			} else if len(ids) == 0 && len(active) > 1 {
+				return output.Errorf(output.CodeAmbiguous, "%d messages are pending. Pass an id or use --all (see 'familiar messages list')", len(active))
+			}
+
+			// Acknowledging a message again is allowed but earns no boost
+			newlyAcked := false
+			for _, id := range ids {
+				if m, err := p.State.FindMessage(id); err == nil && !p.Acknowledged(*m) {
+					newlyAcked = true
+				}
+				m, err := p.AcknowledgeMessage(id, now)
+				if err != nil {
+					return err
+				}
+				a.Messages = append(a.Messages, output.NewMessage(p, m))
+			}
+
+			if newlyAcked {
+				// If a message was read, boost everything to 100
+				p.State.Hunger = 0 // 0 = not hungry (best)
+				p.State.Happiness = 100
+				p.State.Energy = 100
+			} else {
+				// If no message, boost by 5
+				p.State.Hunger = max(0, p.State.Hunger-5) // Decrease hunger (lower is better)
+				p.State.Happiness = min(100, p.State.Happiness+5)
+				p.State.Energy = min(100, p.State.Energy+5)
+			}
+
+			if silent || structured() {
+				// Silent mode: no output
+				return nil
+			}
+
+			// Normal mode: show name, condition, art, and confirmation
+			health := health.ComputeHealth(p.State.Hunger, p.State.Happiness, p.State.Energy, health.ComputationMode(p.Config.HealthComputation))
+			status := conditions.DeriveStatus(p, now, health)
+			if statePath, err := findStatePath(); err == nil {
+				loadFrames(p, filepath.Dir(statePath), art.ChooseAnimationKey(status.Conditions, p.State.Evolution, p.Config.Animations))
+			}
+
+			name := p.Config.Name
+			if p.State.NameOverride != "" {
+				name = p.State.NameOverride
+			}
+
+			// After acknowledge, show "happy" if stats are good (as per README)
+			displayCondition := status.Primary
+			if p.State.Hunger < 30 && p.State.Happiness > 70 && p.State.Energy > 50 {
+				displayCondition = conditions.CondHappy
+			}
+
+			fmt.Printf("%s\n", name)
+			fmt.Printf("%s\n\n", displayCondition)
+			fmt.Println(art.GetStaticArt(p, status))
+			fmt.Printf("%s feels acknowledged\n", name)
+
+			return nil
+		})
+	},
+}
+
+func init() {
+	acknowledgeCmd.Flags().BoolP("silent", "s", false, "Silent mode: no output")
+	acknowledgeCmd.Flags().Bool("all", false, "Acknowledge every pending message")
+}
+
+// printMessageSummary prints the message section shown under the familiar's art.
+// A single one-line message keeps the original "Message: ..." layout; longer
+// messages are rendered as Markdown below a header.
+func printMessageSummary(messages []pet.Message, now time.Time) {
+	switch len(messages) {
+	case 0:
+		return
+	case 1:
+		text := messages[0].Text
+		if !strings.Contains(text, "\n") {
+			rendered := renderMessage(text, len("Message: "))
+			fmt.Printf("\nMessage: %s\n", indentLines(rendered, strings.Repeat(" ", len("Message: "))))
+			return
+		}
+		fmt.Printf("\nMessage:\n  %s\n", indentLines(renderMessage(text, 2), "  "))
+		return
+	}
+
+	fmt.Printf("\nMessages (%d, highest: %s):\n", len(messages), pet.HighestSeverity(messages))
+	for _, m := range messages {
+		fmt.Printf("  %s\n", formatMessageLine(m, now))
+		if body := messageBody(m.Text); body != "" {
+			fmt.Printf("      %s\n", indentLines(renderMessage(body, 6), "      "))
+		}
+	}
+}
+
+const (
+	// maxBubbleWidth keeps speech bubbles readable on wide terminals
+	maxBubbleWidth = 48
+	// minBubbleWidth is the narrowest bubble worth drawing; below it messages
+	// are printed under the art instead
+	minBubbleWidth = 20
+	// maxBubbleLines caps the bubble's height; the rest is in 'messages list'
+	maxBubbleLines = 12
+)
+
+// messageBubble returns the speech bubble the familiar shows for its pending
+// messages, or nil when there is nothing to say, output is not a terminal, the
+// terminal is too narrow or bubbles are turned off
+func messageBubble(p *pet.Pet, status conditions.DerivedStatus, messages []pet.Message) []string {
+	style := p.Config.SpeechBubble
+	if len(messages) == 0 || style == "off" || !term.IsTerminal(os.Stdout) {
+		return nil
+	}
+	width := min(maxBubbleWidth, term.Width(os.Stdout)-art.ArtWidth(p, status)-art.BubbleOverhead())
+	if width < minBubbleWidth {
+		return nil
+	}
+	opts := markdown.Options{Width: width, Color: term.ColorDepth(os.Stdout) != term.DepthNone}
+
+	var lines []string
+	if len(messages) == 1 {
+		lines = strings.Split(markdown.Render(messages[0].Text, opts), "\n")
+	} else {
+		for _, m := range messages {
+			// With the id, as in the message list, to acknowledge it by. Only
+			// the title is Markdown; wrapped lines are indented past the prefix.
+			prefix := fmt.Sprintf("[%s] %s: ", m.ID, m.Severity)
+			title, _, _ := strings.Cut(strings.TrimSpace(m.Text), "\n")
+			titleOpts := opts
+			titleOpts.Width = max(minBubbleWidth/2, width-len(prefix))
+			line := indentLines(prefix+markdown.Render(title, titleOpts), strings.Repeat(" ", len(prefix)))
+			lines = append(lines, strings.Split(line, "\n")...)
+		}
+	}
+	if len(lines) > maxBubbleLines {
+		lines = append(lines[:maxBubbleLines-1], "… see 'familiar messages list'")
+	}
+	return art.Bubble(lines, art.BubbleStyle(style))
+}
+
+// renderMessage renders message Markdown for stdout, wrapped to the terminal
+// width less indent. Styles and hyperlinks are only used on a terminal.
+func renderMessage(text string, indent int) string {
+	return markdown.Render(text, markdown.Options{
+		Width: max(20, term.Width(os.Stdout)-indent),
+		Color: term.ColorDepth(os.Stdout) != term.DepthNone,
+	})
+}
+
+// indentLines prefixes every non-empty line after the first
+func indentLines(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = prefix + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// messageTitle returns the first line of a message as plain text, for one-line listings
+func messageTitle(text string) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return markdown.Render(title, markdown.Options{})
+}
+
+// messageBody returns everything after a message's first line
+func messageBody(text string) string {
+	_, body, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(body)
+}
+
+// formatMessageLine renders one message as "[id] severity title (author, expiry)".
+// Multi-line messages show their first line followed by an ellipsis.
+func formatMessageLine(m pet.Message, now time.Time) string {
+	var details []string
+	if m.Author != "" {
+		details = append(details, m.Author)
+	}
+	if !m.ExpiresAt.IsZero() {
+		details = append(details, "expires in "+formatRemaining(m.ExpiresAt.Sub(now)))
+	}
+
+	title := messageTitle(m.Text)
+	if messageBody(m.Text) != "" {
+		title += " …"
+	}
+	line := fmt.Sprintf("[%s] %-8s %s", m.ID, m.Severity, title)
+	if len(details) > 0 {
+		line += " (" + strings.Join(details, ", ") + ")"
+	}
+	return line
+}
+
+// formatRemaining renders a duration coarsely, e.g. "2d", "5h" or "12m"
+func formatRemaining(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return "<1m"
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/output"
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputFlag   string
+	outputFormat = output.Text
+
+	// notes collects what say would have printed, for the action document
+	notes []string
+
+	// started is set once flags and arguments are valid; errors before it are usage errors
+	started bool
+)
+
+var adminSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of --output json and yaml documents",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := os.Stdout.Write(output.Schema)
+		return err
+	},
+}
+
+// initOutput selects the document format, silencing cobra's own error and usage text
+func initOutput(root *cobra.Command) {
+	if format, err := output.ParseFormat(outputFlag); err == nil && format != output.Text {
+		outputFormat = format
+		root.SilenceErrors = true
+		root.SilenceUsage = true
+	}
+}
+
+// structured reports whether commands write a document instead of text
+func structured() bool {
+	return outputFormat != output.Text
+}
+
+// say prints a line of text output. With --output json or yaml it is kept
+// as a note of the action document instead.
+func say(format string, args ...any) {
+	if structured() {
+		notes = append(notes, fmt.Sprintf(format, args...))
+		return
+	}
+	fmt.Printf(format+"\n", args...)
+}
+
+// emit writes a document when the output is structured, and does nothing for text
+func emit(kind string, data any) error {
+	if !structured() {
+		return nil
+	}
+	return output.Write(os.Stdout, outputFormat, kind, data)
+}
+
+// emitAction writes an action document with the notes collected by say
+func emitAction(a *output.Action) error {
+	a.Notes = append([]string{}, notes...)
+	notes = nil
+	return emit(output.KindAction, a)
+}
+
+// runAction is executeStatefulCommand for commands that change the familiar:
+// it records the stats before and after fn and emits the action document
+func runAction(name string, fn func(p *pet.Pet, a *output.Action) error) error {
+	a := &output.Action{Action: name}
+	err := executeStatefulCommand(func(p *pet.Pet) error {
+		a.Familiar = output.DisplayName(p)
+		before := output.NewStats(p)
+		unlocked := p.UnlockedAccessories()
+		if err := fn(p, a); err != nil {
+			return err
+		}
+		announceUnlocks(p, unlocked)
+		a.SetStats(before, output.NewStats(p))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return emitAction(a)
+}
+
+// announceUnlocks awards the achievements the familiar has earned and says
+// which of them, and which accessories unlocked since before, are new
+func announceUnlocks(p *pet.Pet, before []string) {
+	for _, achievement := range p.State.AwardAchievements(time.Now()) {
+		say("Achievement unlocked: %s (%s)", achievement.ID, achievement.Description)
+	}
+	for _, name := range p.UnlockedAccessories() {
+		if !slices.Contains(before, name) {
+			say("New accessory: %s. Put it on with 'familiar wear %s'", name, name)
+		}
+	}
+}
+
+// reportedError is an error whose details were already written as a document,
+// such as a closed gate; it only sets the exit status
+type reportedError struct {
+	error
+}
+
+// reportError prints err to stderr, or writes an error document
+func reportError(err error) {
+	if !started {
+		err = output.WithCode(output.CodeUsage, err)
+	}
+	if !structured() {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
+	}
+	var reported reportedError
+	if errors.As(err, &reported) {
+		return
+	}
+	if werr := output.WriteError(os.Stdout, outputFormat, err); werr != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sethgrid/familiar/internal/art"
+	"github.com/sethgrid/familiar/internal/output"
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/term"
+	"github.com/spf13/cobra"
+)
+
+var recolorCmd = &cobra.Command{
+	Use:   "recolor [palette]",
+	Short: "Swap your familiar's colour palette",
+	Long: `Draw your familiar's pixel art with another of its palettes, such as shiny.
+With no palette, list the palettes; 'default' restores the original colours.
+
+Examples:
+  familiar recolor
+  familiar recolor shiny
+  familiar recolor default`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return listPalettes()
+		}
+
+		name := args[0]
+		return runAction("recolor", func(p *pet.Pet, a *output.Action) error {
+			if _, ok := p.Config.Palettes[name]; !ok && name != pet.DefaultPalette {
+				return output.Errorf(output.CodeNotFound, "no palette '%s'. Use 'familiar recolor' to see them", name)
+			}
+			if name == pet.DefaultPalette {
+				name = ""
+			}
+			p.State.Palette = name
+			say("Your familiar is now %s", paletteName(name))
+			return nil
+		})
+	},
+}
+
+// paletteName names the palette of the state, which is empty for the default
+func paletteName(name string) string {
+	if name == "" {
+		return pet.DefaultPalette
+	}
+	return name
+}
+
+// listPalettes prints the familiar's palettes, marking the active one
+func listPalettes() error {
+	var doc output.Palettes
+	err := executeStatefulCommand(func(p *pet.Pet) error {
+		doc.Active = paletteName(p.State.Palette)
+		names := make([]string, 0, len(p.Config.Palettes))
+		for name := range p.Config.Palettes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		doc.Palettes = []output.Palette{}
+		for _, name := range names {
+			doc.Palettes = append(doc.Palettes, output.Palette{Name: name, Colors: p.Config.Palette(name)})
+		}
+		if structured() {
+			return nil
+		}
+
+		if len(names) == 0 {
+			fmt.Println("Your familiar's art has no palettes")
+			return nil
+		}
+		for _, pal := range doc.Palettes {
+			mark := " "
+			if pal.Name == doc.Active {
+				mark = "*"
+			}
+			fmt.Printf("%s %-12s %s\n", mark, pal.Name, paletteSwatch(pal.Colors))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return emit(output.KindPalettes, doc)
+}
+
+// paletteSwatch shows the colours of pal in symbol order, or names the
+// symbols when the terminal has no colour
+func paletteSwatch(pal pet.Palette) string {
+	symbols := make([]string, 0, len(pal))
+	for symbol := range pal {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	depth := term.ColorDepth(os.Stdout)
+	if depth == term.DepthNone {
+		return strings.Join(symbols, ", ")
+	}
+	row := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		row[i] = pal[symbol]
+	}
+	return art.RenderPixelArtDepth(pet.Frame{Pixels: [][]string{row, row}}, depth)
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/duration"
+	"github.com/sethgrid/familiar/internal/output"
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// reminderGlyph marks due reminders in the prompt, distinct from the message triangle
+const reminderGlyph = "⏰"
+
+var remindCmd = &cobra.Command{
+	Use:   "remind <when> <text>",
+	Short: "Set a personal reminder",
+	Long: `Set a reminder that only you will see, once it is due.
+
+<when> is a duration (2h, 1d), a time of day (15:30, today or tomorrow), a
+date (2026-11-02) or a date and time ("2026-11-02 09:00").
+
+Reminders are stored per user under $XDG_STATE_HOME/familiar (default
+~/.local/state/familiar), never in the repository's .familiar directory.
+
+Examples:
+  familiar remind 2h check the deploy
+  familiar remind 17:00 "submit timesheet"
+  familiar remind snooze 4f2a --for 30m
+  familiar remind dismiss 4f2a`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Help()
+		}
+		if len(args) < 2 {
+			return output.Errorf(output.CodeUsage, "usage: familiar remind <when> <text>")
+		}
+
+		now := time.Now()
+		due, err := duration.ParseDue(args[0], now)
+		if err != nil {
+			return err
+		}
+
+		a := &output.Action{Action: "remind"}
+		err = updateReminders(func(local *pet.LocalState) error {
+			r := local.AddReminder(pet.Reminder{
+				Text:      strings.Join(args[1:], " "),
+				Due:       due,
+				CreatedAt: now,
+			})
+			a.Reminders = append(a.Reminders, output.NewReminder(r, now))
+			say("Reminder set [%s], due %s: %s", r.ID, formatDue(r.Due, now), r.Text)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return emitAction(a)
+	},
+}
+
+var remindSnoozeCmd = &cobra.Command{
+	Use:   "snooze [id]",
+	Short: "Push a due reminder back (default 1h)",
+	Long: `Push a reminder back by --for (default 1h). With no id, the single due
+reminder is snoozed, so 'familiar remind snooze --for 30m' works too.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		forFlag, _ := cmd.Flags().GetString("for")
+		snooze, err := duration.Parse(forFlag)
+		if err != nil {
+			return output.WithCode(output.CodeUsage, err)
+		}
+
+		now := time.Now()
+		a := &output.Action{Action: "remind-snooze"}
+		err = updateReminders(func(local *pet.LocalState) error {
+			id, err := reminderArg(local, args, now)
+			if err != nil {
+				return err
+			}
+			r, err := local.FindReminder(id)
+			if err != nil {
+				return err
+			}
+			r.Due = now.Add(snooze)
+			r.Snoozes++
+			a.Reminders = append(a.Reminders, output.NewReminder(*r, now))
+			say("Reminder snoozed [%s], due %s: %s", r.ID, formatDue(r.Due, now), r.Text)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return emitAction(a)
+	},
+}
+
+var remindDismissCmd = &cobra.Command{
+	Use:   "dismiss [id]",
+	Short: "Dismiss a reminder",
+	Long:  `Delete a reminder. With no id, the single due reminder is dismissed.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		now := time.Now()
+		a := &output.Action{Action: "remind-dismiss"}
+		err := updateReminders(func(local *pet.LocalState) error {
+			id, err := reminderArg(local, args, now)
+			if err != nil {
+				return err
+			}
+			r, err := local.RemoveReminder(id)
+			if err != nil {
+				return err
+			}
+			a.Reminders = append(a.Reminders, output.NewReminder(r, now))
+			say("Reminder dismissed [%s]: %s", r.ID, r.Text)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return emitAction(a)
+	},
+}
+
+var remindersCmd = &cobra.Command{
+	Use:   "reminders",
+	Short: "Inspect your personal reminders",
+}
+
+var remindersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List your reminders, due first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		local, _, err := loadReminders()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		reminders := append([]pet.Reminder(nil), local.Reminders...)
+		sort.SliceStable(reminders, func(i, j int) bool { return reminders[i].Due.Before(reminders[j].Due) })
+		if structured() {
+			return emit(output.KindReminders, output.Reminders{Reminders: output.NewReminders(reminders, now)})
+		}
+		if len(reminders) == 0 {
+			fmt.Println("No reminders")
+			return nil
+		}
+
+		for _, r := range reminders {
+			marker := " "
+			if !r.Due.After(now) {
+				marker = reminderGlyph
+			}
+			fmt.Printf("%s [%s] %s  %s\n", marker, r.ID, formatDue(r.Due, now), r.Text)
+		}
+		return nil
+	},
+}
+
+func init() {
+	remindSnoozeCmd.Flags().String("for", "1h", "How long to snooze for, e.g. 30m or 1d")
+	remindCmd.AddCommand(remindSnoozeCmd)
+	remindCmd.AddCommand(remindDismissCmd)
+	remindersCmd.AddCommand(remindersListCmd)
+}
+
+// loadReminders loads the current user's local state for the discovered familiar
+func loadReminders() (*pet.LocalState, string, error) {
+	_, _, statePath, err := loadPet()
+	if err != nil {
+		return nil, "", err
+	}
+	petDir := filepath.Dir(statePath)
+	local, err := storage.LoadLocalState(petDir)
+	if err != nil {
+		return nil, "", err
+	}
+	return local, petDir, nil
+}
+
+// updateReminders loads the user's local state, applies fn and saves it
+func updateReminders(fn func(local *pet.LocalState) error) error {
+	local, petDir, err := loadReminders()
+	if err != nil {
+		return err
+	}
+	if err := fn(local); err != nil {
+		return err
+	}
+	return storage.SaveLocalState(petDir, local)
+}
+
+// reminderArg returns the id argument, or the id of the only due reminder
+func reminderArg(local *pet.LocalState, args []string, now time.Time) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	due := local.DueReminders(now)
+	switch len(due) {
+	case 0:
+		return "", output.Errorf(output.CodeNotFound, "no reminders are due. Pass an id (see 'familiar reminders list')")
+	case 1:
+		return due[0].ID, nil
+	}
+	return "", output.Errorf(output.CodeAmbiguous, "%d reminders are due. Pass an id (see 'familiar reminders list')", len(due))
+}
+
+// printReminderSummary prints due reminders under the familiar's art
+func printReminderSummary(reminders []pet.Reminder, now time.Time) {
+	if len(reminders) == 0 {
+		return
+	}
+	fmt.Println()
+	for _, r := range reminders {
+		fmt.Printf("%s Reminder [%s]: %s (due %s)\n", reminderGlyph, r.ID, r.Text, formatDue(r.Due, now))
+	}
+}
+
+// formatDue renders a due time relative to now, e.g. "in 2h" or "5m ago"
+func formatDue(due, now time.Time) string {
+	if due.After(now) {
+		return "in " + formatRemaining(due.Sub(now))
+	}
+	return formatRemaining(now.Sub(due)) + " ago"
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/output"
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage scheduled and recurring messages",
+	Long: `Schedules queue a message whenever their rule comes due. They live in pet.toml:
+
+  [[schedules]]
+  id = "standup"
+  message = "Standup notes are due"
+  every = "weekday 09:30"          # or: cron = "30 9 * * 1-5"
+  timezone = "Europe/Berlin"       # default: local time
+  severity = "info"
+  expires = "4h"                   # default: until the next occurrence
+  until = "2026-12-31"             # optional last day
+
+"every" accepts day, weekday, weekend, hour, or day names such as
+"monday" or "mon,thu", followed by an optional HH:MM time.
+
+Familiar has no background process, so a due message is queued the next time
+any familiar command runs. Occurrences missed in the meantime collapse into one
+message, which is skipped if it would already have expired.`,
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List schedules and when they next fire",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, _, _, err := loadPet()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if structured() {
+			doc := output.Schedules{Schedules: []output.Schedule{}}
+			for _, s := range p.Config.Schedules {
+				doc.Schedules = append(doc.Schedules, output.NewSchedule(s, now))
+			}
+			return emit(output.KindSchedules, doc)
+		}
+		if len(p.Config.Schedules) == 0 {
+			fmt.Println("No schedules configured")
+			return nil
+		}
+
+		for _, s := range p.Config.Schedules {
+			fmt.Printf("%s: %s\n", s.ID, s.Message)
+			if err := s.Validate(); err != nil {
+				fmt.Printf("  invalid: %v\n", err)
+				continue
+			}
+			rule, _ := s.Rule()
+			fmt.Printf("  rule: %s (%s)\n", rule, rule.Location())
+
+			next := rule.Next(now)
+			if ended, _ := s.Ended(next); ended || next.IsZero() {
+				fmt.Println("  next: never")
+			} else {
+				fmt.Printf("  next: %s (in %s)\n", next.Format("Mon 2006-01-02 15:04 MST"), formatRemaining(next.Sub(now)))
+			}
+		}
+		return nil
+	},
+}
+
+var scheduleAddCmd = &cobra.Command{
+	Use:   "add <message>",
+	Short: "Add a scheduled message",
+	Long: `Add a scheduled message to pet.toml.
+
+Examples:
+  familiar schedule add --every "monday 09:00" "Rotate the on-call pager"
+  familiar schedule add --cron "0 17 * * 5" --tz America/New_York --severity warn "Deploy freeze starts"
+
+Note: pet.toml is rewritten, so comments in it are not preserved.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s := pet.ScheduleConfig{Message: strings.Join(args, " ")}
+		s.ID, _ = cmd.Flags().GetString("id")
+		s.Cron, _ = cmd.Flags().GetString("cron")
+		s.Every, _ = cmd.Flags().GetString("every")
+		s.Timezone, _ = cmd.Flags().GetString("tz")
+		s.Expires, _ = cmd.Flags().GetString("expires")
+		s.Until, _ = cmd.Flags().GetString("until")
+		severityFlag, _ := cmd.Flags().GetString("severity")
+
+		if (s.Cron == "") == (s.Every == "") {
+			return output.Errorf(output.CodeUsage, "pass exactly one of --cron or --every")
+		}
+		severity, err := pet.ParseSeverity(severityFlag)
+		if err != nil {
+			return err
+		}
+		s.Severity = severity
+
+		p, petConfigPath, statePath, err := loadPet()
+		if err != nil {
+			return err
+		}
+
+		if s.ID == "" {
+			s.ID = pet.NewMessageID()
+		}
+		if findSchedule(p.Config.Schedules, s.ID) >= 0 {
+			return output.Errorf(output.CodeExists, "a schedule with id '%s' already exists", s.ID)
+		}
+		if err := s.Validate(); err != nil {
+			return err
+		}
+
+		if err := storage.AddSchedule(petConfigPath, s); err != nil {
+			return err
+		}
+
+		// Start counting from now so the schedule does not fire retroactively
+		now := time.Now()
+		if p.State.ScheduleRuns == nil {
+			p.State.ScheduleRuns = make(map[string]time.Time)
+		}
+		p.State.ScheduleRuns[s.ID] = now
+		if err := savePet(p, statePath); err != nil {
+			return err
+		}
+
+		rule, _ := s.Rule()
+		say("Schedule added [%s]: %s, %s", s.ID, rule, s.Message)
+		if next := rule.Next(now); !next.IsZero() {
+			say("Next: %s", next.Format("Mon 2006-01-02 15:04 MST"))
+		}
+		return emitAction(&output.Action{
+			Action:    "schedule-add",
+			Familiar:  output.DisplayName(p),
+			Schedules: []output.Schedule{output.NewSchedule(s, now)},
+		})
+	},
+}
+
+var scheduleRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Remove a scheduled message",
+	Long: `Remove a scheduled message from pet.toml. Messages it already queued stay
+in the queue; remove those with 'familiar message remove'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, petConfigPath, statePath, err := loadPet()
+		if err != nil {
+			return err
+		}
+
+		i := findSchedule(p.Config.Schedules, args[0])
+		if i < 0 {
+			return output.Errorf(output.CodeNotFound, "no schedule with id '%s'", args[0])
+		}
+		removed := p.Config.Schedules[i]
+		if err := storage.RemoveSchedule(petConfigPath, removed.ID); err != nil {
+			return err
+		}
+
+		delete(p.State.ScheduleRuns, removed.ID)
+		if err := savePet(p, statePath); err != nil {
+			return err
+		}
+
+		say("Schedule removed [%s]: %s", removed.ID, removed.Message)
+		return emitAction(&output.Action{
+			Action:    "schedule-remove",
+			Familiar:  output.DisplayName(p),
+			Schedules: []output.Schedule{output.NewSchedule(removed, time.Now())},
+		})
+	},
+}
+
+func init() {
+	scheduleAddCmd.Flags().String("id", "", "Schedule id (default: random)")
+	scheduleAddCmd.Flags().String("cron", "", "Cron rule: minute hour day-of-month month day-of-week")
+	scheduleAddCmd.Flags().String("every", "", "Simple rule, e.g. \"monday 09:00\" or \"weekday 17:30\"")
+	scheduleAddCmd.Flags().String("tz", "", "IANA time zone for the rule (default: local time)")
+	scheduleAddCmd.Flags().String("severity", "info", "Message severity: info, warn or critical")
+	scheduleAddCmd.Flags().String("expires", "", "Message lifetime (default: until the next occurrence)")
+	scheduleAddCmd.Flags().String("until", "", "Last day the schedule fires (YYYY-MM-DD)")
+	scheduleCmd.AddCommand(scheduleListCmd)
+	scheduleCmd.AddCommand(scheduleAddCmd)
+	scheduleCmd.AddCommand(scheduleRemoveCmd)
+}
+
+// findSchedule returns the index of the schedule with the given id, or -1
+func findSchedule(schedules []pet.ScheduleConfig, id string) int {
+	for i, s := range schedules {
+		if s.ID == id {
+			return i
+		}
+	}
+	return -1
+}
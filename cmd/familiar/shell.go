@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/duration"
+	"github.com/sethgrid/familiar/internal/output"
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/prompt"
+	"github.com/sethgrid/familiar/internal/shell"
+	"github.com/sethgrid/familiar/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// slowPrompt is the uncached prompt time above which doctor suggests --async
+const slowPrompt = 50 * time.Millisecond
+
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Integrate familiar with your shell",
+}
+
+var shellInitCmd = &cobra.Command{
+	Use:   "init <bash|zsh|fish>",
+	Short: "Print the shell integration to load from your startup file",
+	Long: `Print a snippet that integrates familiar with your shell:
+
+  prompt      show the familiar's segment at the start of the prompt
+  visits      record a visit when you change directory (at most once per --visit-throttle)
+  async       refresh the segment in the background, so a slow disk never
+              stalls the prompt; the prompt shows the previous refresh (off by default)
+  completion  load completions for familiar
+
+Load it from your startup file:
+
+  bash  ~/.bashrc                    eval "$(familiar shell init bash)"
+  zsh   ~/.zshrc                     eval "$(familiar shell init zsh)"
+  fish  ~/.config/fish/config.fish   familiar shell init fish | source
+
+Then open a new shell and run 'familiar shell doctor'.`,
+	ValidArgs: shell.Names,
+	Args:      cobra.ExactValidArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := shell.DefaultOptions()
+		opts.Prompt, _ = cmd.Flags().GetBool("prompt")
+		opts.Visits, _ = cmd.Flags().GetBool("visits")
+		opts.Async, _ = cmd.Flags().GetBool("async")
+		opts.Completion, _ = cmd.Flags().GetBool("completion")
+		opts.VisitThrottle, _ = cmd.Flags().GetString("visit-throttle")
+		if _, err := duration.Parse(opts.VisitThrottle); err != nil {
+			return fmt.Errorf("invalid --visit-throttle: %w", err)
+		}
+
+		snippet, err := shell.Init(args[0], opts)
+		if err != nil {
+			return err
+		}
+		fmt.Print(snippet)
+		return nil
+	},
+}
+
+var shellDoctorCmd = &cobra.Command{
+	Use:          "doctor",
+	Short:        "Check that the shell integration is active in this shell",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		doc := output.Doctor{Checks: []output.Check{}}
+		report := func(ok bool, check, detail string) {
+			doc.Checks = append(doc.Checks, output.Check{Name: check, OK: ok, Detail: detail})
+			mark := "ok"
+			if !ok {
+				mark = "!!"
+				doc.Problems++
+			}
+			if !structured() {
+				fmt.Printf("  %s  %-12s %s\n", mark, check, detail)
+			}
+		}
+
+		in, loaded := shell.Detect(os.Getenv, os.Getppid())
+		name := in.Shell
+		if !loaded {
+			name = filepath.Base(os.Getenv("SHELL"))
+		}
+		doc.Shell = name
+
+		if loaded {
+			report(true, "integration", fmt.Sprintf("loaded in %s (%s)", in.Shell, strings.Join(in.Features, ", ")))
+			if in.Version != shell.Version {
+				report(false, "version", fmt.Sprintf("this shell loaded version %d, familiar generates %d. Open a new shell", in.Version, shell.Version))
+			}
+		} else {
+			report(false, "integration", fmt.Sprintf("not loaded in this shell. Add to your startup file: %s", shell.LoadLine(name)))
+		}
+
+		if home, err := os.UserHomeDir(); err == nil {
+			if path, ok := shell.Persisted(name, home); ok {
+				report(true, "startup", path)
+			} else {
+				report(false, "startup", fmt.Sprintf("no startup file loads it, so new shells will not. Add: %s", shell.LoadLine(name)))
+			}
+		}
+
+		if path, err := exec.LookPath("familiar"); err != nil {
+			report(false, "path", "familiar is not on $PATH; the integration calls it by name")
+		} else {
+			detail := path
+			if self, err := os.Executable(); err == nil && !sameFile(self, path) {
+				detail += fmt.Sprintf(" (this is %s)", self)
+			}
+			report(true, "path", detail)
+		}
+
+		statePath, err := findStatePath()
+		if err != nil {
+			report(true, "familiar", "none here; the prompt stays empty")
+		} else {
+			report(true, "familiar", filepath.Dir(statePath))
+			uncached, err := timeUncachedPrompt()
+			switch {
+			case err != nil:
+				report(false, "prompt", err.Error())
+			case uncached > slowPrompt && !in.Has(shell.FeatureAsync):
+				report(false, "prompt", fmt.Sprintf("%s uncached; consider 'familiar shell init %s --async'", roundDuration(uncached), name))
+			default:
+				detail := fmt.Sprintf("%s uncached", roundDuration(uncached))
+				if c, err := storage.LoadPromptCache(filepath.Dir(statePath)); err == nil && c.Fresh(time.Now()) {
+					detail += ", cached until " + c.ValidUntil.Local().Format("15:04")
+				}
+				report(true, "prompt", detail)
+			}
+		}
+
+		if in.Has(shell.FeatureAsync) {
+			dir := os.Getenv("XDG_RUNTIME_DIR")
+			if dir == "" {
+				dir = os.TempDir()
+			}
+			if f, err := os.CreateTemp(dir, "familiar-doctor-*"); err != nil {
+				report(false, "async", fmt.Sprintf("cannot write to %s: %v", dir, err))
+			} else {
+				f.Close()
+				os.Remove(f.Name())
+				report(true, "async", dir)
+			}
+		}
+
+		if err := emit(output.KindDoctor, doc); err != nil {
+			return err
+		}
+		if doc.Problems > 0 {
+			err := fmt.Errorf("%d problem(s) found", doc.Problems)
+			if structured() {
+				return reportedError{err}
+			}
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	shellInitCmd.Flags().Bool("prompt", true, "Show the familiar's segment in the prompt")
+	shellInitCmd.Flags().Bool("visits", true, "Record a visit when changing directory")
+	shellInitCmd.Flags().Bool("async", false, "Refresh the prompt segment in the background")
+	shellInitCmd.Flags().Bool("completion", true, "Load completions")
+	shellInitCmd.Flags().String("visit-throttle", "1h", "Record at most one visit per familiar in this interval")
+	shellCmd.AddCommand(shellInitCmd)
+	shellCmd.AddCommand(shellDoctorCmd)
+}
+
+// timeUncachedPrompt measures computing the prompt segment without the cache
+func timeUncachedPrompt() (time.Duration, error) {
+	start := time.Now()
+	p, _, _, err := loadPet()
+	if err != nil {
+		return 0, err
+	}
+	if err := pet.ApplyTimeStep(p, start); err != nil {
+		return 0, fmt.Errorf("failed to apply time step: %w", err)
+	}
+	prompt.Build(p, start)
+	return time.Since(start), nil
+}
+
+// sameFile reports whether two paths name the same file, following symlinks
+func sameFile(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
+
+// roundDuration rounds to a readable precision, e.g. 4.2ms
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d > time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d > time.Millisecond:
+		return d.Round(100 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/duration"
+	"github.com/sethgrid/familiar/internal/git"
+	"github.com/sethgrid/familiar/internal/output"
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/storage"
+	"github.com/sethgrid/familiar/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Queue messages when watched files change",
+	Long: `Watches map file patterns to messages. Declare them in pet.toml:
+
+  [[watches]]
+  paths = ["config/*.yaml", "**/.env.example"]
+  message = "New local config defaults available: {{FILES}}"
+  severity = "warn"
+  expires = "7d"
+
+Patterns are relative to the directory containing .familiar and support "**".
+A pattern without a slash matches the file name in any directory. Messages
+may use {{FILES}}, {{COUNT}}, {{FROM}} and {{TO}}.`,
+}
+
+var watchCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Queue messages for watched files changed since the last check (for git hooks)",
+	Long: `Diff the last commit this user's familiar processed against HEAD and queue
+the message of every watch with a matching changed file.
+
+The last processed commit is remembered per user outside the repository. The
+first run only records HEAD. Intended to run from post-merge and
+post-checkout hooks.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		quiet, _ := cmd.Flags().GetBool("quiet")
+		from, _ := cmd.Flags().GetString("from")
+		a := &output.Action{Action: "watch-check"}
+		if err := runWatchCheck(from, quiet, a); err != nil {
+			return err
+		}
+		return emitAction(a)
+	},
+}
+
+// runWatchCheck queues watch messages for files changed between from (or the
+// last commit this user processed) and HEAD, adding them to a when it is not nil
+func runWatchCheck(from string, quiet bool, a *output.Action) error {
+	p, _, statePath, err := loadPet()
+	if err != nil {
+		return err
+	}
+	if a != nil {
+		a.Familiar = output.DisplayName(p)
+	}
+	for _, w := range p.Config.Watches {
+		if err := w.Validate(); err != nil {
+			return err
+		}
+	}
+	petDir := filepath.Dir(statePath)
+	projectDir := filepath.Dir(petDir)
+
+	local, err := storage.LoadLocalState(petDir)
+	if err != nil {
+		return err
+	}
+
+	head, err := git.RevParse(projectDir, "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	if from == "" {
+		from = local.LastSeenCommit
+	}
+	// Nothing to compare against yet (first run, or history was rewritten)
+	if from == "" || !git.HasCommit(projectDir, from) {
+		local.LastSeenCommit = head
+		if !quiet {
+			say("Watching for changes from %s", head[:7])
+		}
+		return storage.SaveLocalState(petDir, local)
+	}
+	if from == head {
+		return nil
+	}
+
+	changed, err := git.ChangedFiles(projectDir, from, head)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := pet.ApplyTimeStep(p, now); err != nil {
+		return fmt.Errorf("failed to apply time step: %w", err)
+	}
+
+	queued := 0
+	for _, hit := range watch.Evaluate(p.Config.Watches, changed) {
+		text := watch.RenderMessage(hit, from, head)
+		if hasActiveMessage(p, text, now) {
+			// Another contributor's pull already queued it
+			continue
+		}
+
+		var expiresAt time.Time
+		if hit.Watch.Expires != "" {
+			d, _ := duration.Parse(hit.Watch.Expires)
+			expiresAt = now.Add(d)
+		}
+
+		m := p.State.AddMessage(pet.Message{
+			Text:      text,
+			Severity:  watchSeverity(hit.Watch),
+			Author:    "familiar watch",
+			CreatedAt: now,
+			ExpiresAt: expiresAt,
+		})
+		queued++
+		if a != nil {
+			a.Messages = append(a.Messages, output.NewMessage(p, m))
+		}
+		if !quiet {
+			say("Message queued [%s]: %s", m.ID, m.Text)
+		}
+	}
+
+	if queued > 0 {
+		if err := savePet(p, statePath); err != nil {
+			return err
+		}
+	}
+
+	local.LastSeenCommit = head
+	return storage.SaveLocalState(petDir, local)
+}
+
+var watchListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured watches",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, _, _, err := loadPet()
+		if err != nil {
+			return err
+		}
+		if structured() {
+			doc := output.Watches{Watches: []output.Watch{}}
+			for _, w := range p.Config.Watches {
+				doc.Watches = append(doc.Watches, output.Watch{Paths: w.Paths, Message: w.Message, Severity: string(watchSeverity(w)), Expires: w.Expires})
+			}
+			return emit(output.KindWatches, doc)
+		}
+		if len(p.Config.Watches) == 0 {
+			fmt.Println("No watches configured")
+			return nil
+		}
+		for _, w := range p.Config.Watches {
+			severity := watchSeverity(w)
+			fmt.Printf("%-8s %s\n         %s\n", severity, strings.Join(w.Paths, ", "), w.Message)
+			if err := w.Validate(); err != nil {
+				fmt.Printf("         invalid: %v\n", err)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	watchCheckCmd.Flags().BoolP("quiet", "q", false, "Print nothing (for git hooks)")
+	watchCheckCmd.Flags().String("from", "", "Compare from this commit instead of the last one seen")
+	watchCmd.AddCommand(watchCheckCmd)
+	watchCmd.AddCommand(watchListCmd)
+}
+
+// watchSeverity is the severity of a watch's messages, info by default. An
+// unknown severity is shown as written (see WatchConfig.Validate).
+func watchSeverity(w pet.WatchConfig) pet.Severity {
+	severity, err := pet.ParseSeverity(string(w.Severity))
+	if err != nil {
+		return w.Severity
+	}
+	return severity
+}
+
+// hasActiveMessage reports whether an unexpired message with the same text is queued
+func hasActiveMessage(p *pet.Pet, text string, now time.Time) bool {
+	for _, m := range p.State.ActiveMessages(now) {
+		if m.Text == text {
+			return true
+		}
+	}
+	return false
+}
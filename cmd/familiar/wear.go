@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/output"
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/spf13/cobra"
+)
+
+var wearCmd = &cobra.Command{
+	Use:   "wear [item]",
+	Short: "Dress your familiar in an accessory",
+	Long: `Put an accessory on your familiar, or take it off with --off. Accessories are
+drawn over pixel art, and are unlocked by evolving or earning achievements.
+With no item, list the accessories and achievements.
+
+Examples:
+  familiar wear
+  familiar wear party-hat
+  familiar wear --off party-hat
+  familiar wear --off`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		off, _ := cmd.Flags().GetBool("off")
+		if len(args) == 0 && !off {
+			return listWardrobe()
+		}
+
+		return runAction("wear", func(p *pet.Pet, a *output.Action) error {
+			if len(args) == 0 {
+				p.State.Wearing = nil
+				say("Took off all accessories")
+				return nil
+			}
+
+			name := args[0]
+			accessory, ok := p.Config.Accessories[name]
+			if !ok {
+				return output.Errorf(output.CodeNotFound, "no accessory '%s'. Use 'familiar wear' to see them", name)
+			}
+			if off {
+				if !p.Wears(name) {
+					return output.Errorf(output.CodeState, "your familiar is not wearing %s", name)
+				}
+				p.State.Wearing = slices.DeleteFunc(p.State.Wearing, func(w string) bool { return w == name })
+				say("Took off %s", name)
+				return nil
+			}
+			if !p.AccessoryUnlocked(name) {
+				return output.Errorf(output.CodeState, "%s is locked: it is unlocked by %s", name, accessory.Unlock())
+			}
+			if p.Wears(name) {
+				say("Your familiar is already wearing %s", name)
+				return nil
+			}
+			p.State.Wearing = append(p.State.Wearing, name)
+			say("Your familiar is wearing %s", name)
+			return nil
+		})
+	},
+}
+
+func init() {
+	wearCmd.Flags().Bool("off", false, "Take the item off, or everything with no item")
+}
+
+// listWardrobe prints the accessories and the achievements earned
+func listWardrobe() error {
+	var doc output.Wardrobe
+	err := executeStatefulCommand(func(p *pet.Pet) error {
+		names := make([]string, 0, len(p.Config.Accessories))
+		for name := range p.Config.Accessories {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		doc.Accessories = []output.Accessory{}
+		for _, name := range names {
+			a := p.Config.Accessories[name]
+			doc.Accessories = append(doc.Accessories, output.Accessory{
+				Name:        name,
+				Description: a.Description,
+				Unlocked:    p.AccessoryUnlocked(name),
+				Wearing:     p.Wears(name),
+				Unlock:      a.Unlock(),
+			})
+		}
+		doc.Achievements = []output.Achievement{}
+		for _, achievement := range pet.Achievements {
+			if earned, ok := p.State.Achievements[achievement.ID]; ok {
+				doc.Achievements = append(doc.Achievements, output.Achievement{
+					ID:          achievement.ID,
+					Description: achievement.Description,
+					Earned:      earned,
+				})
+			}
+		}
+		if structured() {
+			return nil
+		}
+
+		if len(doc.Accessories) == 0 {
+			fmt.Println("Your familiar's art has no accessories")
+		}
+		for _, a := range doc.Accessories {
+			mark := "  "
+			switch {
+			case a.Wearing:
+				mark = "* "
+			case !a.Unlocked:
+				mark = "🔒"
+			}
+			line := fmt.Sprintf("%s %-16s %s", mark, a.Name, a.Description)
+			if !a.Unlocked {
+				line += fmt.Sprintf(" (unlocked by %s)", a.Unlock)
+			}
+			fmt.Println(line)
+		}
+		if len(doc.Achievements) > 0 {
+			fmt.Println("\nAchievements:")
+			for _, a := range doc.Achievements {
+				fmt.Printf("  %-16s %s, %s\n", a.ID, a.Description, a.Earned.Format(time.DateOnly))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return emit(output.KindWardrobe, doc)
+}
@@ -5,6 +5,8 @@ go 1.25.0
 require (
 	github.com/pelletier/go-toml/v2 v2.2.4
 	github.com/spf13/cobra v1.10.2
+	golang.org/x/image v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -2,7 +2,11 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,7 +18,9 @@ import (
 	"github.com/sethgrid/familiar/internal/discovery"
 	"github.com/sethgrid/familiar/internal/health"
 	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/prompt"
 	"github.com/sethgrid/familiar/internal/storage"
+	"github.com/sethgrid/familiar/internal/term"
 )
 
 func TestNonAnimatedFamiliar(t *testing.T) {
@@ -231,7 +237,7 @@ func TestFamiliarStates(t *testing.T) {
 	}
 
 	// Test has-message state
-	p.State.Message = "Test message"
+	p.State.AddMessage(pet.Message{Text: "Test message", CreatedAt: now})
 	healthVal = health.ComputeHealth(p.State.Hunger, p.State.Happiness, p.State.Energy, health.ComputationMode(p.Config.HealthComputation))
 	status = conditions.DeriveStatus(p, now, healthVal)
 }
@@ -748,7 +754,7 @@ func TestReadmeExample(t *testing.T) {
 	// Set evolution to 1 so it shows cat art (not egg)
 	p.State.Evolution = 1
 	// Set a message
-	p.State.Message = "Attn Devs — new local config defaults available."
+	msg := p.State.AddMessage(pet.Message{Text: "Attn Devs — new local config defaults available.", CreatedAt: time.Now()})
 	// Ensure good stats so it's not in a bad state
 	p.State.Hunger = 10 // Low hunger is good (0-30 is good range)
 	p.State.Happiness = 80
@@ -784,8 +790,8 @@ func TestReadmeExample(t *testing.T) {
 	}
 
 	// Verify message
-	if p.State.Message != "Attn Devs — new local config defaults available." {
-		t.Errorf("Expected message 'Attn Devs — new local config defaults available.', got '%s'", p.State.Message)
+	if len(p.State.Messages) != 1 || p.State.Messages[0].Text != "Attn Devs — new local config defaults available." {
+		t.Errorf("Expected message 'Attn Devs — new local config defaults available.', got %+v", p.State.Messages)
 	}
 
 	// Verify art for has-message (should have asterisk)
@@ -802,8 +808,8 @@ func TestReadmeExample(t *testing.T) {
 
 	// Now test acknowledge
 	// Simulate acknowledge: if message existed, boost to 100, else boost by 5
-	hadMessage := p.State.Message != ""
-	p.State.Message = ""
+	_, err = p.State.RemoveMessage(msg.ID)
+	hadMessage := err == nil
 	if hadMessage {
 		p.State.Hunger = 0 // 0 = not hungry (best)
 		p.State.Happiness = 100
@@ -830,8 +836,8 @@ func TestReadmeExample(t *testing.T) {
 	status = conditions.DeriveStatus(p, now, healthVal)
 
 	// Verify message is cleared
-	if p.State.Message != "" {
-		t.Errorf("Expected message to be cleared after acknowledge, got '%s'", p.State.Message)
+	if len(p.State.Messages) != 0 {
+		t.Errorf("Expected message to be cleared after acknowledge, got %+v", p.State.Messages)
 	}
 
 	// Verify happy condition (or at least not has-message)
@@ -1064,6 +1070,56 @@ func TestAdminArtEvolutionWithType(t *testing.T) {
 	}
 }
 
+func TestBrailleRendersTemplates(t *testing.T) {
+	// Every pixel animation in lib/v1 renders as braille: 2x4 pixels per cell
+	libDir, err := storage.FindLibDir()
+	if err != nil {
+		t.Fatalf("Failed to find lib directory: %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(libDir, "*.toml"))
+	rendered := 0
+	for _, file := range files {
+		if strings.HasSuffix(file, ".state.toml") {
+			continue
+		}
+		petType := strings.TrimSuffix(filepath.Base(file), ".toml")
+		template, err := storage.LoadTemplateConfig(petType)
+		if err != nil {
+			t.Fatalf("Failed to load %s template: %v", petType, err)
+		}
+		for key, anim := range template.Config.Animations {
+			if !anim.Pixel() {
+				continue
+			}
+			for i, frame := range anim.Frames {
+				width := 0
+				for _, row := range frame.Pixels {
+					width = max(width, len(row))
+				}
+				for _, c := range []pet.BrailleConfig{{}, {Threshold: 128}, {Dither: true}} {
+					out := art.RenderBraille(frame, c, term.DepthTrue)
+					lines := strings.Split(out, "\n")
+					if len(lines) != (len(frame.Pixels)+3)/4 {
+						t.Errorf("%s %s frame %d: %d lines for %d pixel rows", petType, key, i, len(lines), len(frame.Pixels))
+					}
+					for _, line := range lines {
+						if w := term.VisibleWidth(line); w != (width+1)/2 {
+							t.Errorf("%s %s frame %d: line %d columns wide, want %d", petType, key, i, w, (width+1)/2)
+						}
+					}
+				}
+				if strings.TrimSpace(art.RenderBraille(frame, pet.BrailleConfig{}, term.DepthNone)) == "" {
+					t.Errorf("%s %s frame %d: expected dots", petType, key, i)
+				}
+				rendered++
+			}
+		}
+	}
+	if rendered == 0 {
+		t.Error("Expected pixel templates in lib/v1")
+	}
+}
+
 func TestAdminArtTypeFlagWithDifferentStates(t *testing.T) {
 	// Test that --type flag works with different states
 	pixelTemplate, err := storage.LoadTemplateConfig("pixel")
@@ -1186,6 +1242,184 @@ func TestAdminArtTypeFlagWithDancerTemplate(t *testing.T) {
 	}
 }
 
+func TestMessageQueue(t *testing.T) {
+	tmpDir := t.TempDir()
+	petDir := filepath.Join(tmpDir, ".familiar")
+
+	if err := storage.InitPet(false, "cat", "QueueCat", tmpDir); err != nil {
+		t.Fatalf("Failed to initialize pet: %v", err)
+	}
+
+	configPath := filepath.Join(petDir, "pet.toml")
+	statePath := filepath.Join(petDir, "pet.state.toml")
+	p, err := storage.LoadPet(configPath, statePath)
+	if err != nil {
+		t.Fatalf("Failed to load pet: %v", err)
+	}
+
+	now := time.Now()
+	info := p.State.AddMessage(pet.Message{Text: "standup moved", CreatedAt: now})
+	p.State.AddMessage(pet.Message{Text: "deploy freeze", Severity: pet.SeverityCritical, CreatedAt: now})
+	p.State.AddMessage(pet.Message{Text: "old news", Severity: pet.SeverityWarn, CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+
+	if info.ID == "" || info.Severity != pet.SeverityInfo {
+		t.Errorf("Expected id and default info severity, got %+v", info)
+	}
+
+	active := p.State.ActiveMessages(now)
+	if len(active) != 3 {
+		t.Fatalf("Expected 3 active messages, got %d", len(active))
+	}
+	if got := pet.HighestSeverity(active); got != pet.SeverityCritical {
+		t.Errorf("Expected highest severity critical, got %s", got)
+	}
+
+	// Expired messages drop out of the active set and are pruned by decay
+	later := now.Add(2 * time.Hour)
+	if got := len(p.State.ActiveMessages(later)); got != 2 {
+		t.Errorf("Expected 2 active messages after expiry, got %d", got)
+	}
+	if err := pet.ApplyTimeStep(p, later); err != nil {
+		t.Fatalf("Failed to apply time step: %v", err)
+	}
+	if len(p.State.Messages) != 2 {
+		t.Errorf("Expected expired message to be pruned, got %d messages", len(p.State.Messages))
+	}
+
+	// Acknowledge by unique id prefix
+	if _, err := p.State.RemoveMessage(info.ID[:4]); err != nil {
+		t.Fatalf("Failed to remove message by prefix: %v", err)
+	}
+	if len(p.State.Messages) != 1 || p.State.Messages[0].Text != "deploy freeze" {
+		t.Errorf("Unexpected queue after removal: %+v", p.State.Messages)
+	}
+	if _, err := p.State.RemoveMessage("zzzzzz"); err == nil {
+		t.Error("Expected error removing unknown message id")
+	}
+
+	if err := storage.SavePetState(p, statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	p, err = storage.LoadPet(configPath, statePath)
+	if err != nil {
+		t.Fatalf("Failed to reload pet: %v", err)
+	}
+	if len(p.State.Messages) != 1 || p.State.Messages[0].Severity != pet.SeverityCritical {
+		t.Errorf("Message queue not persisted correctly: %+v", p.State.Messages)
+	}
+}
+
+func TestLegacyMessageMigration(t *testing.T) {
+	tmpDir := t.TempDir()
+	petDir := filepath.Join(tmpDir, ".familiar")
+
+	if err := storage.InitPet(false, "cat", "LegacyCat", tmpDir); err != nil {
+		t.Fatalf("Failed to initialize pet: %v", err)
+	}
+
+	// Rewrite the state file the way versions before the queue stored messages
+	statePath := filepath.Join(petDir, "pet.state.toml")
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatalf("Failed to read state: %v", err)
+	}
+	legacy := strings.Replace(string(data), "messages = []", "message = 'ship is red'", 1)
+	if err := os.WriteFile(statePath, []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write state: %v", err)
+	}
+
+	p, err := storage.LoadPet(filepath.Join(petDir, "pet.toml"), statePath)
+	if err != nil {
+		t.Fatalf("Failed to load pet: %v", err)
+	}
+	if len(p.State.Messages) != 1 || p.State.Messages[0].Text != "ship is red" {
+		t.Fatalf("Expected legacy message to be migrated, got %+v", p.State.Messages)
+	}
+	if p.State.LegacyMessage != "" {
+		t.Errorf("Expected legacy field to be cleared, got %q", p.State.LegacyMessage)
+	}
+}
+
+func TestPerUserAcknowledgement(t *testing.T) {
+	for _, store := range []pet.AckStore{pet.AckStoreState, pet.AckStoreUser} {
+		t.Run(string(store), func(t *testing.T) {
+			tmpDir := t.TempDir()
+			petDir := filepath.Join(tmpDir, ".familiar")
+
+			if err := storage.InitPet(false, "cat", "SharedCat", tmpDir); err != nil {
+				t.Fatalf("Failed to initialize pet: %v", err)
+			}
+			configPath := filepath.Join(petDir, "pet.toml")
+			statePath := filepath.Join(petDir, "pet.state.toml")
+
+			load := func(user string) *pet.Pet {
+				p, err := storage.LoadPet(configPath, statePath)
+				if err != nil {
+					t.Fatalf("Failed to load pet: %v", err)
+				}
+				p.Config.AckStore = store
+				p.User = user
+				p.UserAcks, err = storage.LoadUserAcks(petDir, user)
+				if err != nil {
+					t.Fatalf("Failed to load acks: %v", err)
+				}
+				return p
+			}
+			save := func(p *pet.Pet) {
+				if err := storage.SavePetState(p, statePath); err != nil {
+					t.Fatalf("Failed to save state: %v", err)
+				}
+				keep := map[string]bool{}
+				for _, m := range p.State.Messages {
+					keep[m.ID] = true
+				}
+				if err := storage.SaveUserAcks(petDir, p.User, p.UserAcks, keep); err != nil {
+					t.Fatalf("Failed to save acks: %v", err)
+				}
+			}
+
+			now := time.Now()
+			alice := load("alice@example.com")
+			msg := alice.State.AddMessage(pet.Message{Text: "new config defaults", CreatedAt: now})
+			save(alice)
+
+			// Alice acknowledges; Bob should still see the message
+			alice = load("alice@example.com")
+			if _, err := alice.AcknowledgeMessage(msg.ID, now); err != nil {
+				t.Fatalf("Failed to acknowledge: %v", err)
+			}
+			save(alice)
+
+			alice = load("alice@example.com")
+			if got := len(alice.PendingMessages(now)); got != 0 {
+				t.Errorf("Expected alice to have no pending messages, got %d", got)
+			}
+			bob := load("bob@example.com")
+			if got := len(bob.PendingMessages(now)); got != 1 {
+				t.Errorf("Expected bob to still have 1 pending message, got %d", got)
+			}
+
+			healthVal := health.ComputeHealth(bob.State.Hunger, bob.State.Happiness, bob.State.Energy, health.ComputationMode(bob.Config.HealthComputation))
+			if !conditions.DeriveStatus(bob, now, healthVal).Conditions[conditions.CondHasMessage] {
+				t.Error("Expected has-message condition for bob")
+			}
+
+			all, err := storage.LoadAllUserAcks(petDir)
+			if err != nil {
+				t.Fatalf("Failed to load all acks: %v", err)
+			}
+			_, inFile := all["alice@example.com"][msg.ID]
+			_, inState := bob.State.Messages[0].AckedAt("alice@example.com")
+			if store == pet.AckStoreUser && (!inFile || inState) {
+				t.Errorf("Expected acknowledgement only in the per-user file (file=%v state=%v)", inFile, inState)
+			}
+			if store == pet.AckStoreState && (inFile || !inState) {
+				t.Errorf("Expected acknowledgement only in the shared state (file=%v state=%v)", inFile, inState)
+			}
+		})
+	}
+}
+
 func getAnimationKeys(anims map[string]pet.AnimationConfig) []string {
 	keys := make([]string, 0, len(anims))
 	for k := range anims {
@@ -1200,3 +1434,793 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+func TestScheduledMessages(t *testing.T) {
+	tmpDir := t.TempDir()
+	petDir := filepath.Join(tmpDir, ".familiar")
+
+	if err := storage.InitPet(false, "cat", "CronCat", tmpDir); err != nil {
+		t.Fatalf("Failed to initialize pet: %v", err)
+	}
+
+	configPath := filepath.Join(petDir, "pet.toml")
+	statePath := filepath.Join(petDir, "pet.state.toml")
+	p, err := storage.LoadPet(configPath, statePath)
+	if err != nil {
+		t.Fatalf("Failed to load pet: %v", err)
+	}
+
+	p.Config.Schedules = []pet.ScheduleConfig{
+		{ID: "oncall", Message: "rotate on-call", Every: "monday 09:00", Timezone: "UTC", Severity: pet.SeverityWarn},
+		{ID: "ended", Message: "old reminder", Cron: "0 9 * * *", Timezone: "UTC", Until: "2026-01-01"},
+	}
+	if err := storage.SavePetConfig(p, configPath); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	if p, err = storage.LoadPet(configPath, statePath); err != nil {
+		t.Fatalf("Failed to reload pet: %v", err)
+	}
+	if len(p.Config.Schedules) != 2 {
+		t.Fatalf("Expected 2 schedules after reload, got %d", len(p.Config.Schedules))
+	}
+
+	// Sunday: the first evaluation only records a baseline
+	sunday := time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)
+	if err := pet.ApplyTimeStep(p, sunday); err != nil {
+		t.Fatalf("Failed to apply time step: %v", err)
+	}
+	if len(p.State.Messages) != 0 {
+		t.Fatalf("Expected no messages on first evaluation, got %+v", p.State.Messages)
+	}
+
+	// Monday after 09:00: the occurrence is due
+	monday := time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)
+	if err := pet.ApplyTimeStep(p, monday); err != nil {
+		t.Fatalf("Failed to apply time step: %v", err)
+	}
+	active := p.State.ActiveMessages(monday)
+	if len(active) != 1 || active[0].Text != "rotate on-call" || active[0].Severity != pet.SeverityWarn {
+		t.Fatalf("Expected the on-call message, got %+v", active)
+	}
+	if want := time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC); !active[0].ExpiresAt.Equal(want) {
+		t.Errorf("Expected expiry at next occurrence %v, got %v", want, active[0].ExpiresAt)
+	}
+
+	// The same occurrence is not queued twice
+	if err := pet.ApplyTimeStep(p, monday.Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to apply time step: %v", err)
+	}
+	if got := len(p.State.ActiveMessages(monday)); got != 1 {
+		t.Errorf("Expected 1 message after re-evaluation, got %d", got)
+	}
+
+	// State survives a save and reload
+	if err := storage.SavePetState(p, statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	reloaded, err := storage.LoadPet(configPath, statePath)
+	if err != nil {
+		t.Fatalf("Failed to reload pet: %v", err)
+	}
+	if !reloaded.State.ScheduleRuns["oncall"].Equal(monday.Add(time.Hour)) {
+		t.Errorf("Expected schedule run to persist, got %v", reloaded.State.ScheduleRuns)
+	}
+}
+
+func TestPersonalReminders(t *testing.T) {
+	tmpDir := t.TempDir()
+	petDir := filepath.Join(tmpDir, ".familiar")
+	stateHome := filepath.Join(tmpDir, "state")
+	t.Setenv("XDG_STATE_HOME", stateHome)
+
+	if err := storage.InitPet(false, "cat", "RemindCat", tmpDir); err != nil {
+		t.Fatalf("Failed to initialize pet: %v", err)
+	}
+
+	now := time.Now()
+	local, err := storage.LoadLocalState(petDir)
+	if err != nil {
+		t.Fatalf("Failed to load local state: %v", err)
+	}
+	soon := local.AddReminder(pet.Reminder{Text: "check the deploy", Due: now.Add(-time.Minute), CreatedAt: now})
+	local.AddReminder(pet.Reminder{Text: "later", Due: now.Add(2 * time.Hour), CreatedAt: now})
+	if err := storage.SaveLocalState(petDir, local); err != nil {
+		t.Fatalf("Failed to save local state: %v", err)
+	}
+
+	// Reminders never touch the shared .familiar directory
+	entries, err := os.ReadDir(petDir)
+	if err != nil {
+		t.Fatalf("Failed to read familiar directory: %v", err)
+	}
+	for _, e := range entries {
+		if e.Name() != "pet.toml" && e.Name() != "pet.state.toml" {
+			t.Errorf("Unexpected file in .familiar: %s", e.Name())
+		}
+	}
+
+	reloaded, err := storage.LoadLocalState(petDir)
+	if err != nil {
+		t.Fatalf("Failed to reload local state: %v", err)
+	}
+	due := reloaded.DueReminders(now)
+	if len(due) != 1 || due[0].ID != soon.ID {
+		t.Fatalf("Expected only %q to be due, got %+v", soon.Text, due)
+	}
+
+	// Snoozing moves it out of the due set; dismissing removes it
+	r, err := reloaded.FindReminder(soon.ID[:4])
+	if err != nil {
+		t.Fatalf("Failed to find reminder by prefix: %v", err)
+	}
+	r.Due = now.Add(time.Hour)
+	if got := len(reloaded.DueReminders(now)); got != 0 {
+		t.Errorf("Expected no due reminders after snooze, got %d", got)
+	}
+	if _, err := reloaded.RemoveReminder(soon.ID); err != nil {
+		t.Fatalf("Failed to dismiss reminder: %v", err)
+	}
+	if len(reloaded.Reminders) != 1 {
+		t.Errorf("Expected 1 reminder after dismiss, got %d", len(reloaded.Reminders))
+	}
+}
+
+// cachePixelPrompt sets up a pixel familiar, the largest template, with a cached prompt segment
+func cachePixelPrompt(tb testing.TB) (petDir, statePath string) {
+	tb.Helper()
+	tmpDir := tb.TempDir()
+	tb.Setenv("XDG_STATE_HOME", filepath.Join(tmpDir, "state"))
+	if err := storage.InitPet(false, "pixel", "PromptPixel", tmpDir); err != nil {
+		tb.Fatalf("Failed to initialize pet: %v", err)
+	}
+	petDir = filepath.Join(tmpDir, ".familiar")
+	statePath = filepath.Join(petDir, "pet.state.toml")
+	configPath := discovery.GetConfigPathFromState(statePath)
+
+	inputs, err := storage.PromptInputs(petDir, configPath, statePath)
+	if err != nil {
+		tb.Fatalf("Failed to list prompt inputs: %v", err)
+	}
+	stamps := storage.StampFiles(inputs...)
+	p, err := storage.LoadPet(configPath, statePath)
+	if err != nil {
+		tb.Fatalf("Failed to load pet: %v", err)
+	}
+	now := time.Now()
+	if err := pet.ApplyTimeStep(p, now); err != nil {
+		tb.Fatalf("Failed to apply time step: %v", err)
+	}
+	err = storage.SavePromptCache(petDir, &storage.PromptCache{
+		Segment:    prompt.Build(p, now),
+		ComputedAt: now,
+		ValidUntil: now.Add(time.Hour),
+		Inputs:     stamps,
+	})
+	if err != nil {
+		tb.Fatalf("Failed to save prompt cache: %v", err)
+	}
+	return petDir, statePath
+}
+
+func TestPromptCache(t *testing.T) {
+	petDir, statePath := cachePixelPrompt(t)
+	now := time.Now()
+
+	c, err := storage.LoadPromptCache(petDir)
+	if err != nil {
+		t.Fatalf("Failed to load prompt cache: %v", err)
+	}
+	if !c.Fresh(now) {
+		t.Fatal("Expected a fresh cache")
+	}
+	if c.Segment.Name != "PromptPixel" || c.Segment.Glyph == "" {
+		t.Errorf("Expected a cached segment, got %+v", c.Segment)
+	}
+	if c.Fresh(now.Add(2 * time.Hour)) {
+		t.Error("Expected the cache to expire after ValidUntil")
+	}
+
+	// Reading the cache never touches the familiar's files
+	entries, err := os.ReadDir(petDir)
+	if err != nil {
+		t.Fatalf("Failed to read familiar directory: %v", err)
+	}
+	for _, e := range entries {
+		if e.Name() != "pet.toml" && e.Name() != "pet.state.toml" {
+			t.Errorf("Unexpected file in .familiar: %s", e.Name())
+		}
+	}
+
+	// Any write to the state invalidates the cache
+	p, err := storage.LoadPet(discovery.GetConfigPathFromState(statePath), statePath)
+	if err != nil {
+		t.Fatalf("Failed to load pet: %v", err)
+	}
+	p.State.AddMessage(pet.Message{Text: "new defaults", Severity: pet.SeverityWarn, CreatedAt: now})
+	if err := storage.SavePetState(p, statePath); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	if c.Fresh(now) {
+		t.Error("Expected a changed state file to invalidate the cache")
+	}
+
+	// So does creating this user's local state
+	local, err := storage.LoadLocalState(petDir)
+	if err != nil {
+		t.Fatalf("Failed to load local state: %v", err)
+	}
+	local.AddReminder(pet.Reminder{Text: "r", Due: now, CreatedAt: now})
+	if err := storage.SaveLocalState(petDir, local); err != nil {
+		t.Fatalf("Failed to save local state: %v", err)
+	}
+	c.Inputs[0] = storage.StampFiles(statePath)[0] // accept the new state, so only the local state differs
+	if c.Fresh(now) {
+		t.Error("Expected new local state to invalidate the cache")
+	}
+}
+
+// BenchmarkPromptCached measures the prompt's fast path, a cache hit, which
+// should stay well under a millisecond
+func BenchmarkPromptCached(b *testing.B) {
+	petDir, _ := cachePixelPrompt(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c, err := storage.LoadPromptCache(petDir)
+		if err != nil || !c.Fresh(time.Now()) {
+			b.Fatalf("Expected a cache hit: %v", err)
+		}
+	}
+}
+
+func BenchmarkPromptRecompute(b *testing.B) {
+	_, statePath := cachePixelPrompt(b)
+	configPath := discovery.GetConfigPathFromState(statePath)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p, err := storage.LoadPet(configPath, statePath)
+		if err != nil {
+			b.Fatalf("Failed to load pet: %v", err)
+		}
+		now := time.Now()
+		if err := pet.ApplyTimeStep(p, now); err != nil {
+			b.Fatalf("Failed to apply time step: %v", err)
+		}
+		_ = prompt.Build(p, now).String()
+	}
+}
+
+func TestFileAnimationSource(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := storage.InitPet(false, "cat", "FileCat", tmpDir); err != nil {
+		t.Fatalf("Failed to initialize pet: %v", err)
+	}
+	petDir := filepath.Join(tmpDir, ".familiar")
+	statePath := filepath.Join(petDir, "pet.state.toml")
+	configPath := discovery.GetConfigPathFromState(statePath)
+
+	frames := " /\\_/\\\n( o.o )\n---\n /\\_/\\\n( -.- )\n"
+	if err := os.WriteFile(filepath.Join(petDir, "blink.txt"), []byte(frames), 0644); err != nil {
+		t.Fatalf("Failed to write frame file: %v", err)
+	}
+	set := "[[frames]]\npixels = [[\"#ff0000\", \"\"]]\nms = 100\n"
+	if err := os.WriteFile(filepath.Join(petDir, "dot.toml"), []byte(set), 0644); err != nil {
+		t.Fatalf("Failed to write frame set: %v", err)
+	}
+
+	p, err := storage.LoadPet(configPath, statePath)
+	if err != nil {
+		t.Fatalf("Failed to load pet: %v", err)
+	}
+
+	blink := pet.AnimationConfig{Source: "file", Path: "blink.txt", FPS: 2}
+	got, err := storage.LoadFrames(blink, petDir, 0)
+	if err != nil {
+		t.Fatalf("Failed to load frame file: %v", err)
+	}
+	if len(got) != 2 || got[1].Art != " /\\_/\\\n( -.- )\n" {
+		t.Errorf("Unexpected frames from text file: %q", got)
+	}
+
+	dot := pet.AnimationConfig{Source: "file", Path: "dot.toml"}
+	dot.Frames, err = storage.LoadFrames(dot, petDir, 0)
+	if err != nil {
+		t.Fatalf("Failed to load frame set: %v", err)
+	}
+	if !dot.Pixel() || dot.Frames[0].MS != 100 {
+		t.Errorf("Expected a pixel frame set, got %+v", dot)
+	}
+
+	// A pinned checksum rejects an edited file
+	blink.SHA256 = strings.Repeat("0", 64)
+	if _, err := storage.LoadFrames(blink, petDir, 0); err == nil || !strings.Contains(err.Error(), "sha256") {
+		t.Errorf("Expected a checksum error, got %v", err)
+	}
+
+	// Loaded frames are never saved into pet.toml
+	p.Config.Animations["blink"] = pet.AnimationConfig{Source: "file", Path: "blink.txt"}
+	if err := storage.SavePetConfig(p, configPath); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	reloaded, err := storage.LoadPet(configPath, statePath)
+	if err != nil {
+		t.Fatalf("Failed to reload pet: %v", err)
+	}
+	if anim := reloaded.Config.Animations["blink"]; anim.Path != "blink.txt" || len(anim.Frames) != 0 {
+		t.Errorf("Expected a file animation without inline frames, got %+v", anim)
+	}
+}
+
+func TestURLAnimationSource(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", filepath.Join(t.TempDir(), "state"))
+
+	body := "(o.o)\n---\n(-.-)\n"
+	fetches := 0
+	up := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		fetches++
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+
+	sum := sha256.Sum256([]byte(body))
+	anim := pet.AnimationConfig{Source: "url", URL: srv.URL + "/blink.txt", SHA256: hex.EncodeToString(sum[:])}
+
+	frames, err := storage.LoadFrames(anim, "", time.Hour)
+	if err != nil || len(frames) != 2 {
+		t.Fatalf("Expected 2 fetched frames, got %d: %v", len(frames), err)
+	}
+
+	// Within cacheTTL the cached copy is used
+	if _, err := storage.LoadFrames(anim, "", time.Hour); err != nil || fetches != 1 {
+		t.Errorf("Expected a cache hit, fetched %d times: %v", fetches, err)
+	}
+
+	// With no TTL it refetches, and falls back to the cache when offline
+	if _, err := storage.LoadFrames(anim, "", 0); err != nil || fetches != 2 {
+		t.Errorf("Expected a refetch, fetched %d times: %v", fetches, err)
+	}
+	up = false
+	if frames, err := storage.LoadFrames(anim, "", 0); err != nil || len(frames) != 2 {
+		t.Errorf("Expected the cached frames while offline, got %d: %v", len(frames), err)
+	}
+
+	// Content that does not match the pin is never used
+	up = true
+	body = "(x.x)\n"
+	anim.URL = srv.URL + "/other.txt"
+	if _, err := storage.LoadFrames(anim, "", 0); err == nil || !strings.Contains(err.Error(), "sha256") {
+		t.Errorf("Expected a checksum error, got %v", err)
+	}
+}
+
+func TestSetAnimation(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := storage.InitPet(false, "pixel", "ImportPixel", tmpDir); err != nil {
+		t.Fatalf("Failed to initialize pet: %v", err)
+	}
+	statePath := filepath.Join(tmpDir, ".familiar", "pet.state.toml")
+	configPath := discovery.GetConfigPathFromState(statePath)
+	before, err := storage.LoadPet(configPath, statePath)
+	if err != nil {
+		t.Fatalf("Failed to load pet: %v", err)
+	}
+
+	imported := pet.AnimationConfig{Source: "pixel", FPS: 2, Loops: -1, Frames: []pet.Frame{
+		{Pixels: [][]string{{"#FF0000", ""}}, MS: 250},
+		{Pixels: [][]string{{"", "#FF0000"}}, MS: 250},
+	}}
+	// Replace an evolution animation in place, and add one that does not exist yet
+	if err := storage.SetAnimation(configPath, "e2:happy", imported); err != nil {
+		t.Fatalf("Failed to set animation: %v", err)
+	}
+	boxed := pet.AnimationConfig{Source: "inline", FPS: 1, Loops: 1, Frames: []pet.Frame{{Art: "[ o.o ]\n[[ boxed ]]\n"}}}
+	if err := storage.SetAnimation(configPath, "boxed", boxed); err != nil {
+		t.Fatalf("Failed to add animation: %v", err)
+	}
+	// Replacing again must not mistake art lines for tables
+	boxed.FPS = 3
+	if err := storage.SetAnimation(configPath, "boxed", boxed); err != nil {
+		t.Fatalf("Failed to replace animation: %v", err)
+	}
+
+	after, err := storage.LoadPet(configPath, statePath)
+	if err != nil {
+		t.Fatalf("Failed to reload pet: %v", err)
+	}
+	got := after.Config.Animations["e2:happy"]
+	if got.FPS != 2 || len(got.Frames) != 2 || got.Frames[1].Pixels[0][1] != "#FF0000" || got.Frames[0].MS != 250 {
+		t.Errorf("Unexpected imported animation: %+v", got)
+	}
+	if got := after.Config.Animations["boxed"]; got.FPS != 3 || len(got.Frames) != 1 || got.Frames[0].Art != boxed.Frames[0].Art {
+		t.Errorf("Unexpected boxed animation: %+v", got)
+	}
+	for key, anim := range before.Config.Animations {
+		if key == "e2:happy" {
+			continue
+		}
+		if len(after.Config.Animations[key].Frames) != len(anim.Frames) {
+			t.Errorf("Animation %s changed: %d frames, want %d", key, len(after.Config.Animations[key].Frames), len(anim.Frames))
+		}
+	}
+	if after.Config.Name != "ImportPixel" || after.Config.Prompt.Icon != before.Config.Prompt.Icon {
+		t.Errorf("Expected the rest of the config to be kept, got %+v", after.Config.Prompt)
+	}
+}
+
+func TestAccessoryUnlocks(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := storage.InitPet(false, "pixel", "Dressed", tmpDir); err != nil {
+		t.Fatalf("Failed to initialize pet: %v", err)
+	}
+	statePath := filepath.Join(tmpDir, ".familiar", "pet.state.toml")
+	configPath := discovery.GetConfigPathFromState(statePath)
+	p, err := storage.LoadPet(configPath, statePath)
+	if err != nil {
+		t.Fatalf("Failed to load pet: %v", err)
+	}
+	if got := p.UnlockedAccessories(); len(got) != 0 {
+		t.Fatalf("Expected a new familiar to have no accessories, got %v", got)
+	}
+
+	// The heart is unlocked by the well-fed achievement, earned once
+	now := time.Now()
+	p.State.Feeds = 25
+	var earned []string
+	for _, a := range p.State.AwardAchievements(now) {
+		earned = append(earned, a.ID)
+	}
+	if strings.Join(earned, " ") != "first-meal well-fed" {
+		t.Errorf("Expected first-meal and well-fed, got %v", earned)
+	}
+	if again := p.State.AwardAchievements(now); len(again) != 0 {
+		t.Errorf("Expected achievements to be awarded once, got %v", again)
+	}
+	if !p.AccessoryUnlocked("heart") || p.AccessoryUnlocked("party-hat") {
+		t.Errorf("Expected only the heart unlocked, got %v", p.UnlockedAccessories())
+	}
+
+	// The party hat and grown-up come with evolution 2: hatching, then a
+	// stage age later the next feed evolves it
+	p.Evolve(now)
+	if p.State.Evolution != 1 || p.AccessoryUnlocked("party-hat") {
+		t.Fatalf("Expected the hatched familiar at evolution 1 without the hat, got %d", p.State.Evolution)
+	}
+	if !p.Evolve(p.Config.CreatedAt.Add(pet.StageAge)) {
+		t.Fatalf("Expected evolution 2 a stage age after it was created, got %d", p.State.Evolution)
+	}
+	if awarded := p.State.AwardAchievements(now); len(awarded) != 1 || awarded[0].ID != "grown-up" {
+		t.Errorf("Expected grown-up at evolution 2, got %v", awarded)
+	}
+	if got := p.UnlockedAccessories(); strings.Join(got, " ") != "heart party-hat" {
+		t.Errorf("Expected both accessories unlocked, got %v", got)
+	}
+	if hint := p.Config.Accessories["party-hat"].Unlock(); hint != "evolution 2" {
+		t.Errorf("Unlock() = %q", hint)
+	}
+
+	// Worn accessories and achievements are saved with the state
+	p.State.Wearing = []string{"party-hat", "heart"}
+	if err := storage.SavePetState(p, statePath); err != nil {
+		t.Fatalf("Failed to save pet: %v", err)
+	}
+	loaded, err := storage.LoadPet(configPath, statePath)
+	if err != nil {
+		t.Fatalf("Failed to reload pet: %v", err)
+	}
+	if strings.Join(loaded.State.Wearing, " ") != "party-hat heart" || len(loaded.State.Achievements) != 3 {
+		t.Errorf("Expected wearing and achievements to be saved, got %v and %v", loaded.State.Wearing, loaded.State.Achievements)
+	}
+
+	// Every pixel animation grows to fit the hat and the heart above it
+	for key, anim := range loaded.Config.Animations {
+		if !anim.Pixel() {
+			continue
+		}
+		rows := 0
+		for _, frame := range anim.Frames {
+			rows = max(rows, len(frame.Pixels))
+		}
+		for i, frame := range art.Dress(loaded, key, anim).Frames {
+			if len(frame.Pixels) != rows+2 {
+				t.Errorf("%s frame %d: %d rows dressed, want %d", key, i, len(frame.Pixels), rows+2)
+			}
+		}
+	}
+}
+
+func TestPalettes(t *testing.T) {
+	template, err := storage.LoadTemplateConfig("pixel")
+	if err != nil {
+		t.Fatalf("Failed to load pixel template: %v", err)
+	}
+	for _, name := range []string{"default", "shiny", "winter"} {
+		if _, ok := template.Config.Palettes[name]; !ok {
+			t.Errorf("Expected the pixel template to have a %s palette", name)
+		}
+	}
+
+	// Alternate palettes keep the default colours they do not change
+	cfg := pet.PetConfig{Palettes: map[string]pet.Palette{
+		"default": {"fur": "#FFD700", "eye": "#000000"},
+		"shiny":   {"fur": "#C0C0C0"},
+	}}
+	shiny := cfg.Palette("shiny")
+	if shiny.Color("fur") != "#C0C0C0" || shiny.Color("eye") != "#000000" {
+		t.Errorf("Expected shiny fur and default eyes, got %v", shiny)
+	}
+	if shiny.Color("#FF0000") != "#FF0000" || shiny.Color("") != "" || shiny.Color("tail") != "tail" {
+		t.Error("Expected hex, transparent and unknown pixels to be unchanged")
+	}
+	anim := pet.AnimationConfig{Source: "pixel", Frames: []pet.Frame{{Pixels: [][]string{{"fur", "", "eye"}}, MS: 100}}}
+	applied := shiny.Apply(anim)
+	if got := strings.Join(applied.Frames[0].Pixels[0], " "); got != "#C0C0C0  #000000" || applied.Frames[0].MS != 100 {
+		t.Errorf("Apply() = %q", got)
+	}
+	if anim.Frames[0].Pixels[0][0] != "fur" {
+		t.Error("Expected Apply to leave the animation's own frames alone")
+	}
+
+	// Rendering draws symbols in the colours of the palette applied
+	hex := pet.Frame{Pixels: [][]string{{"#C0C0C0", "", "#000000"}}}
+	if got, want := art.RenderPixelArtDepth(applied.Frames[0], term.DepthTrue), art.RenderPixelArtDepth(hex, term.DepthTrue); got != want {
+		t.Errorf("Expected symbols to render as their colours:\n%q\n%q", got, want)
+	}
+
+	// Palettes are checked when they are loaded
+	for name, tt := range map[string]struct {
+		palettes map[string]pet.Palette
+		pixels   []string
+		wantErr  string
+	}{
+		"valid":          {cfg.Palettes, []string{"fur", "#abc", "#A0B0C0", "", "transparent", "  ", "##"}, ""},
+		"unknown symbol": {cfg.Palettes, []string{"tail"}, `"tail" is not in the default palette`},
+		"only in shiny":  {map[string]pet.Palette{"shiny": {"fur": "#C0C0C0"}}, []string{"fur"}, `"fur" is not in the default palette`},
+		"bad pixel hex":  {cfg.Palettes, []string{"#12345"}, `"#12345" is not a #RGB or #RRGGBB colour`},
+		"bad colour":     {map[string]pet.Palette{"default": {"fur": "gold"}}, nil, `palette default: fur is "gold"`},
+		"hex symbol":     {map[string]pet.Palette{"default": {"#fff": "#000"}}, nil, `"#fff" cannot be a symbol`},
+	} {
+		c := pet.PetConfig{Palettes: tt.palettes, Animations: map[string]pet.AnimationConfig{
+			"default": {Source: "pixel", Frames: []pet.Frame{{Pixels: [][]string{tt.pixels}}}},
+		}}
+		err := c.ValidatePalettes()
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s: ValidatePalettes() = %v", name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: ValidatePalettes() = %v, want %s", name, err, tt.wantErr)
+		}
+	}
+
+	// The familiar's palette is saved with its state
+	tmpDir := t.TempDir()
+	if err := storage.InitPet(false, "pixel", "Shiny", tmpDir); err != nil {
+		t.Fatalf("Failed to initialize pet: %v", err)
+	}
+	statePath := filepath.Join(tmpDir, ".familiar", "pet.state.toml")
+	configPath := discovery.GetConfigPathFromState(statePath)
+	p, err := storage.LoadPet(configPath, statePath)
+	if err != nil {
+		t.Fatalf("Failed to load pet: %v", err)
+	}
+	if p.ActivePalette().Color("fur") != "#FFD700" {
+		t.Errorf("Expected golden fur by default, got %s", p.ActivePalette().Color("fur"))
+	}
+	p.State.Palette = "winter"
+	if err := storage.SavePetState(p, statePath); err != nil {
+		t.Fatalf("Failed to save pet: %v", err)
+	}
+	loaded, err := storage.LoadPet(configPath, statePath)
+	if err != nil {
+		t.Fatalf("Failed to reload pet: %v", err)
+	}
+	if loaded.ActivePalette().Color("fur") != template.Config.Palettes["winter"]["fur"] {
+		t.Errorf("Expected winter fur after reloading, got %s", loaded.ActivePalette().Color("fur"))
+	}
+}
+
+func TestGridFrames(t *testing.T) {
+	legend := map[string]string{"f": "fur", "k": "#000000"}
+	pixels, err := pet.ExpandGrid([]string{".f.", "fkf"}, legend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"", "fur", ""}, {"fur", "#000000", "fur"}}; fmt.Sprint(pixels) != fmt.Sprint(want) {
+		t.Errorf("ExpandGrid() = %q, want %q", pixels, want)
+	}
+	if _, err := pet.ExpandGrid([]string{"fx"}, legend); err == nil || !strings.Contains(err.Error(), "'x' is not in the legend") {
+		t.Errorf("Expected an error for a character missing from the legend, got %v", err)
+	}
+
+	// An animation's own legend adds to the config's
+	cfg := pet.PetConfig{
+		Legend: legend,
+		Animations: map[string]pet.AnimationConfig{
+			"default": {Source: "pixel", Legend: map[string]string{"k": "#FFFFFF"}, Frames: []pet.Frame{{Grid: []string{"fk"}, MS: 50}}},
+		},
+		Accessories: map[string]pet.AccessoryConfig{
+			"hat": {Frames: []pet.Frame{{Grid: []string{"k."}}}},
+		},
+	}
+	if err := cfg.ExpandGrids(); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Animations["default"].Frames[0].Pixels; fmt.Sprint(got) != "[[fur #FFFFFF]]" {
+		t.Errorf("Expected the animation's legend to win, got %q", got)
+	}
+	if got := cfg.Accessories["hat"].Frames[0].Pixels; fmt.Sprint(got) != "[[#000000 ]]" {
+		t.Errorf("Expected accessories to use the config's legend, got %q", got)
+	}
+	if compact := cfg.Compact(); compact.Animations["default"].Frames[0].Pixels != nil || cfg.Animations["default"].Frames[0].Pixels == nil {
+		t.Error("Expected Compact to drop the expanded pixels from a copy only")
+	}
+
+	// Pixel frames become grids, with new colours added to the legend
+	frames := []pet.Frame{{Pixels: [][]string{{"", "fur", "#FF0000"}, {"transparent", "#000000", "face"}}, MS: 100}}
+	extended := pet.GridLegend(frames, legend)
+	if extended["f"] != "fur" || extended["F"] != "face" || len(extended) != 4 {
+		t.Errorf("GridLegend() = %v", extended)
+	}
+	grids, err := pet.GridFrames(frames, extended)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(grids[0].Grid, "/"); got != ".f"+grids[0].Grid[0][2:]+"/.kF" || grids[0].MS != 100 {
+		t.Errorf("GridFrames() = %q", got)
+	}
+	back, err := pet.ExpandGrid(grids[0].Grid, extended)
+	if err != nil || back[0][2] != "#FF0000" {
+		t.Errorf("Expected the grid to expand back to the same colours, got %q, %v", back, err)
+	}
+
+	// Frame sets in files can be grids with their own legend
+	set := "[legend]\nr = \"#FF0000\"\n\n[[frames]]\ngrid = [\"r.\", \".r\"]\n"
+	parsed, err := storage.ParseFrames([]byte(set), ".toml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(parsed[0].Pixels) != "[[#FF0000 ] [ #FF0000]]" {
+		t.Errorf("Expected a grid frame set to expand, got %q", parsed[0].Pixels)
+	}
+}
+
+func TestGridFramesAreSaved(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := storage.InitPet(false, "pixel", "Gridded", tmpDir); err != nil {
+		t.Fatalf("Failed to initialize pet: %v", err)
+	}
+	statePath := filepath.Join(tmpDir, ".familiar", "pet.state.toml")
+	configPath := discovery.GetConfigPathFromState(statePath)
+
+	// The shared legend is replaced in place, and grid animations written
+	template, err := storage.LoadPet(configPath, statePath)
+	if err != nil {
+		t.Fatalf("Failed to load pet: %v", err)
+	}
+	legend := map[string]string{"x": "#123456", "#": "#FFFFFF"}
+	for k, v := range template.Config.Legend {
+		legend[k] = v
+	}
+	if err := storage.SetLegend(configPath, legend); err != nil {
+		t.Fatalf("Failed to set legend: %v", err)
+	}
+	anim := pet.AnimationConfig{Source: "pixel", FPS: 2, Loops: -1, Frames: []pet.Frame{{Grid: []string{"fx#", ".f."}}}}
+	if err := storage.SetAnimation(configPath, "happy", anim); err != nil {
+		t.Fatalf("Failed to set animation: %v", err)
+	}
+	data, _ := os.ReadFile(configPath)
+	if strings.Count(string(data), "[legend]") != 1 || !strings.Contains(string(data), "\"#\" = \"#FFFFFF\"") {
+		t.Errorf("Expected one [legend] table with a quoted # key:\n%s", data)
+	}
+
+	p, err := storage.LoadPet(configPath, statePath)
+	if err != nil {
+		t.Fatalf("Failed to load pet: %v", err)
+	}
+	if got := p.Config.Animations["happy"].Frames[0].Pixels; fmt.Sprint(got) != "[[fur #123456 #FFFFFF] [ fur ]]" {
+		t.Errorf("Expected the grid expanded through the legend, got %q", got)
+	}
+
+	// Saving the whole config keeps grids as grids
+	if err := storage.SavePetConfig(p, configPath); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	data, _ = os.ReadFile(configPath)
+	if strings.Contains(string(data), "pixels") || !strings.Contains(string(data), "fx#") {
+		t.Errorf("Expected the saved config to keep grids and not their pixels")
+	}
+	if _, err := storage.LoadPet(configPath, statePath); err != nil {
+		t.Errorf("Failed to reload the saved config: %v", err)
+	}
+}
+
+func TestNewEggIsNotReadyToEvolve(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := storage.InitPet(false, "cat", "Eggbert", tmpDir); err != nil {
+		t.Fatalf("Failed to initialize pet: %v", err)
+	}
+	statePath := filepath.Join(tmpDir, ".familiar", "pet.state.toml")
+	p, err := storage.LoadPet(discovery.GetConfigPathFromState(statePath), statePath)
+	if err != nil {
+		t.Fatalf("Failed to load pet: %v", err)
+	}
+	now := time.Now()
+	if p.State.Evolution != 0 || p.ReadyToEvolve(now) {
+		t.Fatalf("Expected a new egg that is not ready to evolve, got evolution %d ready %v", p.State.Evolution, p.ReadyToEvolve(now))
+	}
+	if seg := prompt.Build(p, now); seg.State == prompt.StateEvolutionReady {
+		t.Errorf("Expected the prompt to show health for a new egg, got %s", seg.State)
+	}
+
+	// Feeding is what hatches it
+	p.State.Feeds++
+	if !p.Evolve(now) || p.State.Evolution != 1 {
+		t.Errorf("Expected the fed egg to hatch, got evolution %d", p.State.Evolution)
+	}
+	if p.ReadyToEvolve(now) {
+		t.Error("Expected a hatched familiar not to be ready again")
+	}
+}
+
+func TestEvolveByAge(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := storage.InitPet(false, "cat", "Eggbert", tmpDir); err != nil {
+		t.Fatalf("Failed to initialize pet: %v", err)
+	}
+	statePath := filepath.Join(tmpDir, ".familiar", "pet.state.toml")
+	p, err := storage.LoadPet(discovery.GetConfigPathFromState(statePath), statePath)
+	if err != nil {
+		t.Fatalf("Failed to load pet: %v", err)
+	}
+	if p.Config.EvolutionMode != pet.EvolutionModeByAge {
+		t.Fatalf("Expected the cat template to evolve by age, got %q", p.Config.EvolutionMode)
+	}
+	p.State.Feeds++
+	p.Evolve(p.Config.CreatedAt)
+
+	// A stage age later it shows as ready until the next interaction evolves it
+	later := p.Config.CreatedAt.Add(pet.StageAge)
+	if seg := prompt.Build(p, later); seg.State != prompt.StateEvolutionReady {
+		t.Errorf("Expected the prompt to show evolution-ready after a stage age, got %s", seg.State)
+	}
+	if !p.Evolve(later) || p.State.Evolution != 2 {
+		t.Fatalf("Expected evolution 2 after a stage age, got %d", p.State.Evolution)
+	}
+	if p.ReadyToEvolve(later) {
+		t.Error("Expected evolution 3 to wait for another stage age")
+	}
+
+	// It stops at maxEvolution however old it gets
+	old := p.Config.CreatedAt.Add(100 * pet.StageAge)
+	for p.Evolve(old) {
+	}
+	if p.State.Evolution != p.Config.MaxEvolution {
+		t.Errorf("Expected evolution to stop at %d, got %d", p.Config.MaxEvolution, p.State.Evolution)
+	}
+}
+
+func TestWatchValidate(t *testing.T) {
+	_, want := pet.ParseSeverity("urgent")
+	tests := map[string]struct {
+		watch   pet.WatchConfig
+		wantErr string
+	}{
+		"valid":       {pet.WatchConfig{Paths: []string{"*.yaml"}, Severity: "Warning", Expires: "7d"}, ""},
+		"no severity": {pet.WatchConfig{Paths: []string{"*.yaml"}}, ""},
+		"no paths":    {pet.WatchConfig{Severity: "warn"}, "watch is missing paths"},
+		"severity":    {pet.WatchConfig{Paths: []string{"*.yaml"}, Severity: "urgent"}, want.Error()},
+		"expires":     {pet.WatchConfig{Paths: []string{"*.yaml"}, Expires: "soon"}, "watch [*.yaml]"},
+	}
+	for name, tt := range tests {
+		err := tt.watch.Validate()
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s: Validate() = %v", name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: Validate() = %v, want %s", name, err, tt.wantErr)
+		}
+	}
+}
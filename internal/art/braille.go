@@ -0,0 +1,95 @@
+package art
+
+import (
+	"strings"
+
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/term"
+)
+
+// brailleDots are the bits of the braille character for each dot, indexed
+// by row then column of the 2x4 cell
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// bayer4 is the 4x4 ordered dithering matrix
+var bayer4 = [4][4]int{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// brailleConfig is the pet's braille setting, see SetBraille
+var brailleConfig pet.BrailleConfig
+
+// SetBraille sets how pixel art is turned into braille dots when graphics
+// is "braille"
+func SetBraille(c pet.BrailleConfig) {
+	brailleConfig = c
+}
+
+// RenderBraille renders a pixel art frame as braille characters, 2x4 pixels
+// per cell, so art takes half the columns and a quarter of the rows of the
+// pixels. A pixel becomes a dot when it is opaque and at least
+// c.Threshold bright, or by ordered dithering with c.Dither. Each cell is
+// coloured with the average of its dots at depth.
+func RenderBraille(frame pet.Frame, c pet.BrailleConfig, depth term.Depth) string {
+	height := len(frame.Pixels)
+	width := 0
+	for _, row := range frame.Pixels {
+		width = max(width, len(row))
+	}
+	if height == 0 || width == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, (height+3)/4)
+	for top := 0; top < height; top += 4 {
+		var line strings.Builder
+		for left := 0; left < width; left += 2 {
+			var char rune
+			var r, g, b, n int
+			for dy := 0; dy < 4; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					x, y := left+dx, top+dy
+					if y >= height || x >= len(frame.Pixels[y]) || isTransparentPixel(frame.Pixels[y][x]) {
+						continue
+					}
+					pr, pg, pb := hexToRGB(frame.Pixels[y][x])
+					if !brailleDot(term.Luminance(pr, pg, pb), x, y, c) {
+						continue
+					}
+					char |= brailleDots[dy][dx]
+					r, g, b, n = r+pr, g+pg, b+pb, n+1
+				}
+			}
+
+			switch {
+			case n == 0:
+				line.WriteByte(' ')
+			case depth == term.DepthNone:
+				line.WriteRune(0x2800 + char)
+			default:
+				line.WriteString("\033[" + term.FG(r/n, g/n, b/n, depth) + "m")
+				line.WriteRune(0x2800 + char)
+				line.WriteString("\033[0m")
+			}
+		}
+		lines = append(lines, line.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// brailleDot decides whether an opaque pixel of luminance lum at x, y is a dot
+func brailleDot(lum, x, y int, c pet.BrailleConfig) bool {
+	if c.Dither {
+		// Scale the matrix to 0-255 so mid grey lights half the dots
+		return lum > bayer4[y%4][x%4]*16+8
+	}
+	return lum >= c.Threshold
+}
@@ -0,0 +1,59 @@
+package art
+
+import (
+	"testing"
+
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/term"
+)
+
+func TestRenderBraille(t *testing.T) {
+	// A 3x5 frame: a full white 2x4 cell, a half-lit cell, and one dot below
+	w, k := "#FFFFFF", "#202020"
+	frame := pet.Frame{Pixels: [][]string{
+		{w, w, w},
+		{w, w, ""},
+		{w, w, k},
+		{w, w, ""},
+		{"", w, ""},
+	}}
+
+	tests := []struct {
+		name string
+		c    pet.BrailleConfig
+		want string
+	}{
+		{"every pixel", pet.BrailleConfig{}, "⣿⠅\n⠈ "},
+		{"threshold", pet.BrailleConfig{Threshold: 128}, "⣿⠁\n⠈ "},
+	}
+	for _, tt := range tests {
+		if got := RenderBraille(frame, tt.c, term.DepthNone); got != tt.want {
+			t.Errorf("%s: RenderBraille() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if got := RenderBraille(frame, pet.BrailleConfig{}, term.DepthTrue); got[:len("\033[38;2;")] != "\033[38;2;" {
+		t.Errorf("Expected coloured cells, got %q", got)
+	}
+	if got := RenderBraille(pet.Frame{}, pet.BrailleConfig{}, term.DepthTrue); got != "" {
+		t.Errorf("Expected nothing for an empty frame, got %q", got)
+	}
+}
+
+func TestBrailleDither(t *testing.T) {
+	// Mid grey lights about half the dots of a 4x4 block
+	dots := 0
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if brailleDot(128, x, y, pet.BrailleConfig{Dither: true}) {
+				dots++
+			}
+		}
+	}
+	if dots != 8 {
+		t.Errorf("Expected 8 of 16 dots for mid grey, got %d", dots)
+	}
+	if brailleDot(0, 0, 0, pet.BrailleConfig{Dither: true}) || !brailleDot(255, 3, 3, pet.BrailleConfig{Dither: true}) {
+		t.Error("Expected black to have no dots and white every dot")
+	}
+}
@@ -0,0 +1,120 @@
+package art
+
+import (
+	"strings"
+
+	"github.com/sethgrid/familiar/internal/term"
+)
+
+// BubbleStyle selects the characters used to draw a speech bubble
+type BubbleStyle string
+
+const (
+	BubbleRound BubbleStyle = "round" // box-drawing characters with rounded corners
+	BubbleASCII BubbleStyle = "ascii" // plain ASCII for limited fonts
+)
+
+type bubbleChars struct {
+	topLeft, topRight, bottomLeft, bottomRight string
+	horizontal, vertical                       string
+	tail, tailJoin                             string // gutter and left edge of the row the tail points from
+}
+
+var bubbleStyles = map[BubbleStyle]bubbleChars{
+	BubbleRound: {"╭", "╮", "╰", "╯", "─", "│", " ─", "┤"},
+	BubbleASCII: {"+", "+", "+", "+", "-", "|", " <", "|"},
+}
+
+// bubbleGap separates the art from the bubble's tail
+const bubbleGap = " "
+
+// Bubble draws lines inside a speech bubble whose tail points left, at the art.
+// Lines may contain ANSI escapes; padding uses their visible width.
+func Bubble(lines []string, style BubbleStyle) []string {
+	chars, ok := bubbleStyles[style]
+	if !ok {
+		chars = bubbleStyles[BubbleRound]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	width := 0
+	for _, line := range lines {
+		width = max(width, term.VisibleWidth(line))
+	}
+
+	gutter := strings.Repeat(" ", term.VisibleWidth(chars.tail))
+	border := strings.Repeat(chars.horizontal, width+2)
+
+	out := make([]string, 0, len(lines)+2)
+	out = append(out, gutter+chars.topLeft+border+chars.topRight)
+	for i, line := range lines {
+		pad := strings.Repeat(" ", width-term.VisibleWidth(line))
+		left := gutter + chars.vertical
+		if i == 0 {
+			left = chars.tail + chars.tailJoin
+		}
+		out = append(out, left+" "+line+pad+" "+chars.vertical)
+	}
+	out = append(out, gutter+chars.bottomLeft+border+chars.bottomRight)
+	return out
+}
+
+// BubbleOverhead is the number of columns Bubble adds around its widest line
+func BubbleOverhead() int {
+	return len(bubbleGap) + 2 + 4 // gap, tail gutter, borders and inner padding
+}
+
+// Width returns the visible width of the widest line of art
+func Width(art string) int {
+	width := 0
+	for _, line := range strings.Split(art, "\n") {
+		width = max(width, term.VisibleWidth(line))
+	}
+	return width
+}
+
+// SideBySide places bubble to the right of art, both top-aligned. Art lines are
+// padded to the art's visible width so ANSI-coloured pixel art lines up.
+// A nil bubble returns art unchanged.
+func SideBySide(art string, bubble []string) string {
+	if len(bubble) == 0 {
+		return art
+	}
+	return Compose([]string{art}, bubble)[0]
+}
+
+// Compose places bubble beside every frame, padding all frames to the widest
+// one so the bubble stays put while an animation plays
+func Compose(frames []string, bubble []string) []string {
+	if len(bubble) == 0 {
+		return frames
+	}
+
+	width := 0
+	for _, frame := range frames {
+		width = max(width, Width(strings.TrimRight(frame, "\n\r")))
+	}
+
+	composed := make([]string, len(frames))
+	for i, frame := range frames {
+		artLines := strings.Split(strings.TrimRight(frame, "\n\r"), "\n")
+		rows := max(len(artLines), len(bubble))
+
+		lines := make([]string, rows)
+		for row := 0; row < rows; row++ {
+			artLine := ""
+			if row < len(artLines) {
+				artLine = artLines[row]
+			}
+			if row >= len(bubble) {
+				lines[row] = strings.TrimRight(artLine, " ")
+				continue
+			}
+			lines[row] = artLine + strings.Repeat(" ", width-term.VisibleWidth(artLine)) + bubbleGap + bubble[row]
+		}
+		composed[i] = strings.Join(lines, "\n")
+	}
+	return composed
+}
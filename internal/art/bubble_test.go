@@ -0,0 +1,75 @@
+package art
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/term"
+)
+
+func TestBubble(t *testing.T) {
+	got := strings.Join(Bubble([]string{"hi there", "bye"}, BubbleASCII), "\n")
+	want := strings.Join([]string{
+		"  +----------+",
+		" <| hi there |",
+		"  | bye      |",
+		"  +----------+",
+	}, "\n")
+	if got != want {
+		t.Errorf("Bubble:\n%s\nwant:\n%s", got, want)
+	}
+
+	if Bubble(nil, BubbleRound) != nil {
+		t.Error("Expected no bubble for no lines")
+	}
+}
+
+func TestBubbleIgnoresANSIWidth(t *testing.T) {
+	lines := Bubble([]string{"\033[1mbold\033[0m", "plain"}, BubbleRound)
+	for _, line := range lines {
+		if w := term.VisibleWidth(line); w != term.VisibleWidth(lines[0]) {
+			t.Errorf("Line %q is %d columns, expected %d", line, w, term.VisibleWidth(lines[0]))
+		}
+	}
+}
+
+func TestComposeKeepsBubbleAligned(t *testing.T) {
+	// Pixel frames of different visible widths, with ANSI codes
+	frames := []string{
+		RenderPixelArtDepth(pet.Frame{Pixels: [][]string{{"#ff0000", "#ff0000"}, {"#00ff00", ""}}}, term.DepthTrue),
+		RenderPixelArtDepth(pet.Frame{Pixels: [][]string{{"#ff0000", "#ff0000", "#ff0000", "#ff0000"}}}, term.DepthTrue),
+	}
+	bubble := Bubble([]string{"hello"}, BubbleASCII)
+
+	composed := Compose(frames, bubble)
+	if len(composed) != len(frames) {
+		t.Fatalf("Expected %d frames, got %d", len(frames), len(composed))
+	}
+
+	column := -1
+	for _, frame := range composed {
+		first := strings.Split(frame, "\n")[0]
+		plain := term.StripANSI(first)
+		idx := term.VisibleWidth(plain[:strings.Index(plain, "+")])
+		if column >= 0 && idx != column {
+			t.Errorf("Bubble starts at column %d, expected %d:\n%s", idx, column, frame)
+		}
+		column = idx
+	}
+	if column != 4+len(bubbleGap)+2 {
+		t.Errorf("Bubble starts at column %d, expected after the widest frame", column)
+	}
+
+	// Bubble taller than the art
+	if got := len(strings.Split(composed[1], "\n")); got != len(bubble) {
+		t.Errorf("Expected %d lines, got %d", len(bubble), got)
+	}
+}
+
+func TestSideBySideWithoutBubble(t *testing.T) {
+	art := " /\\_/\\ \n( o.o )"
+	if got := SideBySide(art, nil); got != art {
+		t.Errorf("Expected art unchanged, got %q", got)
+	}
+}
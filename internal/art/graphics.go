@@ -0,0 +1,245 @@
+package art
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/term"
+)
+
+// kittyChunk is the most base64 data the kitty protocol allows per escape
+const kittyChunk = 4096
+
+// maxSixelColors is the number of colour registers sixel terminals provide
+const maxSixelColors = 256
+
+// kittyID numbers the images sent to kitty. Images keep their id on the
+// terminal, so it starts from the process id to avoid replacing images an
+// earlier familiar left in the scrollback.
+var kittyID atomic.Uint32
+
+func init() {
+	kittyID.Store(uint32(os.Getpid()&0xffff) << 12)
+}
+
+// graphicsFrames renders pixel frames as images for protocol. Every image
+// covers the cells half-blocks would, one column per pixel and one row per
+// two, so layout and bubbles are unchanged. upload is written once before the
+// frames: with kitty it transmits every image, and the frames only place them,
+// so an animation sends each image once. ok is false when the frames cannot
+// be drawn with protocol and half-blocks should be used instead.
+func graphicsFrames(frames []pet.Frame, protocol string, cellW, cellH int) (upload string, rendered []string, ok bool) {
+	if protocol != term.GraphicsKitty && protocol != term.GraphicsITerm && protocol != term.GraphicsSixel {
+		return "", nil, false
+	}
+	scaleX, scaleY := max(cellW, 1), max(cellH/2, 1)
+
+	var up strings.Builder
+	ids := make([]uint32, len(frames))
+	images := make([]string, len(frames))
+	for i, frame := range frames {
+		img := pixelImage(frame, scaleX, scaleY)
+		cols, rows := pixelCells(frame)
+		if cols == 0 {
+			return "", nil, false
+		}
+		switch protocol {
+		case term.GraphicsKitty:
+			data, err := encodePNG(img)
+			if err != nil {
+				return "", nil, false
+			}
+			ids[i] = kittyID.Add(1)
+			up.WriteString(kittyTransmit(ids[i], data))
+			images[i] = fmt.Sprintf("\033_Ga=p,i=%d,p=1,c=%d,r=%d,C=1,q=2\033\\", ids[i], cols, rows)
+		case term.GraphicsITerm:
+			data, err := encodePNG(img)
+			if err != nil {
+				return "", nil, false
+			}
+			images[i] = fmt.Sprintf("\033]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=0:%s\a",
+				len(data), cols, rows, base64.StdEncoding.EncodeToString(data))
+		case term.GraphicsSixel:
+			sixel, ok := encodeSixel(img)
+			if !ok {
+				return "", nil, false
+			}
+			images[i] = sixel
+		}
+	}
+
+	rendered = make([]string, len(frames))
+	for i, frame := range frames {
+		// Kitty images are not erased with the text under them, so a frame
+		// removes the placements of the others before placing its own
+		var clear strings.Builder
+		for j, id := range ids {
+			if j != i && id != 0 {
+				fmt.Fprintf(&clear, "\033_Ga=d,d=i,i=%d,q=2\033\\", id)
+			}
+		}
+		cols, rows := pixelCells(frame)
+		rendered[i] = placeImage(clear.String()+images[i], cols, rows)
+	}
+	return up.String(), rendered, true
+}
+
+// placeImage fills cols by rows cells with spaces, then draws seq over them
+// from the last line. Writing the spaces first makes the terminal scroll
+// before the image is drawn, and saving the cursor around the image keeps
+// protocols that move it (iTerm2, sixel) from disturbing what follows.
+func placeImage(seq string, cols, rows int) string {
+	line := strings.Repeat(" ", cols)
+	lines := make([]string, rows)
+	for i := range lines {
+		lines[i] = line
+	}
+	up := ""
+	if rows > 1 {
+		up = fmt.Sprintf("\033[%dA", rows-1)
+	}
+	lines[rows-1] += fmt.Sprintf("\0337%s\033[%dD%s\0338", up, cols, seq)
+	return strings.Join(lines, "\n")
+}
+
+// pixelCells is the size in cells half-blocks draw frame at
+func pixelCells(frame pet.Frame) (cols, rows int) {
+	for _, row := range frame.Pixels {
+		cols = max(cols, len(row))
+	}
+	return cols, (len(frame.Pixels) + 1) / 2
+}
+
+// pixelImage draws frame with every pixel scaleX by scaleY image pixels.
+// Transparent pixels stay transparent.
+func pixelImage(frame pet.Frame, scaleX, scaleY int) *image.NRGBA {
+	cols, _ := pixelCells(frame)
+	img := image.NewNRGBA(image.Rect(0, 0, cols*scaleX, len(frame.Pixels)*scaleY))
+	for y, row := range frame.Pixels {
+		for x, px := range row {
+			if isTransparentPixel(px) {
+				continue
+			}
+			r, g, b := hexToRGB(px)
+			c := color.NRGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 0xff}
+			for dy := 0; dy < scaleY; dy++ {
+				for dx := 0; dx < scaleX; dx++ {
+					img.SetNRGBA(x*scaleX+dx, y*scaleY+dy, c)
+				}
+			}
+		}
+	}
+	return img
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// kittyTransmit sends a PNG to kitty as image id without showing it, split
+// into chunks. q=2 stops the terminal answering on stdin.
+func kittyTransmit(id uint32, data []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var b strings.Builder
+	for first := true; first || encoded != ""; first = false {
+		chunk := encoded[:min(kittyChunk, len(encoded))]
+		encoded = encoded[len(chunk):]
+		more := 0
+		if encoded != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&b, "\033_Ga=t,f=100,i=%d,q=2,m=%d;%s\033\\", id, more, chunk)
+		} else {
+			fmt.Fprintf(&b, "\033_Gm=%d;%s\033\\", more, chunk)
+		}
+	}
+	return b.String()
+}
+
+// encodeSixel encodes img as a sixel image with a colour register per
+// colour. Unset pixels are left showing the background. ok is false when
+// img has more colours than terminals have registers.
+func encodeSixel(img *image.NRGBA) (string, bool) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	index := map[color.NRGBA]int{}
+	var colors []color.NRGBA
+	for i := 0; i < len(img.Pix); i += 4 {
+		c := color.NRGBA{R: img.Pix[i], G: img.Pix[i+1], B: img.Pix[i+2], A: img.Pix[i+3]}
+		if _, seen := index[c]; c.A == 0 || seen {
+			continue
+		}
+		if len(colors) == maxSixelColors {
+			return "", false
+		}
+		index[c] = len(colors)
+		colors = append(colors, c)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\033P0;1;0q\"1;1;%d;%d", w, h)
+	for i, c := range colors {
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", i, percent(c.R), percent(c.G), percent(c.B))
+	}
+
+	// Each band is six rows; every colour in it is drawn in its own pass
+	// over the band, returning to the start of the band with $
+	sixels := make([]byte, w)
+	for top := 0; top < h; top += 6 {
+		for i, c := range colors {
+			used := false
+			for x := 0; x < w; x++ {
+				bits := byte(0)
+				for dy := 0; dy < 6 && top+dy < h; dy++ {
+					if img.NRGBAAt(x, top+dy) == c {
+						bits |= 1 << dy
+					}
+				}
+				sixels[x] = 63 + bits
+				used = used || bits != 0
+			}
+			if !used {
+				continue
+			}
+			fmt.Fprintf(&b, "#%d", i)
+			writeSixelRuns(&b, bytes.TrimRight(sixels, "?"))
+			b.WriteByte('$')
+		}
+		b.WriteByte('-')
+	}
+	b.WriteString("\033\\")
+	return b.String(), true
+}
+
+// writeSixelRuns writes sixels, compressing repeats as !<count><sixel>
+func writeSixelRuns(b *strings.Builder, sixels []byte) {
+	for i := 0; i < len(sixels); {
+		run := 1
+		for i+run < len(sixels) && sixels[i+run] == sixels[i] {
+			run++
+		}
+		if run > 3 {
+			fmt.Fprintf(b, "!%d%c", run, sixels[i])
+		} else {
+			b.Write(sixels[i : i+run])
+		}
+		i += run
+	}
+}
+
+// percent converts a colour channel to the 0-100 range sixel uses
+func percent(v uint8) int {
+	return (int(v)*100 + 127) / 255
+}
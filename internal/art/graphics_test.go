@@ -0,0 +1,76 @@
+package art
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/term"
+)
+
+var twoFrames = []pet.Frame{
+	{Pixels: [][]string{{"#FF0000", ""}, {"", "#00FF00"}, {"#0000FF", "#0000FF"}}},
+	{Pixels: [][]string{{"", "#FF0000"}, {"#00FF00", ""}, {"#0000FF", ""}}},
+}
+
+func TestGraphicsFramesLayout(t *testing.T) {
+	for _, protocol := range []string{term.GraphicsKitty, term.GraphicsITerm, term.GraphicsSixel} {
+		_, rendered, ok := graphicsFrames(twoFrames, protocol, 8, 16)
+		if !ok {
+			t.Fatalf("%s: expected frames", protocol)
+		}
+		// Same footprint as half-blocks: 2 columns, 3 pixel rows in 2 lines
+		for _, r := range rendered {
+			if lines := strings.Split(r, "\n"); len(lines) != 2 || Width(r) != 2 {
+				t.Errorf("%s: expected 2 lines 2 columns wide, got %d lines %d wide", protocol, len(lines), Width(r))
+			}
+		}
+	}
+
+	if _, _, ok := graphicsFrames(twoFrames, term.GraphicsBlocks, 8, 16); ok {
+		t.Error("Expected no images for blocks")
+	}
+}
+
+func TestKittyUploadsOnce(t *testing.T) {
+	upload, rendered, _ := graphicsFrames(twoFrames, term.GraphicsKitty, 8, 16)
+	if got := strings.Count(upload, "a=t,"); got != 2 {
+		t.Errorf("Expected both images uploaded once, got %d", got)
+	}
+	for i, r := range rendered {
+		if strings.Contains(r, "a=t,") || !strings.Contains(r, "a=p,") {
+			t.Errorf("Frame %d should only place its uploaded image: %q", i, r)
+		}
+		if !strings.Contains(r, "a=d,d=i,") {
+			t.Errorf("Frame %d should remove the other frame's placement", i)
+		}
+	}
+}
+
+func TestEncodeSixel(t *testing.T) {
+	img := pixelImage(pet.Frame{Pixels: [][]string{{"#FF0000", ""}}}, 4, 3)
+	got, ok := encodeSixel(img)
+	want := "\033P0;1;0q\"1;1;8;3#0;2;100;0;0#0!4F$-\033\\"
+	if !ok || got != want {
+		t.Errorf("encodeSixel() = %q, want %q", got, want)
+	}
+
+	many := image.NewNRGBA(image.Rect(0, 0, 300, 1))
+	for x := 0; x < 300; x++ {
+		many.SetNRGBA(x, 0, color.NRGBA{R: uint8(x), G: uint8(x / 256), A: 0xff})
+	}
+	if _, ok := encodeSixel(many); ok {
+		t.Error("Expected more than 256 colours to fall back")
+	}
+}
+
+func TestKittyTransmitChunks(t *testing.T) {
+	data := make([]byte, kittyChunk) // base64 grows it past one chunk
+	got := kittyTransmit(7, data)
+	if !strings.HasPrefix(got, "\033_Ga=t,f=100,i=7,q=2,m=1;") || !strings.Contains(got, "\033_Gm=0;") {
+		t.Errorf("Expected a chunked transmission, got %s", fmt.Sprintf("%.60q", got))
+	}
+}
@@ -0,0 +1,95 @@
+package art
+
+import (
+	"sort"
+
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+// layer is a worn accessory placed on the frames of an animation
+type layer struct {
+	z      int
+	x, y   int
+	frames []pet.Frame
+}
+
+// Dress composites the accessories the familiar wears onto anim, the
+// animation for key. Accessories that are locked, unknown or without frames
+// are skipped, and only pixel animations can be dressed. The frames grow to
+// fit accessories that reach outside them, by the same amount in every frame
+// so the familiar stays still.
+func Dress(p *pet.Pet, key string, anim pet.AnimationConfig) pet.AnimationConfig {
+	if !anim.Pixel() || len(anim.Frames) == 0 {
+		return anim
+	}
+
+	var layers []layer
+	for _, name := range p.State.Wearing {
+		a, ok := p.Config.Accessories[name]
+		if !ok || len(a.Frames) == 0 || !p.AccessoryUnlocked(name) {
+			continue
+		}
+		x, y := a.Offset(key)
+		layers = append(layers, layer{z: a.Z, x: x, y: y, frames: a.Frames})
+	}
+	if len(layers) == 0 {
+		return anim
+	}
+	// Accessories with the same z are drawn in the order they were put on
+	sort.SliceStable(layers, func(i, j int) bool { return layers[i].z < layers[j].z })
+
+	// The canvas covers every frame and every layer frame at its offset
+	left, top, right, bottom := 0, 0, 0, 0
+	for _, frame := range anim.Frames {
+		w, h := pixelSize(frame)
+		right, bottom = max(right, w), max(bottom, h)
+	}
+	for _, l := range layers {
+		for _, frame := range l.frames {
+			w, h := pixelSize(frame)
+			left, top = min(left, l.x), min(top, l.y)
+			right, bottom = max(right, l.x+w), max(bottom, l.y+h)
+		}
+	}
+
+	dressed := anim
+	dressed.Frames = make([]pet.Frame, len(anim.Frames))
+	for i, frame := range anim.Frames {
+		canvas := make([][]string, bottom-top)
+		for y := range canvas {
+			canvas[y] = make([]string, right-left)
+		}
+		for _, l := range layers {
+			if l.z < 0 {
+				drawPixels(canvas, l.frames[i%len(l.frames)], l.x-left, l.y-top)
+			}
+		}
+		drawPixels(canvas, frame, -left, -top)
+		for _, l := range layers {
+			if l.z >= 0 {
+				drawPixels(canvas, l.frames[i%len(l.frames)], l.x-left, l.y-top)
+			}
+		}
+		dressed.Frames[i] = pet.Frame{Pixels: canvas, MS: frame.MS}
+	}
+	return dressed
+}
+
+// pixelSize is the width and height of a pixel frame
+func pixelSize(frame pet.Frame) (w, h int) {
+	for _, row := range frame.Pixels {
+		w = max(w, len(row))
+	}
+	return w, len(frame.Pixels)
+}
+
+// drawPixels paints the opaque pixels of frame onto canvas at x, y
+func drawPixels(canvas [][]string, frame pet.Frame, x, y int) {
+	for dy, row := range frame.Pixels {
+		for dx, px := range row {
+			if !isTransparentPixel(px) {
+				canvas[y+dy][x+dx] = px
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package art
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+func TestDress(t *testing.T) {
+	r, g, b := "#FF0000", "#00FF00", "#0000FF"
+	p := &pet.Pet{
+		Config: pet.PetConfig{
+			Accessories: map[string]pet.AccessoryConfig{
+				// A hat above the frame, moved right for e2
+				"hat": {X: 0, Y: -1, Anchors: map[string][2]int{"e2": {1, -1}}, Frames: []pet.Frame{{Pixels: [][]string{{g}}}}},
+				// A backdrop behind the familiar, only showing where it is transparent
+				"cape":   {Z: -1, Frames: []pet.Frame{{Pixels: [][]string{{b, b}, {b, b}}}}},
+				"locked": {Evolution: 3, Frames: []pet.Frame{{Pixels: [][]string{{b}}}}},
+			},
+		},
+		State: pet.PetState{Evolution: 1, Wearing: []string{"hat", "cape", "locked", "missing"}},
+	}
+	anim := pet.AnimationConfig{Source: "pixel", Frames: []pet.Frame{
+		{Pixels: [][]string{{r, ""}, {r, r}}, MS: 100},
+		{Pixels: [][]string{{"", r}}},
+	}}
+
+	got := Dress(p, "default", anim)
+	want := []pet.Frame{
+		{Pixels: [][]string{{g, ""}, {r, b}, {r, r}}, MS: 100},
+		{Pixels: [][]string{{g, ""}, {b, r}, {b, b}}},
+	}
+	if !reflect.DeepEqual(got.Frames, want) {
+		t.Errorf("Dress() = %v, want %v", got.Frames, want)
+	}
+	if len(anim.Frames[0].Pixels) != 2 {
+		t.Error("Expected the animation's own frames to be left alone")
+	}
+
+	got = Dress(p, "e2:happy", anim)
+	if row := got.Frames[0].Pixels[0]; !reflect.DeepEqual(row, []string{"", g}) {
+		t.Errorf("Expected the e2 anchor to move the hat right, got %v", row)
+	}
+
+	p.State.Wearing = nil
+	if got := Dress(p, "default", anim); !reflect.DeepEqual(got, anim) {
+		t.Error("Expected no change without accessories")
+	}
+	inline := pet.AnimationConfig{Source: "inline", Frames: []pet.Frame{{Art: "cat"}}}
+	p.State.Wearing = []string{"hat"}
+	if got := Dress(p, "default", inline); !reflect.DeepEqual(got, inline) {
+		t.Error("Expected inline art to be left alone")
+	}
+}
@@ -0,0 +1,300 @@
+package art
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/term"
+)
+
+// Forever is the Passes of a loop that plays until it is interrupted
+const Forever = -1
+
+// Loop says how many times a Player goes through its frames
+type Loop struct {
+	Passes   int  // times through the frames, or Forever
+	PingPong bool // go forward then back: 0 1 2 1, 0 1 2 1, ... 0
+}
+
+// LoopOf is the loop anim asks for: Loops passes, forever for 0 or -1
+func LoopOf(anim pet.AnimationConfig) Loop {
+	loop := Loop{Passes: anim.Loops, PingPong: anim.PingPong}
+	if loop.Passes <= 0 {
+		loop.Passes = Forever
+	}
+	return loop
+}
+
+// Bounded cuts a forever loop to one pass, for output that has to end
+func (l Loop) Bounded() Loop {
+	if l.Passes == Forever {
+		l.Passes = 1
+	}
+	return l
+}
+
+// Player draws frames one over another in the same place on a terminal. The
+// cursor is hidden while it plays and shown again however playing ends.
+type Player struct {
+	Out io.Writer
+	// Sleep waits between frames. Nil waits in real time, waking early when
+	// the context is done or Resized fires. A recorder can pass a Sleep that
+	// only advances its own clock.
+	Sleep func(time.Duration)
+	// Size returns the terminal's columns and rows, zero when unknown. It is
+	// asked before every frame, so a resized terminal is drawn to its new size.
+	Size func() (cols, rows int)
+	// Resized fires when the terminal changes size, to redraw straight away
+	Resized <-chan os.Signal
+	// Cursor returns the row the cursor is on, 1 at the top, or zero when
+	// unknown. With Size it lets the player set the terminal's scroll region
+	// to the rows of the frame while it plays.
+	Cursor func() int
+	// Setup is written once before the first frame, e.g. images it places
+	Setup string
+}
+
+// Play draws frames in the order loop gives, showing frame i for delays[i],
+// until the loop ends or ctx is done, when it returns ctx's error. Frames do
+// not end in a newline. Room is made below the cursor for the tallest frame,
+// and frames taller than the terminal are cropped, so moving back to the top
+// of a frame never runs into the top of the screen. Where the cursor's row is
+// known the scroll region is held to those rows, and put back to the whole
+// screen however playing ends.
+func (p *Player) Play(ctx context.Context, frames []string, delays []time.Duration, loop Loop) error {
+	if len(frames) == 0 {
+		return nil
+	}
+	pass := pet.AnimationConfig{Frames: make([]pet.Frame, len(frames)), PingPong: loop.PingPong}.Pass()
+	total := len(pass) * max(loop.Passes, 1)
+	if loop.PingPong && len(frames) > 1 {
+		// End where the animation started
+		total++
+	}
+	forever := loop.Passes == Forever && len(frames) > 1
+	if len(frames) == 1 {
+		total = 1
+	}
+
+	region := false
+	fmt.Fprint(p.Out, "\033[?25l")
+	defer func() {
+		if region {
+			// Before the newline, which would scroll within the region
+			fmt.Fprint(p.Out, "\0337\033[r\0338")
+		}
+		fmt.Fprint(p.Out, "\n\033[0m\033[?25h")
+		flush(p.Out)
+	}()
+
+	// reserve makes room below the cursor, at the top of a frame, for the
+	// tallest frame at the terminal's current size and holds the scroll
+	// region to it
+	reserve := func() {
+		if region {
+			fmt.Fprint(p.Out, "\0337\033[r\0338")
+			region = false
+		}
+		cols, rows := p.size()
+		height := 0
+		for _, frame := range frames {
+			_, h := fit(frame, cols, rows)
+			height = max(height, h)
+		}
+		// Scroll now if the screen is too full for the tallest frame
+		if height > 1 {
+			fmt.Fprint(p.Out, strings.Repeat("\n", height-1))
+			fmt.Fprintf(p.Out, "\033[%dA", height-1)
+		}
+		if p.Cursor == nil || rows == 0 {
+			return
+		}
+		if top := p.Cursor(); top > 0 {
+			// Setting the region homes the cursor, so save it around that
+			fmt.Fprintf(p.Out, "\0337\033[%d;%dr\0338", top, min(top+height-1, rows))
+			region = true
+		}
+	}
+	reserve()
+	fmt.Fprint(p.Out, p.Setup)
+
+	shown := ""
+	draw := func(frame string, resized bool) {
+		cols, rows := p.size()
+		// The cursor is at the end of the last frame; rows are counted at the
+		// current width, as terminals rewrap lines when they are resized
+		if shown != "" {
+			fmt.Fprint(p.Out, "\r")
+			if _, h := fit(shown, cols, 0); h > 1 {
+				fmt.Fprintf(p.Out, "\033[%dA", h-1)
+			}
+		}
+		if resized {
+			fmt.Fprint(p.Out, "\033[J")
+			reserve()
+		}
+		fmt.Fprint(p.Out, "\033[J")
+		shown, _ = fit(frame, cols, rows)
+		fmt.Fprint(p.Out, shown)
+		flush(p.Out)
+	}
+
+	for i := 0; forever || i < total; i++ {
+		idx := pass[i%len(pass)]
+		draw(frames[idx], false)
+		if !forever && i == total-1 {
+			break
+		}
+		if err := p.wait(ctx, delays[idx], func() { draw(frames[idx], true) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// size is Size, or unknown when there is none
+func (p *Player) size() (int, int) {
+	if p.Size == nil {
+		return 0, 0
+	}
+	return p.Size()
+}
+
+// wait sleeps for d, calling redraw whenever the terminal is resized
+func (p *Player) wait(ctx context.Context, d time.Duration, redraw func()) error {
+	if p.Sleep != nil {
+		p.Sleep(d)
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		case <-p.Resized:
+			redraw()
+		}
+	}
+}
+
+// fit crops frame to the first rows screen rows, counting lines longer than
+// cols as the rows they wrap onto, and returns it with the rows it covers.
+// Zero cols or rows means no limit.
+func fit(frame string, cols, rows int) (string, int) {
+	lines := strings.Split(frame, "\n")
+	used := 0
+	for i, line := range lines {
+		n := 1
+		if w := term.VisibleWidth(line); cols > 0 && w > cols {
+			n = (w + cols - 1) / cols
+		}
+		if rows > 0 && used+n > rows && i > 0 {
+			return strings.Join(lines[:i], "\n"), used
+		}
+		used += n
+	}
+	return frame, used
+}
+
+// Play plays anim on stdout with bubble beside every frame, looping as loop
+// says, until it ends or ctx is done
+func Play(ctx context.Context, anim pet.AnimationConfig, bubble []string, loop Loop) error {
+	return playOn(ctx, os.Stdin, os.Stdout, anim, bubble, loop)
+}
+
+// playOn is Play on out, asking the terminal on in and out where the cursor is
+func playOn(ctx context.Context, in, out *os.File, anim pet.AnimationConfig, bubble []string, loop Loop) error {
+	p := &Player{Out: out}
+	if term.IsTTY(out) {
+		p.Size = func() (int, int) { return term.Width(out), term.Height(out) }
+		resized := make(chan os.Signal, 1)
+		term.NotifyResize(resized)
+		defer signal.Stop(resized)
+		p.Resized = resized
+		p.Cursor = func() int { return term.CursorRow(in, out) }
+	}
+
+	depth, protocol := term.ColorDepth(out), term.GraphicsProtocol(out)
+	cellW, cellH := term.CellSize(out)
+	_, rows := p.size()
+	setup, frames := renderFrames(anim, depth, protocol, cellW, cellH, rows)
+	p.Setup = setup
+	return p.Play(ctx, Compose(frames, bubble), frameDelays(anim), loop)
+}
+
+// PlayAnimation plays anim on stdout as it asks, a forever loop until Ctrl-C.
+// A forever loop plays once unless someone can be at the terminal to stop
+// it, so output to a file, a pipe or /dev/null, or a run without a terminal
+// on stdin, always ends.
+func PlayAnimation(anim pet.AnimationConfig) {
+	playAnimation(os.Stdin, os.Stdout, anim)
+}
+
+func playAnimation(in, out *os.File, anim pet.AnimationConfig) error {
+	loop := LoopOf(anim)
+	if !term.IsTTY(in) || !term.IsTTY(out) {
+		loop = loop.Bounded()
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return playOn(ctx, in, out, anim, nil, loop)
+}
+
+// playInterruptible is Play stopped by Ctrl-C, which leaves the terminal as
+// it was and lets the command carry on
+func playInterruptible(anim pet.AnimationConfig, bubble []string, loop Loop) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	Play(ctx, anim, bubble, loop)
+}
+
+// renderFrames draws each frame of anim as text, or for pixel art as images
+// placed by the returned setup when protocol allows and they fit in rows
+func renderFrames(anim pet.AnimationConfig, depth term.Depth, protocol string, cellW, cellH, rows int) (string, []string) {
+	frames := make([]string, len(anim.Frames))
+	if !anim.Pixel() {
+		for i, frame := range anim.Frames {
+			frames[i] = strings.TrimRight(frame.Art, "\n\r")
+		}
+		return "", frames
+	}
+	if upload, rendered, ok := graphicsFrames(anim.Frames, protocol, cellW, cellH); ok {
+		tallest := 0
+		for _, frame := range rendered {
+			tallest = max(tallest, strings.Count(frame, "\n")+1)
+		}
+		// An image cannot be cropped to the terminal; text can
+		if rows == 0 || tallest <= rows {
+			return upload, rendered
+		}
+	}
+	for i, frame := range anim.Frames {
+		frames[i] = strings.TrimRight(pixelText(frame, depth, protocol), "\n\r")
+	}
+	return "", frames
+}
+
+// frameDelays is how long each frame of anim shows: its ms, or 1/fps
+func frameDelays(anim pet.AnimationConfig) []time.Duration {
+	fps := anim.FPS
+	if fps <= 0 {
+		fps = 1
+	}
+	delays := make([]time.Duration, len(anim.Frames))
+	for i, frame := range anim.Frames {
+		delays[i] = time.Second / time.Duration(fps)
+		if frame.MS > 0 {
+			delays[i] = time.Duration(frame.MS) * time.Millisecond
+		}
+	}
+	return delays
+}
@@ -0,0 +1,155 @@
+package art
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+// drawn lists the frames a player drew, in order
+func drawn(out string) []string {
+	var frames []string
+	for _, part := range strings.Split(out, "\033[J")[1:] {
+		frames = append(frames, regexp.MustCompile(`\r|\n|\033\[[0-9;?]*[A-Za-z]`).ReplaceAllString(part, ""))
+	}
+	return frames
+}
+
+func TestPlayerLoops(t *testing.T) {
+	frames := []string{"a", "b", "c"}
+	delays := []time.Duration{time.Second, time.Second, time.Second}
+	tests := map[string]struct {
+		loop Loop
+		want string
+	}{
+		"once":      {Loop{Passes: 1}, "abc"},
+		"twice":     {Loop{Passes: 2}, "abcabc"},
+		"ping-pong": {Loop{Passes: 2, PingPong: true}, "abcbabcba"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var b bytes.Buffer
+			sleeps := 0
+			p := &Player{Out: &b, Sleep: func(time.Duration) { sleeps++ }}
+			if err := p.Play(context.Background(), frames, delays, tt.loop); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(drawn(b.String()), ""); got != tt.want {
+				t.Errorf("Drew %q, want %q", got, tt.want)
+			}
+			if sleeps != len(tt.want)-1 {
+				t.Errorf("Slept %d times, want %d", sleeps, len(tt.want)-1)
+			}
+		})
+	}
+}
+
+func TestPlayerForeverRestoresTerminal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var b bytes.Buffer
+	sleeps := 0
+	p := &Player{Out: &b, Sleep: func(time.Duration) {
+		if sleeps++; sleeps == 7 {
+			cancel()
+		}
+	}}
+	err := p.Play(ctx, []string{"a", "b"}, []time.Duration{time.Second, time.Second}, Loop{Passes: Forever})
+	if err != context.Canceled {
+		t.Errorf("Play() = %v, want context.Canceled", err)
+	}
+	if got := len(drawn(b.String())); got != 7 {
+		t.Errorf("Drew %d frames before the interrupt, want 7", got)
+	}
+	if !strings.HasSuffix(b.String(), "\033[0m\033[?25h") {
+		t.Errorf("Expected the cursor to be shown again, got %q", b.String())
+	}
+}
+
+func TestPlayerFitsTerminal(t *testing.T) {
+	var b bytes.Buffer
+	rows := 3
+	p := &Player{Out: &b, Sleep: func(time.Duration) { rows = 2 }, Size: func() (int, int) { return 4, rows }}
+	frames := []string{"1\n2\n3\n4\n5", "abcdef\nx"}
+	if err := p.Play(context.Background(), frames, []time.Duration{0, 0}, Loop{Passes: 1}); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	// Room for three rows, then back up from the three drawn, then the second
+	// frame's first line wraps onto both rows of the shrunk terminal
+	if !strings.HasPrefix(out, "\033[?25l\n\n\033[2A\033[J1\n2\n3\r\033[2A\033[Jabcdef\n") {
+		t.Errorf("Unexpected output %q", out)
+	}
+	if strings.Contains(out, "x") || strings.Contains(out, "4") {
+		t.Errorf("Expected lines past the terminal's height to be cropped, got %q", out)
+	}
+}
+
+func TestPlayerRedrawsOnResize(t *testing.T) {
+	resized := make(chan os.Signal, 1)
+	resized <- os.Interrupt
+	var b bytes.Buffer
+	p := &Player{Out: &b, Resized: resized}
+	if err := p.Play(context.Background(), []string{"a", "b"}, []time.Duration{10 * time.Millisecond, 0}, Loop{Passes: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(drawn(b.String()), ""); got != "aab" {
+		t.Errorf("Drew %q, want the first frame again after the resize", got)
+	}
+}
+
+func TestPlayerHoldsScrollRegion(t *testing.T) {
+	var b bytes.Buffer
+	p := &Player{
+		Out:    &b,
+		Sleep:  func(time.Duration) {},
+		Size:   func() (int, int) { return 80, 24 },
+		Cursor: func() int { return 20 },
+	}
+	if err := p.Play(context.Background(), []string{"a\nb\nc", "d"}, []time.Duration{0, 0}, Loop{Passes: 1}); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if !strings.HasPrefix(out, "\033[?25l\n\n\033[2A\0337\033[20;22r\0338") {
+		t.Errorf("Expected the region to cover rows 20-22, got %q", out)
+	}
+	if !strings.HasSuffix(out, "\0337\033[r\0338\n\033[0m\033[?25h") {
+		t.Errorf("Expected the region to be reset before the last newline, got %q", out)
+	}
+}
+
+func TestLoopOf(t *testing.T) {
+	for loops, want := range map[int]int{-1: Forever, 0: Forever, 1: 1, 25: 25} {
+		if got := LoopOf(pet.AnimationConfig{Loops: loops}).Passes; got != want {
+			t.Errorf("LoopOf(loops = %d) = %d passes, want %d", loops, got, want)
+		}
+	}
+	if got := LoopOf(pet.AnimationConfig{Loops: -1}).Bounded().Passes; got != 1 {
+		t.Errorf("Bounded() = %d passes, want 1", got)
+	}
+}
+
+func TestPlayAnimationEndsWithoutTerminal(t *testing.T) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	// /dev/null is a character device, but nobody is watching it
+	anim := pet.AnimationConfig{Loops: -1, Frames: []pet.Frame{{Art: "a", MS: 1}, {Art: "b", MS: 1}}}
+	done := make(chan error, 1)
+	go func() { done <- playAnimation(devNull, devNull, anim) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("playAnimation() = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a forever loop to play once to /dev/null and end")
+	}
+}
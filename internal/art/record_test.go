@@ -0,0 +1,91 @@
+package art
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/cast"
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/term"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden casts in testdata")
+
+// TestRecordGolden plays animations into a recorder and compares the escape
+// stream and timing with casts in testdata. Run with -update after an
+// intended change to the players.
+func TestRecordGolden(t *testing.T) {
+	tests := map[string]pet.AnimationConfig{
+		"wave": {
+			Source: "inline",
+			FPS:    4,
+			Loops:  2,
+			Frames: []pet.Frame{
+				{Art: "  o\n /|\\\n / \\\n"},
+				{Art: " \\o/\n  |\n / \\\n", MS: 500},
+			},
+		},
+		"blink": {
+			Source: "pixel",
+			FPS:    2,
+			Loops:  1,
+			Frames: []pet.Frame{
+				{Pixels: [][]string{{"#FF0000", ""}, {"", "#00FF00"}}},
+				{Pixels: [][]string{{"", "#FF0000"}, {"#00FF00", ""}}},
+			},
+		},
+	}
+
+	for name, anim := range tests {
+		t.Run(name, func(t *testing.T) {
+			var r cast.Recorder
+			PlayTo(&r, r.Sleep, anim, term.DepthTrue)
+			var got bytes.Buffer
+			if err := r.Encode(&got, cast.Header{Width: 80, Height: 24}); err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join("testdata", name+".cast")
+			if *update {
+				if err := os.WriteFile(path, got.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("Recording differs from %s:\n%s", path, got.String())
+			}
+
+			// The recording lasts as long as the animation would play
+			_, events, _ := cast.Decode(bytes.NewReader(want))
+			var total time.Duration
+			loops := max(anim.Loops, 1)
+			for i := 0; i < len(anim.Frames)*loops-1; i++ {
+				f := anim.Frames[i%len(anim.Frames)]
+				if f.MS > 0 {
+					total += time.Duration(f.MS) * time.Millisecond
+				} else {
+					total += time.Second / time.Duration(anim.FPS)
+				}
+			}
+			if last := events[len(events)-1].Time; last != total {
+				t.Errorf("Last event at %v, want %v", last, total)
+			}
+		})
+	}
+}
+
+func TestPlayToSingleFrame(t *testing.T) {
+	var b bytes.Buffer
+	PlayTo(&b, func(time.Duration) { t.Error("Expected no delay for one frame") }, pet.AnimationConfig{Frames: []pet.Frame{{Art: "=^.^=\n"}}}, term.DepthNone)
+	if b.String() != "=^.^=\n" {
+		t.Errorf("PlayTo() = %q", b.String())
+	}
+}
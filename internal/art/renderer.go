@@ -1,7 +1,9 @@
 package art
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -9,12 +11,12 @@ import (
 
 	"github.com/sethgrid/familiar/internal/conditions"
 	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/term"
 )
 
 // isTerminal checks if stdout is a terminal
 func isTerminal() bool {
-	fileInfo, _ := os.Stdout.Stat()
-	return (fileInfo.Mode() & os.ModeCharDevice) != 0
+	return term.IsTerminal(os.Stdout)
 }
 
 // ChooseAnimationKey selects the appropriate animation key based on conditions and evolution
@@ -109,36 +111,89 @@ func ChooseAnimationKey(conds map[conditions.Condition]bool, evolution int, anim
 }
 
 func GetStaticArt(p *pet.Pet, status conditions.DerivedStatus) string {
+	return GetStaticArtWithBubble(p, status, nil)
+}
+
+// GetStaticArtWithBubble is GetStaticArt with a speech bubble (see Bubble) drawn
+// to the right of the art, including while an animation plays
+func GetStaticArtWithBubble(p *pet.Pet, status conditions.DerivedStatus, bubble []string) string {
 	key := ChooseAnimationKey(status.Conditions, p.State.Evolution, p.Config.Animations)
 
 	// Try to get animation from config
 	if anim, exists := p.Config.Animations[key]; exists && len(anim.Frames) > 0 {
+		anim = p.ActivePalette().Apply(Dress(p, key, anim))
 		// Check if this is pixel art
-		if anim.Source == "pixel" {
+		if anim.Pixel() {
 			// For pixel art, render the first frame
 			if len(anim.Frames) > 0 {
 				rendered := RenderPixelArt(anim.Frames[0])
 				// If animation has multiple frames and animations are enabled, play animation
 				if len(anim.Frames) > 1 && p.Config.AllowAnsiAnimations && isTerminal() {
-					PlayPixelAnimation(anim)
+					playInterruptible(anim, bubble, LoopOf(anim).Bounded())
 					// Return empty string - animation already displayed the final frame
 					return ""
 				}
-				return rendered
+				return SideBySide(rendered, bubble)
 			}
 		} else {
 			// If animation has multiple frames and animations are enabled, play animation
 			if len(anim.Frames) > 1 && p.Config.AllowAnsiAnimations && isTerminal() {
-				PlayAnimation(anim)
+				playInterruptible(anim, bubble, LoopOf(anim).Bounded())
 				// Return empty string - animation already displayed the final frame
 				return ""
 			}
 			// Otherwise return first frame
-			return anim.Frames[0].Art
+			return SideBySide(anim.Frames[0].Art, bubble)
 		}
 	}
 
-	// Fallback to hardcoded art based on state (check in priority order)
+	return SideBySide(fallbackArt(p, status), bubble)
+}
+
+// PlainStaticArt is the first frame of the art GetStaticArt would show, as
+// plain text. It never plays an animation.
+func PlainStaticArt(p *pet.Pet, status conditions.DerivedStatus) string {
+	key := ChooseAnimationKey(status.Conditions, p.State.Evolution, p.Config.Animations)
+	if anim, exists := p.Config.Animations[key]; exists && len(anim.Frames) > 0 {
+		anim = p.ActivePalette().Apply(Dress(p, key, anim))
+		return PlainFrame(anim, anim.Frames[0])
+	}
+	return term.StripANSI(fallbackArt(p, status))
+}
+
+// PlainFrame renders a frame without escape sequences: pixel art is shaded
+// with ASCII and escapes are stripped from inline art
+func PlainFrame(anim pet.AnimationConfig, frame pet.Frame) string {
+	if anim.Pixel() {
+		return RenderPixelArtDepth(frame, term.DepthNone)
+	}
+	return term.StripANSI(frame.Art)
+}
+
+// ArtWidth returns the visible width of the art GetStaticArt would show, taking
+// the widest frame of an animation
+func ArtWidth(p *pet.Pet, status conditions.DerivedStatus) int {
+	key := ChooseAnimationKey(status.Conditions, p.State.Evolution, p.Config.Animations)
+	anim, exists := p.Config.Animations[key]
+	if !exists || len(anim.Frames) == 0 {
+		return Width(fallbackArt(p, status))
+	}
+	anim = p.ActivePalette().Apply(Dress(p, key, anim))
+
+	width := 0
+	for _, frame := range anim.Frames {
+		if anim.Pixel() {
+			width = max(width, Width(pixelText(frame, term.DepthNone, term.GraphicsProtocol(os.Stdout))))
+		} else {
+			width = max(width, Width(frame.Art))
+		}
+	}
+	return width
+}
+
+// fallbackArt returns hardcoded art based on state when no animation is configured
+func fallbackArt(p *pet.Pet, status conditions.DerivedStatus) string {
+	// Check in priority order
 	if status.Conditions[conditions.CondHasMessage] {
 		return getHasMessageCat()
 	}
@@ -162,121 +217,35 @@ func GetStaticArt(p *pet.Pet, status conditions.DerivedStatus) string {
 	return getDefaultCat()
 }
 
-// PlayAnimation plays an animation by cycling through frames directly to stdout
-func PlayAnimation(anim pet.AnimationConfig) {
-	if len(anim.Frames) == 0 || len(anim.Frames) == 1 {
-		return
-	}
-
-	// Calculate frame duration
-	fps := anim.FPS
-	if fps <= 0 {
-		fps = 1
-	}
-	frameDuration := time.Second / time.Duration(fps)
-
-	// Determine number of loops (limit to reasonable number)
-	loops := anim.Loops
-	if loops <= 0 {
-		loops = 3 // Default to 3 loops for demo
-	}
-	if loops > 10 {
-		loops = 10 // Cap at 10 loops max
-	}
-
-	totalFrames := len(anim.Frames) * loops
-
-	// Trim trailing newlines from frames and calculate their heights
-	trimmedFrames := make([]string, len(anim.Frames))
-	frameHeights := make([]int, len(anim.Frames))
-	maxLines := 0
-	for i, frame := range anim.Frames {
-		trimmed := strings.TrimRight(frame.Art, "\n\r")
-		trimmedFrames[i] = trimmed
-		lines := strings.Count(trimmed, "\n") + 1
-		frameHeights[i] = lines
-		if lines > maxLines {
-			maxLines = lines
+// PlayTo plays anim into out as it would appear on stdout, calling sleep for
+// each delay between frames. A recorder can pass a sleep that only advances
+// its own clock. An animation of one frame is written once, and one that
+// loops forever plays one pass. Pixel art is drawn with half-blocks, or
+// braille when that is the graphics mode.
+func PlayTo(out io.Writer, sleep func(time.Duration), anim pet.AnimationConfig, depth term.Depth) {
+	// Images cannot be recorded; braille is text and can
+	protocol := term.GraphicsBlocks
+	if term.GraphicsMode() == term.GraphicsBraille {
+		protocol = term.GraphicsBraille
+	}
+	if len(anim.Frames) == 1 {
+		if anim.Pixel() {
+			fmt.Fprintln(out, strings.TrimRight(pixelText(anim.Frames[0], depth, protocol), "\n"))
+		} else {
+			fmt.Fprintln(out, strings.TrimRight(anim.Frames[0].Art, "\n"))
 		}
+		return
 	}
+	_, frames := renderFrames(anim, depth, protocol, 0, 0, 0)
+	p := &Player{Out: out, Sleep: sleep}
+	p.Play(context.Background(), frames, frameDelays(anim), LoopOf(anim).Bounded())
+}
 
-	// Hide cursor
-	fmt.Fprint(os.Stdout, "\033[?25l")
-
-	// Print buffer lines BELOW to ensure animation has room without scrolling
-	// This prevents the animation from overwriting status text when terminal is near bottom
-	bufferLines := maxLines + 10 // Extra buffer below animation
-	for j := 0; j < bufferLines; j++ {
-		fmt.Fprint(os.Stdout, "\n")
-	}
-	// Move back up to animation start position (we're now at the start of the buffer area)
-	if bufferLines > 0 {
-		fmt.Fprintf(os.Stdout, "\033[%dA", bufferLines)
-	}
-
-	for i := 0; i < totalFrames; i++ {
-		frameIdx := i % len(anim.Frames)
-		trimmedArt := trimmedFrames[frameIdx]
-		frame := anim.Frames[frameIdx]
-
-		// Use frame-specific duration if available, otherwise use calculated duration
-		duration := frameDuration
-		if frame.MS > 0 {
-			duration = time.Duration(frame.MS) * time.Millisecond
-		}
-
-		// For frames after the first, move back to start
-		// After printing a trimmed frame (no trailing newline), cursor is at the END of the last line
-		// To get back to the start of the first line:
-		// 1. Move to start of current line (\r) - now at start of last line
-		// 2. Move up by (frameHeight - 1) to get to start of first line
-		if i > 0 {
-			prevFrameIdx := (i - 1) % len(anim.Frames)
-			prevFrameHeight := frameHeights[prevFrameIdx]
-			// Move to start of current line (we're at end of last line of previous frame)
-			fmt.Fprint(os.Stdout, "\r")
-			// Move up by (frameHeight - 1) to get to start of first line
-			// If frame is 3 lines, we're at start of line 3, move up 2 to get to line 1
-			if prevFrameHeight > 1 {
-				fmt.Fprintf(os.Stdout, "\033[%dA", prevFrameHeight-1)
-			}
-		}
-
-		// Clear exactly maxLines worth of space from current position
-		// We're at the start position (line 1), so clear downward
-		for j := 0; j < maxLines; j++ {
-			fmt.Fprint(os.Stdout, "\033[2K") // Clear entire line
-			if j < maxLines-1 {
-				fmt.Fprint(os.Stdout, "\033[1B") // Move down exactly 1 line
-			}
-		}
-
-		// Move back up to start - we moved down (maxLines-1) times, so move back up that much
-		// After this, we're back at the start position (line 1)
-		if maxLines > 1 {
-			fmt.Fprintf(os.Stdout, "\033[%dA", maxLines-1)
-		}
-
-		// Print the frame
-		// After printing (no trailing newline), cursor will be at the END of the last line
-		fmt.Fprint(os.Stdout, trimmedArt)
-		os.Stdout.Sync()
-
-		// Add delay between frames (except for last frame)
-		if i < totalFrames-1 {
-			time.Sleep(duration)
-		}
+// flush syncs out when it is a file, so each frame reaches the terminal whole
+func flush(out io.Writer) {
+	if f, ok := out.(*os.File); ok {
+		f.Sync()
 	}
-
-	// After animation, move cursor to below the art
-	// We're currently at the start of the line AFTER the last frame
-	// The last frame was maxLines tall, so we're already positioned correctly
-	// Just ensure cursor is visible and add a newline for spacing
-	fmt.Fprint(os.Stdout, "\n")
-
-	// Show cursor
-	fmt.Fprint(os.Stdout, "\033[?25h")
-	os.Stdout.Sync()
 }
 
 func getDefaultCat() string {
@@ -343,13 +312,49 @@ func isTransparentPixel(pixel string) bool {
 	return false
 }
 
-// RenderPixelArt renders a pixel art frame to a string using ANSI color codes
-// Uses half-block characters (▀ ▄) for 2 pixels per character cell
+// RenderPixelArt renders a pixel art frame for stdout: as an image when the
+// terminal has a graphics protocol, otherwise with half-blocks at its colour depth
 func RenderPixelArt(frame pet.Frame) string {
+	if protocol := term.GraphicsProtocol(os.Stdout); protocol != term.GraphicsBlocks {
+		cellW, cellH := term.CellSize(os.Stdout)
+		if upload, rendered, ok := graphicsFrames([]pet.Frame{frame}, protocol, cellW, cellH); ok {
+			return upload + rendered[0]
+		}
+	}
+	return pixelText(frame, term.ColorDepth(os.Stdout), term.GraphicsProtocol(os.Stdout))
+}
+
+// pixelText renders a pixel art frame as text: braille when protocol is
+// braille, otherwise half-blocks
+func pixelText(frame pet.Frame, depth term.Depth, protocol string) string {
+	if protocol == term.GraphicsBraille {
+		return RenderBraille(frame, brailleConfig, depth)
+	}
+	return RenderPixelArtDepth(frame, depth)
+}
+
+// asciiRamp shades pixels by luminance when there is no colour, darkest first.
+// It has no space, so dark outlines stay visible.
+const asciiRamp = ".:-=+*#%@"
+
+// RenderPixelArtDepth renders a pixel art frame to a string using ANSI color codes
+// Uses half-block characters (▀ ▄) for 2 pixels per character cell. Colours are
+// reduced to depth; without colour each cell becomes an ASCII character
+// chosen by the luminance of its pixels.
+func RenderPixelArtDepth(frame pet.Frame, depth term.Depth) string {
 	if len(frame.Pixels) == 0 {
 		return ""
 	}
 
+	fg := func(hex string) string {
+		r, g, b := hexToRGB(hex)
+		return "\033[" + term.FG(r, g, b, depth) + "m"
+	}
+	bg := func(hex string) string {
+		r, g, b := hexToRGB(hex)
+		return "\033[" + term.BG(r, g, b, depth) + "m"
+	}
+
 	var result strings.Builder
 	height := len(frame.Pixels)
 
@@ -385,25 +390,20 @@ func RenderPixelArt(frame pet.Frame) string {
 			if topTransparent && bottomTransparent {
 				// Both transparent - use space
 				result.WriteString(" ")
+			} else if depth == term.DepthNone {
+				result.WriteByte(asciiShade(topColor, bottomColor, topTransparent, bottomTransparent))
 			} else if topTransparent {
 				// Only bottom - use lower half block
-				r, g, b := hexToRGB(bottomColor)
-				result.WriteString(fmt.Sprintf("\033[38;2;%d;%d;%dm▄\033[0m", r, g, b))
+				result.WriteString(fg(bottomColor) + "▄\033[0m")
 			} else if bottomTransparent {
 				// Only top - use upper half block
-				r, g, b := hexToRGB(topColor)
-				result.WriteString(fmt.Sprintf("\033[38;2;%d;%d;%dm▀\033[0m", r, g, b))
+				result.WriteString(fg(topColor) + "▀\033[0m")
 			} else if topColor == bottomColor {
 				// Same color - use full block
-				r, g, b := hexToRGB(topColor)
-				result.WriteString(fmt.Sprintf("\033[38;2;%d;%d;%dm█\033[0m", r, g, b))
+				result.WriteString(fg(topColor) + "█\033[0m")
 			} else {
-				// Different colors - use upper half block with foreground and background
-				topR, topG, topB := hexToRGB(topColor)
-				bottomR, bottomG, bottomB := hexToRGB(bottomColor)
-				// Set both foreground (top) and background (bottom) colors
-				result.WriteString(fmt.Sprintf("\033[38;2;%d;%d;%dm\033[48;2;%d;%d;%dm▀\033[0m",
-					topR, topG, topB, bottomR, bottomG, bottomB))
+				// Different colors - use upper half block with foreground (top) and background (bottom)
+				result.WriteString(fg(topColor) + bg(bottomColor) + "▀\033[0m")
 			}
 		}
 
@@ -415,105 +415,21 @@ func RenderPixelArt(frame pet.Frame) string {
 	return result.String()
 }
 
-// PlayPixelAnimation plays a pixel art animation
-func PlayPixelAnimation(anim pet.AnimationConfig) {
-	if len(anim.Frames) == 0 || len(anim.Frames) == 1 {
-		return
-	}
-
-	// Calculate frame duration
-	fps := anim.FPS
-	if fps <= 0 {
-		fps = 1
-	}
-	frameDuration := time.Second / time.Duration(fps)
-
-	// Determine number of loops
-	loops := anim.Loops
-	if loops <= 0 {
-		loops = 3
-	}
-	if loops > 10 {
-		loops = 10
-	}
-
-	totalFrames := len(anim.Frames) * loops
-
-	// Render all frames to strings
-	renderedFrames := make([]string, len(anim.Frames))
-	frameHeights := make([]int, len(anim.Frames))
-	maxLines := 0
-	for i, frame := range anim.Frames {
-		rendered := RenderPixelArt(frame)
-		renderedFrames[i] = strings.TrimRight(rendered, "\n\r")
-		lines := strings.Count(renderedFrames[i], "\n") + 1
-		frameHeights[i] = lines
-		if lines > maxLines {
-			maxLines = lines
+// asciiShade picks the asciiRamp character for the opaque pixels of a cell
+func asciiShade(top, bottom string, topTransparent, bottomTransparent bool) byte {
+	total, n := 0, 0
+	for _, px := range []struct {
+		color       string
+		transparent bool
+	}{{top, topTransparent}, {bottom, bottomTransparent}} {
+		if px.transparent {
+			continue
 		}
+		r, g, b := hexToRGB(px.color)
+		total += term.Luminance(r, g, b)
+		n++
 	}
-
-	// Hide cursor
-	fmt.Fprint(os.Stdout, "\033[?25l")
-
-	// Print buffer lines BELOW to ensure animation has room without scrolling
-	// This prevents the animation from overwriting status text when terminal is near bottom
-	bufferLines := maxLines + 10 // Extra buffer below animation
-	for j := 0; j < bufferLines; j++ {
-		fmt.Fprint(os.Stdout, "\n")
-	}
-	// Move back up to animation start position (we're now at the start of the buffer area)
-	if bufferLines > 0 {
-		fmt.Fprintf(os.Stdout, "\033[%dA", bufferLines)
-	}
-
-	for i := 0; i < totalFrames; i++ {
-		frameIdx := i % len(anim.Frames)
-		renderedArt := renderedFrames[frameIdx]
-		frame := anim.Frames[frameIdx]
-
-		// Use frame-specific duration if available
-		duration := frameDuration
-		if frame.MS > 0 {
-			duration = time.Duration(frame.MS) * time.Millisecond
-		}
-
-		// Move back to start for frames after the first
-		if i > 0 {
-			prevFrameIdx := (i - 1) % len(anim.Frames)
-			prevFrameHeight := frameHeights[prevFrameIdx]
-			fmt.Fprint(os.Stdout, "\r")
-			if prevFrameHeight > 1 {
-				fmt.Fprintf(os.Stdout, "\033[%dA", prevFrameHeight-1)
-			}
-		}
-
-		// Clear space
-		for j := 0; j < maxLines; j++ {
-			fmt.Fprint(os.Stdout, "\033[2K")
-			if j < maxLines-1 {
-				fmt.Fprint(os.Stdout, "\033[1B")
-			}
-		}
-
-		if maxLines > 1 {
-			fmt.Fprintf(os.Stdout, "\033[%dA", maxLines-1)
-		}
-
-		// Print the frame
-		fmt.Fprint(os.Stdout, renderedArt)
-		os.Stdout.Sync()
-
-		// Add delay between frames
-		if i < totalFrames-1 {
-			time.Sleep(duration)
-		}
-	}
-
-	// Move cursor below and show it
-	fmt.Fprint(os.Stdout, "\n")
-	fmt.Fprint(os.Stdout, "\033[?25h")
-	os.Stdout.Sync()
+	return asciiRamp[total/n*len(asciiRamp)/256]
 }
 
 // colorCode converts a hex color to ANSI 24-bit color code
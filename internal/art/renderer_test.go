@@ -0,0 +1,29 @@
+package art
+
+import (
+	"testing"
+
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/term"
+)
+
+func TestRenderPixelArtDepth(t *testing.T) {
+	frame := pet.Frame{Pixels: [][]string{
+		{"#ff0000", "#ffffff", "", "#000000"},
+		{"#ff0000", "", "", "#000000"},
+	}}
+	tests := []struct {
+		depth term.Depth
+		want  string
+	}{
+		{term.DepthTrue, "\033[38;2;255;0;0m█\033[0m\033[38;2;255;255;255m▀\033[0m \033[38;2;0;0;0m█\033[0m"},
+		{term.Depth256, "\033[38;5;196m█\033[0m\033[38;5;231m▀\033[0m \033[38;5;16m█\033[0m"},
+		{term.Depth16, "\033[91m█\033[0m\033[97m▀\033[0m \033[30m█\033[0m"},
+		{term.DepthNone, "-@ ."},
+	}
+	for _, tt := range tests {
+		if got := RenderPixelArtDepth(frame, tt.depth); got != tt.want {
+			t.Errorf("RenderPixelArtDepth at %s = %q, want %q", tt.depth, got, tt.want)
+		}
+	}
+}
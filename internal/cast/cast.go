@@ -0,0 +1,115 @@
+// Package cast records terminal output as asciicast v2 files, the format
+// played by asciinema. Time is virtual: a Recorder advances its clock when
+// asked to sleep, so a recording takes no longer to make than to write.
+package cast
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Version is the asciicast format version written and read
+const Version = 2
+
+// Header is the first line of a cast
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event is output written at a time since the start of the recording
+type Event struct {
+	Time time.Duration
+	Data string
+}
+
+// Recorder is an io.Writer that keeps everything written to it as output
+// events. Writes at the same moment are merged into one event.
+type Recorder struct {
+	now    time.Duration
+	events []Event
+}
+
+// Write records p as output at the current time. Each \n is recorded as
+// \r\n, as a terminal's line discipline (ONLCR) would pass it on, so lines
+// start at the left margin when the cast is played.
+func (r *Recorder) Write(p []byte) (int, error) {
+	data := strings.ReplaceAll(string(p), "\n", "\r\n")
+	if n := len(r.events); n > 0 && r.events[n-1].Time == r.now {
+		r.events[n-1].Data += data
+	} else {
+		r.events = append(r.events, Event{Time: r.now, Data: data})
+	}
+	return len(p), nil
+}
+
+// Sleep advances the recording clock by d without waiting
+func (r *Recorder) Sleep(d time.Duration) {
+	r.now += d
+}
+
+// Events returns the output recorded so far
+func (r *Recorder) Events() []Event {
+	return r.events
+}
+
+// Encode writes the recording as an asciicast v2 file: the header, then one
+// [seconds, "o", data] line per event
+func (r *Recorder) Encode(w io.Writer, h Header) error {
+	h.Version = Version
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(h); err != nil {
+		return fmt.Errorf("failed to write cast header: %w", err)
+	}
+	for _, e := range r.events {
+		data, err := json.Marshal(e.Data)
+		if err != nil {
+			return fmt.Errorf("failed to write cast event: %w", err)
+		}
+		fmt.Fprintf(bw, "[%.6f, \"o\", %s]\n", e.Time.Seconds(), data)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write cast: %w", err)
+	}
+	return nil
+}
+
+// Decode reads an asciicast v2 file, keeping its output events
+func Decode(rd io.Reader) (Header, []Event, error) {
+	var h Header
+	dec := json.NewDecoder(rd)
+	if err := dec.Decode(&h); err != nil {
+		return h, nil, fmt.Errorf("failed to read cast header: %w", err)
+	}
+	if h.Version != Version {
+		return h, nil, fmt.Errorf("unsupported asciicast version %d", h.Version)
+	}
+
+	var events []Event
+	for dec.More() {
+		var raw [3]any
+		if err := dec.Decode(&raw); err != nil {
+			return h, nil, fmt.Errorf("failed to read cast event: %w", err)
+		}
+		seconds, ok1 := raw[0].(float64)
+		kind, ok2 := raw[1].(string)
+		data, ok3 := raw[2].(string)
+		if !ok1 || !ok2 || !ok3 {
+			return h, nil, fmt.Errorf("invalid cast event %v", raw)
+		}
+		if kind == "o" {
+			events = append(events, Event{Time: time.Duration(seconds * float64(time.Second)).Round(time.Microsecond), Data: data})
+		}
+	}
+	return h, events, nil
+}
@@ -0,0 +1,49 @@
+package cast
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	var r Recorder
+	fmt.Fprint(&r, "\033[?25l")
+	fmt.Fprint(&r, "a")
+	r.Sleep(250 * time.Millisecond)
+	fmt.Fprint(&r, "b\n")
+
+	var b bytes.Buffer
+	if err := r.Encode(&b, Header{Width: 80, Height: 24}); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"version":2,"width":80,"height":24}
+[0.000000, "o", "\u001b[?25la"]
+[0.250000, "o", "b\r\n"]
+`
+	if b.String() != want {
+		t.Errorf("Encode() =\n%s\nwant\n%s", b.String(), want)
+	}
+
+	h, events, err := Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Width != 80 || len(events) != 2 || events[1].Time != 250*time.Millisecond || events[0].Data != "\033[?25la" {
+		t.Errorf("Decode() = %+v, %+v", h, events)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, bad := range []string{
+		`{"version":1,"width":80,"height":24}`,
+		`{"version":2,"width":80,"height":24}` + "\n[0.1, \"o\"]",
+		"not json",
+	} {
+		if _, _, err := Decode(strings.NewReader(bad)); err == nil {
+			t.Errorf("Expected an error decoding %q", bad)
+		}
+	}
+}
@@ -32,7 +32,7 @@ func DeriveStatus(p *pet.Pet, now time.Time, health int) DerivedStatus {
 	var allOrdered []Condition
 
 	// Priority 1: has-message
-	if p.State.Message != "" {
+	if len(p.PendingMessages(now)) > 0 {
 		conds[CondHasMessage] = true
 		allOrdered = append(allOrdered, CondHasMessage)
 	}
@@ -0,0 +1,80 @@
+package duration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse parses a duration like time.ParseDuration, but also accepts
+// day ("3d") and week ("2w") units, alone or combined ("1w2d12h").
+// Durations below zero are rejected.
+func Parse(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+	// Any part below zero, such as the -2h of "1d-2h", makes no sense
+	if strings.Contains(s, "-") {
+		return 0, fmt.Errorf("negative duration '%s'", s)
+	}
+
+	var total time.Duration
+	rest := s
+	for _, unit := range []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"w", 7 * 24 * time.Hour},
+		{"d", 24 * time.Hour},
+	} {
+		idx := strings.Index(rest, unit.suffix)
+		if idx < 0 {
+			continue
+		}
+		n, err := strconv.Atoi(rest[:idx])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s'", s)
+		}
+		total += time.Duration(n) * unit.size
+		rest = rest[idx+1:]
+	}
+
+	if rest != "" {
+		d, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s'", s)
+		}
+		total += d
+	}
+	return total, nil
+}
+
+// ParseDue resolves a due time relative to now. It accepts a duration ("2h",
+// "1d"), a time of day ("15:30", today or tomorrow if already past), a date
+// ("2006-01-02", local midnight), a date and time ("2006-01-02 15:30") or RFC 3339.
+func ParseDue(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if d, err := Parse(s); err == nil {
+		return now.Add(d), nil
+	}
+
+	if t, err := time.ParseInLocation("15:04", s, now.Location()); err == nil {
+		due := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+		if !due.After(now) {
+			due = due.AddDate(0, 0, 1)
+		}
+		return due, nil
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s' (use a duration like 2h, a time like 15:30, or a date like 2006-01-02)", s)
+}
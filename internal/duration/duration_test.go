@@ -0,0 +1,66 @@
+package duration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "90m", want: 90 * time.Minute},
+		{in: "3d", want: 72 * time.Hour},
+		{in: "1w", want: 7 * 24 * time.Hour},
+		{in: "1w2d12h", want: 9*24*time.Hour + 12*time.Hour},
+		{in: "", wantErr: true},
+		{in: "soon", wantErr: true},
+		{in: "xd", wantErr: true},
+		{in: "0s", want: 0},
+		{in: "-1h", wantErr: true},
+		{in: "-2d", wantErr: true},
+		{in: "1d-2h", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseDue(t *testing.T) {
+	now := time.Date(2026, 3, 11, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "2h", want: now.Add(2 * time.Hour)},
+		{in: "1d", want: now.Add(24 * time.Hour)},
+		{in: "15:30", want: time.Date(2026, 3, 11, 15, 30, 0, 0, time.UTC)},
+		{in: "09:00", want: time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC)},
+		{in: "2026-03-20", want: time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)},
+		{in: "2026-03-20 08:15", want: time.Date(2026, 3, 20, 8, 15, 0, 0, time.UTC)},
+		{in: "2026-03-20T08:15:00Z", want: time.Date(2026, 3, 20, 8, 15, 0, 0, time.UTC)},
+		{in: "later", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDue(tt.in, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDue(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseDue(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
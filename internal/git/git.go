@@ -0,0 +1,68 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// run executes git in dir and returns its trimmed stdout
+func run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), msg)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// RevParse resolves a revision (e.g. "HEAD") to a full commit hash
+func RevParse(dir, rev string) (string, error) {
+	return run(dir, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+}
+
+// HasCommit reports whether the commit exists in the repository
+func HasCommit(dir, rev string) bool {
+	_, err := RevParse(dir, rev)
+	return err == nil
+}
+
+// ChangedFiles lists files changed between two commits, relative to dir
+func ChangedFiles(dir, from, to string) ([]string, error) {
+	out, err := run(dir, "diff", "--name-only", "--relative", from, to)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// HooksDir returns the absolute hooks directory of the repository containing
+// dir, honouring core.hooksPath
+func HooksDir(dir string) (string, error) {
+	out, err := run(dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(out) {
+		out = filepath.Join(dir, out)
+	}
+	return filepath.Clean(out), nil
+}
+
+// Config returns a git config value, or "" if it is not set
+func Config(dir, key string) string {
+	out, _ := run(dir, "config", "--get", key)
+	return out
+}
@@ -0,0 +1,267 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Names are the git hooks familiar manages
+var Names = []string{"post-merge", "post-checkout", "post-commit", "pre-commit", "pre-push"}
+
+const (
+	blockStart = "# >>> familiar >>>"
+	blockEnd   = "# <<< familiar <<<"
+
+	// chainedSuffix is appended to an existing non-shell hook that familiar
+	// moved aside so it can run it after its own block
+	chainedSuffix = ".pre-familiar"
+)
+
+// State describes how a hook compares to what familiar would install
+type State string
+
+const (
+	StateInstalled     State = "installed"
+	StateMissing       State = "missing"
+	StateNotInstalled  State = "not installed"  // a hook exists without familiar's block
+	StateOutdated      State = "outdated"       // familiar's block differs from the current one
+	StateNotExecutable State = "not executable" // git will silently skip the hook
+)
+
+// Status is the result of inspecting one hook
+type Status struct {
+	Name    string
+	Path    string
+	State   State
+	Chained bool // an existing non-shell hook runs after familiar's block
+	Changed bool // Install or Uninstall modified the hook
+}
+
+// Block returns the managed snippet installed into a hook
+func Block(name string) string {
+	return strings.Join([]string{
+		blockStart,
+		"# Managed by 'familiar admin hooks'. Changes inside this block are overwritten.",
+		"if command -v familiar >/dev/null 2>&1; then",
+		fmt.Sprintf("  familiar admin hooks run %s \"$@\" || exit $?", name),
+		"fi",
+		blockEnd,
+	}, "\n") + "\n"
+}
+
+// Install writes familiar's block into each hook in dir. Existing shell hooks
+// keep their contents with the block inserted after the shebang; hooks in other
+// languages are moved aside and chained. Re-running refreshes the block.
+func Install(dir string, names []string) ([]Status, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	var statuses []Status
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		content, err := readHook(path)
+		if err != nil {
+			return statuses, err
+		}
+
+		chained := false
+		switch {
+		case content == "":
+			content = "#!/bin/sh\n" + Block(name)
+		case hasBlock(content):
+			content = replaceBlock(content, Block(name))
+			chained = fileExists(path + chainedSuffix)
+		case isShellScript(content):
+			content = insertBlock(content, Block(name))
+		default:
+			// Not a shell script: move it aside and call it from a new shell hook,
+			// unless that would overwrite a hook moved aside before
+			if fileExists(path + chainedSuffix) {
+				return statuses, fmt.Errorf("cannot chain existing %s hook: %s already exists; merge or remove one of them", name, path+chainedSuffix)
+			}
+			if err := os.Rename(path, path+chainedSuffix); err != nil {
+				return statuses, fmt.Errorf("failed to move existing %s hook: %w", name, err)
+			}
+			content = "#!/bin/sh\n" + Block(name) + chainLine(name)
+			chained = true
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+			return statuses, fmt.Errorf("failed to write %s hook: %w", name, err)
+		}
+		// WriteFile keeps the mode of an existing file
+		if err := os.Chmod(path, 0755); err != nil {
+			return statuses, fmt.Errorf("failed to make %s hook executable: %w", name, err)
+		}
+		statuses = append(statuses, Status{Name: name, Path: path, State: StateInstalled, Chained: chained, Changed: true})
+	}
+	return statuses, nil
+}
+
+// Uninstall removes familiar's block from each hook, deleting hooks that only
+// contained the block and restoring hooks that were moved aside
+func Uninstall(dir string, names []string) ([]Status, error) {
+	var statuses []Status
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		content, err := readHook(path)
+		if err != nil {
+			return statuses, err
+		}
+		if !hasBlock(content) {
+			state := StateMissing
+			if content != "" {
+				state = StateNotInstalled
+			}
+			statuses = append(statuses, Status{Name: name, Path: path, State: state})
+			continue
+		}
+
+		chained := path + chainedSuffix
+		if fileExists(chained) {
+			if err := os.Rename(chained, path); err != nil {
+				return statuses, fmt.Errorf("failed to restore %s hook: %w", name, err)
+			}
+		} else {
+			rest := replaceBlock(content, "")
+			if isEmptyScript(rest) {
+				if err := os.Remove(path); err != nil {
+					return statuses, fmt.Errorf("failed to remove %s hook: %w", name, err)
+				}
+			} else if err := os.WriteFile(path, []byte(rest), 0755); err != nil {
+				return statuses, fmt.Errorf("failed to write %s hook: %w", name, err)
+			}
+		}
+		statuses = append(statuses, Status{Name: name, Path: path, State: StateMissing, Changed: true})
+	}
+	return statuses, nil
+}
+
+// Inspect reports the state of each hook without changing anything
+func Inspect(dir string, names []string) ([]Status, error) {
+	var statuses []Status
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		content, err := readHook(path)
+		if err != nil {
+			return statuses, err
+		}
+
+		st := Status{Name: name, Path: path, Chained: fileExists(path + chainedSuffix)}
+		switch {
+		case content == "":
+			st.State = StateMissing
+		case !hasBlock(content):
+			st.State = StateNotInstalled
+		case extractBlock(content) != Block(name):
+			st.State = StateOutdated
+		case !isExecutable(path):
+			st.State = StateNotExecutable
+		default:
+			st.State = StateInstalled
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
+
+func readHook(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read hook %s: %w", path, err)
+	}
+	return string(data), nil
+}
+
+func chainLine(name string) string {
+	return fmt.Sprintf("exec \"$(dirname \"$0\")/%s%s\" \"$@\"\n", name, chainedSuffix)
+}
+
+func hasBlock(content string) bool {
+	return strings.Contains(content, blockStart) && strings.Contains(content, blockEnd)
+}
+
+// extractBlock returns the managed block including its markers and trailing newline
+func extractBlock(content string) string {
+	start := strings.Index(content, blockStart)
+	end := strings.Index(content, blockEnd)
+	if start < 0 || end < start {
+		return ""
+	}
+	end += len(blockEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return content[start:end]
+}
+
+func replaceBlock(content, block string) string {
+	old := extractBlock(content)
+	if old == "" {
+		return content
+	}
+	return strings.Replace(content, old, block, 1)
+}
+
+// insertBlock places the block directly after the shebang so it runs even if
+// the existing script exits early
+func insertBlock(content, block string) string {
+	if !strings.HasPrefix(content, "#!") {
+		return "#!/bin/sh\n" + block + content
+	}
+	nl := strings.Index(content, "\n")
+	if nl < 0 {
+		return content + "\n" + block
+	}
+	return content[:nl+1] + block + content[nl+1:]
+}
+
+// isShellScript reports whether the hook can have a POSIX shell block inserted
+func isShellScript(content string) bool {
+	if !strings.HasPrefix(content, "#!") {
+		return true // git runs shebang-less hooks with sh
+	}
+	line := content
+	if nl := strings.Index(content, "\n"); nl >= 0 {
+		line = content[:nl]
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return false
+	}
+	interp := filepath.Base(fields[0])
+	if interp == "env" && len(fields) > 1 {
+		interp = fields[1]
+	}
+	switch interp {
+	case "sh", "bash", "dash", "zsh", "ksh":
+		return true
+	}
+	return false
+}
+
+func isEmptyScript(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#!") {
+			return false
+		}
+	}
+	return true
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&0111 != 0
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
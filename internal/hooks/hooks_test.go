@@ -0,0 +1,126 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallFreshIsIdempotent(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Install(dir, Names); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	first, _ := os.ReadFile(filepath.Join(dir, "post-merge"))
+	if _, err := Install(dir, Names); err != nil {
+		t.Fatalf("second Install failed: %v", err)
+	}
+	second, _ := os.ReadFile(filepath.Join(dir, "post-merge"))
+
+	if string(first) != string(second) {
+		t.Errorf("Install is not idempotent:\n%s\n---\n%s", first, second)
+	}
+	if strings.Count(string(second), blockStart) != 1 {
+		t.Errorf("Expected exactly one managed block, got:\n%s", second)
+	}
+
+	statuses, err := Inspect(dir, Names)
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	for _, st := range statuses {
+		if st.State != StateInstalled {
+			t.Errorf("%s: expected installed, got %s", st.Name, st.State)
+		}
+	}
+}
+
+func TestInstallChainsExistingHooks(t *testing.T) {
+	dir := t.TempDir()
+	shellHook := "#!/bin/bash\necho existing\nexit 0\n"
+	pythonHook := "#!/usr/bin/env python3\nprint('hi')\n"
+	os.WriteFile(filepath.Join(dir, "post-commit"), []byte(shellHook), 0755)
+	os.WriteFile(filepath.Join(dir, "pre-push"), []byte(pythonHook), 0755)
+
+	if _, err := Install(dir, Names); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	// Shell hook keeps its body, with the block ahead of the early exit
+	data, _ := os.ReadFile(filepath.Join(dir, "post-commit"))
+	content := string(data)
+	if !strings.HasPrefix(content, "#!/bin/bash\n"+blockStart) || !strings.Contains(content, "echo existing") {
+		t.Errorf("Unexpected chained shell hook:\n%s", content)
+	}
+
+	// Python hook is moved aside and executed after the block
+	moved, err := os.ReadFile(filepath.Join(dir, "pre-push"+chainedSuffix))
+	if err != nil || string(moved) != pythonHook {
+		t.Fatalf("Expected python hook to be moved aside, got %q (%v)", moved, err)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "pre-push"))
+	if !strings.Contains(string(data), "pre-push"+chainedSuffix) {
+		t.Errorf("Expected pre-push to exec the chained hook:\n%s", data)
+	}
+
+	if _, err := Uninstall(dir, Names); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "post-commit"))
+	if string(data) != shellHook {
+		t.Errorf("Expected shell hook restored, got:\n%s", data)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, "pre-push"))
+	if string(data) != pythonHook {
+		t.Errorf("Expected python hook restored, got:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "post-merge")); !os.IsNotExist(err) {
+		t.Errorf("Expected hook created by familiar to be removed, got %v", err)
+	}
+}
+
+func TestInstallKeepsChainedHook(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pre-push")
+	os.WriteFile(path, []byte("#!/usr/bin/env python3\nprint('first')\n"), 0755)
+	if _, err := Install(dir, []string{"pre-push"}); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	// Another tool replaces the hook, then familiar is installed again
+	second := "#!/usr/bin/env python3\nprint('second')\n"
+	os.WriteFile(path, []byte(second), 0755)
+	if _, err := Install(dir, []string{"pre-push"}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("Expected Install to refuse to overwrite the chained hook, got %v", err)
+	}
+	moved, _ := os.ReadFile(path + chainedSuffix)
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(moved), "first") || string(data) != second {
+		t.Errorf("Expected both hooks left as they were, got %q and %q", moved, data)
+	}
+}
+
+func TestInspectReportsDrift(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Install(dir, []string{"post-merge", "post-commit"}); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+
+	path := filepath.Join(dir, "post-merge")
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, []byte(strings.Replace(string(data), "|| exit $?", "", 1)), 0755)
+	os.Chmod(filepath.Join(dir, "post-commit"), 0644)
+
+	statuses, err := Inspect(dir, []string{"post-merge", "post-commit", "pre-push"})
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	want := []State{StateOutdated, StateNotExecutable, StateMissing}
+	for i, st := range statuses {
+		if st.State != want[i] {
+			t.Errorf("%s: expected %s, got %s", st.Name, want[i], st.State)
+		}
+	}
+}
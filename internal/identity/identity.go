@@ -0,0 +1,96 @@
+package identity
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+)
+
+// User identifies the person running familiar
+type User struct {
+	Name  string
+	Email string
+}
+
+// Current returns the git identity (user.name / user.email) of the working
+// directory, falling back to the operating system user when git has none.
+func Current() User {
+	u := User{
+		Name:  gitConfig("user.name"),
+		Email: gitConfig("user.email"),
+	}
+	if u.Name == "" {
+		u.Name = osUser()
+	}
+	return u
+}
+
+// String formats the user as "Name <email>", or just whichever part is known
+func (u User) String() string {
+	switch {
+	case u.Name != "" && u.Email != "":
+		return u.Name + " <" + u.Email + ">"
+	case u.Email != "":
+		return u.Email
+	default:
+		return u.Name
+	}
+}
+
+// Key is a stable identifier for the user: the git email if set, otherwise the OS user
+func (u User) Key() string {
+	if u.Email != "" {
+		return strings.ToLower(u.Email)
+	}
+	if u.Name != "" {
+		return u.Name
+	}
+	return "unknown"
+}
+
+func gitConfig(key string) string {
+	out, err := exec.Command("git", "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func osUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
+
+// RecentContributors returns the lower-cased emails of commit authors in the
+// current repository since the given time, most active first
+func RecentContributors(since time.Time) []string {
+	out, err := exec.Command("git", "log", "--since="+since.Format(time.RFC3339), "--format=%ae").Output()
+	if err != nil {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	var order []string
+	for _, line := range strings.Split(string(out), "\n") {
+		email := strings.ToLower(strings.TrimSpace(line))
+		if email == "" {
+			continue
+		}
+		if counts[email] == 0 {
+			order = append(order, email)
+		}
+		counts[email]++
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return counts[order[i]] > counts[order[j]]
+	})
+	return order
+}
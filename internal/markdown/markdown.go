@@ -0,0 +1,364 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/sethgrid/familiar/internal/term"
+)
+
+// Options controls rendering
+type Options struct {
+	// Width wraps paragraphs and list items; 0 disables wrapping
+	Width int
+	// Color enables ANSI styles and OSC 8 hyperlinks. Without it, markup is
+	// removed and links are written as "text (url)".
+	Color bool
+}
+
+const (
+	styleReset     = "\033[0m"
+	styleBold      = "\033[1m"
+	styleItalic    = "\033[3m"
+	styleUnderline = "\033[4m"
+	styleDim       = "\033[2m"
+	styleCode      = "\033[36m" // cyan
+)
+
+var (
+	headingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	listRe    = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	ruleRe    = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+)
+
+// Render renders a subset of Markdown for the terminal: ATX headings, bold,
+// italics, code spans, fenced code blocks, lists, block quotes, rules and links
+func Render(src string, opts Options) string {
+	r := renderer{opts: opts}
+	r.render(strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n"))
+	return strings.TrimRight(r.out.String(), "\n")
+}
+
+type renderer struct {
+	opts Options
+	out  strings.Builder
+
+	para      []string // pending paragraph lines
+	paraFirst string   // prefix of the first line of the pending paragraph
+	paraRest  string   // prefix of continuation lines
+	blank     bool     // a blank line separates the next block
+}
+
+func (r *renderer) render(lines []string) {
+	inFence := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			r.flush()
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			r.writeLine("    " + r.style(styleDim, strings.TrimRight(line, " \t")))
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			r.flush()
+			r.blank = true
+		case headingRe.MatchString(trimmed):
+			r.flush()
+			m := headingRe.FindStringSubmatch(trimmed)
+			text := r.inline(m[2])
+			if r.opts.Color {
+				style := styleBold
+				if len(m[1]) == 1 {
+					style += styleUnderline
+				}
+				text = style + term.StripANSI(text) + styleReset
+			}
+			r.writeLine(text)
+		case ruleRe.MatchString(trimmed):
+			r.flush()
+			width := r.opts.Width
+			if width <= 0 || width > 40 {
+				width = 40
+			}
+			if r.opts.Color {
+				r.writeLine(r.style(styleDim, strings.Repeat("─", width)))
+			} else {
+				r.writeLine(strings.Repeat("-", width))
+			}
+		case listRe.MatchString(line):
+			r.flush()
+			m := listRe.FindStringSubmatch(line)
+			indent := strings.Repeat("  ", len(strings.ReplaceAll(m[1], "\t", "  "))/2)
+			marker := m[2]
+			if marker == "-" || marker == "*" || marker == "+" {
+				marker = "-"
+				if r.opts.Color {
+					marker = "•"
+				}
+			}
+			r.para = []string{m[3]}
+			r.paraFirst = indent + marker + " "
+			r.paraRest = indent + strings.Repeat(" ", term.VisibleWidth(marker)+1)
+		case strings.HasPrefix(trimmed, ">"):
+			quote := "> "
+			if r.opts.Color {
+				quote = r.style(styleDim, "│") + " "
+			}
+			if len(r.para) == 0 || r.paraFirst != quote {
+				r.flush()
+				r.paraFirst, r.paraRest = quote, quote
+			}
+			r.para = append(r.para, strings.TrimSpace(strings.TrimPrefix(trimmed, ">")))
+		default:
+			// Continuation of the current paragraph, list item or quote
+			r.para = append(r.para, trimmed)
+		}
+	}
+	r.flush()
+}
+
+// flush wraps and writes the pending paragraph
+func (r *renderer) flush() {
+	if len(r.para) == 0 {
+		return
+	}
+	words := r.words(strings.Join(r.para, " "))
+	r.para = nil
+
+	first, rest := r.paraFirst, r.paraRest
+	r.paraFirst, r.paraRest = "", ""
+
+	prefix := first
+	var line []string
+	lineWidth := 0
+	emit := func() {
+		r.writeLine(prefix + strings.Join(line, " "))
+		prefix = rest
+		line, lineWidth = nil, 0
+	}
+	for _, w := range words {
+		ww := term.VisibleWidth(w)
+		limit := r.opts.Width - term.VisibleWidth(prefix)
+		if r.opts.Width > 0 && len(line) > 0 && lineWidth+1+ww > limit {
+			emit()
+		}
+		if len(line) > 0 {
+			lineWidth++
+		}
+		line = append(line, w)
+		lineWidth += ww
+	}
+	if len(line) > 0 {
+		emit()
+	}
+}
+
+func (r *renderer) writeLine(s string) {
+	if r.blank && r.out.Len() > 0 {
+		r.out.WriteString("\n")
+	}
+	r.blank = false
+	r.out.WriteString(s)
+	r.out.WriteString("\n")
+}
+
+func (r *renderer) style(code, s string) string {
+	if !r.opts.Color || s == "" {
+		return s
+	}
+	return code + s + styleReset
+}
+
+// inline renders inline markup without wrapping
+func (r *renderer) inline(s string) string {
+	return strings.Join(r.words(s), " ")
+}
+
+// words renders inline markup and splits the result into words. Styles are
+// applied per word so that wrapping never leaves a style open across lines.
+func (r *renderer) words(s string) []string {
+	var words []string
+	var word strings.Builder
+	finish := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+
+	spans := parseInline(s)
+	for j, sp := range spans {
+		// Without hyperlinks, write the target after the link text
+		lastOfLink := sp.url != "" && (j+1 == len(spans) || spans[j+1].url != sp.url)
+		if !r.opts.Color && lastOfLink && sp.url != strings.TrimSpace(sp.text) {
+			sp.text += " (" + sp.url + ")"
+		}
+		for i, field := range strings.Split(sp.text, " ") {
+			if i > 0 {
+				finish()
+			}
+			if field != "" {
+				word.WriteString(r.renderSpan(sp, field))
+			}
+		}
+	}
+	finish()
+	return words
+}
+
+func (r *renderer) renderSpan(sp span, text string) string {
+	if !r.opts.Color {
+		return text
+	}
+	var style string
+	if sp.bold {
+		style += styleBold
+	}
+	if sp.italic {
+		style += styleItalic
+	}
+	if sp.code {
+		style += styleCode
+	}
+	if sp.url != "" {
+		style += styleUnderline
+	}
+	if style != "" {
+		text = style + text + styleReset
+	}
+	if sp.url != "" {
+		// OSC 8 hyperlink
+		text = "\033]8;;" + sp.url + "\033\\" + text + "\033]8;;\033\\"
+	}
+	return text
+}
+
+// span is a run of text with uniform inline style
+type span struct {
+	text   string
+	bold   bool
+	italic bool
+	code   bool
+	url    string
+}
+
+// parseInline splits a line into styled spans. Unmatched markers are kept as text.
+func parseInline(s string) []span {
+	var spans []span
+	var cur strings.Builder
+	bold, italic := false, false
+
+	push := func(sp span) {
+		if sp.text != "" {
+			spans = append(spans, sp)
+		}
+	}
+	flush := func() {
+		push(span{text: cur.String(), bold: bold, italic: italic})
+		cur.Reset()
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_[]()#+-.!>", s[i+1]) >= 0:
+			cur.WriteByte(s[i+1])
+			i++
+		case c == '`':
+			end := strings.IndexByte(s[i+1:], '`')
+			if end < 0 {
+				cur.WriteByte(c)
+				continue
+			}
+			flush()
+			push(span{text: s[i+1 : i+1+end], bold: bold, italic: italic, code: true})
+			i += end + 1
+		case (c == '*' || c == '_') && i+1 < len(s) && s[i+1] == c:
+			if bold && !canClose(s, i) || !bold && !(canOpen(s, i, 2) && hasCloser(s, s[i:i+2], i+3)) {
+				cur.WriteString(s[i : i+2])
+				i++
+				continue
+			}
+			flush()
+			bold = !bold
+			i++
+		case c == '*' || (c == '_' && (i == 0 || s[i-1] == ' ' || italic)):
+			if italic && !canClose(s, i) || !italic && !(canOpen(s, i, 1) && hasCloser(s, s[i:i+1], i+2)) {
+				cur.WriteByte(c)
+				continue
+			}
+			flush()
+			italic = !italic
+		case c == '[':
+			text, url, n := parseLink(s[i:])
+			if n == 0 {
+				cur.WriteByte(c)
+				continue
+			}
+			flush()
+			for _, inner := range parseInline(text) {
+				inner.bold = inner.bold || bold
+				inner.italic = inner.italic || italic
+				inner.url = url
+				push(inner)
+			}
+			i += n - 1
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+
+	return spans
+}
+
+// canOpen reports whether the n markers at s[i] can open emphasis: text
+// must follow them without a space, so "2 * 3 * 4" stays as written
+func canOpen(s string, i, n int) bool {
+	return i+n < len(s) && s[i+n] != ' ' && s[i+n] != '\t'
+}
+
+// canClose reports whether the marker at s[i] can close emphasis: text must
+// come right before it
+func canClose(s string, i int) bool {
+	return i > 0 && s[i-1] != ' ' && s[i-1] != '\t'
+}
+
+// hasCloser reports whether marker appears at or after from where it can close
+func hasCloser(s, marker string, from int) bool {
+	for from < len(s) {
+		j := strings.Index(s[from:], marker)
+		if j < 0 {
+			return false
+		}
+		if canClose(s, from+j) {
+			return true
+		}
+		from += j + 1
+	}
+	return false
+}
+
+// parseLink parses "[text](url)" at the start of s, returning the number of bytes consumed
+func parseLink(s string) (text, url string, n int) {
+	closeText := strings.Index(s, "](")
+	if closeText < 0 {
+		return "", "", 0
+	}
+	closeURL := strings.IndexByte(s[closeText+2:], ')')
+	if closeURL < 0 {
+		return "", "", 0
+	}
+	text = s[1:closeText]
+	url = strings.TrimSpace(s[closeText+2 : closeText+2+closeURL])
+	if url == "" || strings.ContainsAny(url, " \t") {
+		return "", "", 0
+	}
+	return text, url, closeText + 2 + closeURL + 1
+}
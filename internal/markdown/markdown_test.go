@@ -0,0 +1,92 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sethgrid/familiar/internal/term"
+)
+
+func TestRenderPlain(t *testing.T) {
+	src := `# Deploy freeze
+
+Deploys are **paused** until *Friday*. See the [runbook](https://example.com/runbook)
+and run ` + "`make verify`" + ` first.
+
+- first item
+- second item that is long enough to wrap onto the next line
+  with a continuation
+1. numbered
+
+> quoted text
+
+` + "```" + `
+go test ./...
+` + "```"
+
+	want := `Deploy freeze
+
+Deploys are paused until Friday. See the runbook
+(https://example.com/runbook) and run make verify
+first.
+
+- first item
+- second item that is long enough to wrap onto the
+  next line with a continuation
+1. numbered
+
+> quoted text
+
+    go test ./...`
+
+	if got := Render(src, Options{Width: 50}); got != want {
+		t.Errorf("Render plain:\n%s\n\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderColor(t *testing.T) {
+	got := Render("**bold** and [link](https://example.com) and `code`", Options{Color: true})
+
+	if !strings.Contains(got, styleBold+"bold"+styleReset) {
+		t.Errorf("Expected bold styling, got %q", got)
+	}
+	if !strings.Contains(got, "\033]8;;https://example.com\033\\") {
+		t.Errorf("Expected OSC 8 hyperlink, got %q", got)
+	}
+	if !strings.Contains(got, styleCode+"code"+styleReset) {
+		t.Errorf("Expected code styling, got %q", got)
+	}
+	if plain := term.StripANSI(got); plain != "bold and link and code" {
+		t.Errorf("Visible text = %q", plain)
+	}
+}
+
+func TestRenderWrapsByVisibleWidth(t *testing.T) {
+	src := "**aaaa** **bbbb** **cccc** **dddd**"
+	for _, line := range strings.Split(Render(src, Options{Width: 10, Color: true}), "\n") {
+		if w := term.VisibleWidth(line); w > 10 {
+			t.Errorf("Line %q is %d columns wide", line, w)
+		}
+	}
+}
+
+func TestInlineLiterals(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"snake_case_name", "snake_case_name"},
+		{"2 * 3 = 6", "2 * 3 = 6"},
+		{`\*not italic\*`, "*not italic*"},
+		{"[not a link]", "[not a link]"},
+		{"_italic_ word", "italic word"},
+		{"2 * 3 * 4", "2 * 3 * 4"},
+		{"a ** b ** c", "a ** b ** c"},
+		{"*a * b*", "a * b"},
+	}
+	for _, tt := range tests {
+		if got := Render(tt.in, Options{}); got != tt.want {
+			t.Errorf("Render(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
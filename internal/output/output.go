@@ -0,0 +1,131 @@
+// Package output writes command results as versioned JSON or YAML documents
+// for scripts. The document shapes are described by schema.json.
+package output
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sethgrid/familiar/internal/pet"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the version of the documents. It changes only when a field
+// is removed or changes meaning; adding fields keeps the version.
+const SchemaVersion = 1
+
+// Schema is the JSON Schema describing every document
+//
+//go:embed schema.json
+var Schema []byte
+
+// Format is an --output format
+type Format string
+
+const (
+	Text Format = "text" // the human-readable output, not a document
+	JSON Format = "json"
+	YAML Format = "yaml"
+)
+
+// ParseFormat validates an --output value
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case Text, JSON, YAML:
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid output format '%s' (expected text, json or yaml)", s)
+}
+
+// Document is the envelope of every result: the data for Kind, or an error
+type Document struct {
+	SchemaVersion int    `json:"schemaVersion" yaml:"schemaVersion"`
+	Kind          string `json:"kind" yaml:"kind"`
+	Data          any    `json:"data,omitempty" yaml:"data,omitempty"`
+	Error         *Error `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Write writes data as a document of the given kind
+func Write(w io.Writer, format Format, kind string, data any) error {
+	return encode(w, format, Document{SchemaVersion: SchemaVersion, Kind: kind, Data: data})
+}
+
+// WriteError writes err as an error document
+func WriteError(w io.Writer, format Format, err error) error {
+	return encode(w, format, Document{
+		SchemaVersion: SchemaVersion,
+		Kind:          KindError,
+		Error:         &Error{Code: CodeOf(err), Message: err.Error()},
+	})
+}
+
+func encode(w io.Writer, format Format, doc Document) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(doc)
+	case YAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("output format '%s' has no document encoding", format)
+}
+
+// Error codes. Scripts should branch on the code, not the message.
+const (
+	CodeUsage      = "usage"       // bad arguments or flags
+	CodeNoFamiliar = "no-familiar" // no familiar here or globally
+	CodeExists     = "exists"      // a familiar or schedule already exists
+	CodeNotFound   = "not-found"   // no message, reminder, schedule or animation matches
+	CodeAmbiguous  = "ambiguous"   // an id prefix or implicit target matches several
+	CodeState      = "state"       // the familiar's state does not allow the action, e.g. stone
+	CodeGateClosed = "gate-closed" // 'familiar gate' found blocking messages
+	CodeFailed     = "failed"      // anything else, such as I/O errors
+)
+
+// codedError attaches an error code to an error
+type codedError struct {
+	code string
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// WithCode attaches an error code, reported by CodeOf
+func WithCode(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{code: code, err: err}
+}
+
+// Errorf is fmt.Errorf with an error code
+func Errorf(code, format string, args ...any) error {
+	return WithCode(code, fmt.Errorf(format, args...))
+}
+
+// CodeOf returns the code attached to err, CodeFailed if there is none
+func CodeOf(err error) string {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	var lookup *pet.LookupError
+	if errors.As(err, &lookup) {
+		if lookup.Ambiguous {
+			return CodeAmbiguous
+		}
+		return CodeNotFound
+	}
+	return CodeFailed
+}
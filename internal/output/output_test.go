@@ -0,0 +1,204 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/conditions"
+	"github.com/sethgrid/familiar/internal/pet"
+	"gopkg.in/yaml.v3"
+)
+
+func TestWrite(t *testing.T) {
+	before := Stats{Health: 50, Hunger: 40, Happiness: 50, Energy: 60, Evolution: 1}
+	after := Stats{Health: 60, Hunger: 20, Happiness: 60, Energy: 60, Evolution: 1}
+	a := Action{Action: "feed", Familiar: "Pix", Notes: []string{"Fed your familiar!"}}
+	a.SetStats(before, after)
+
+	var b bytes.Buffer
+	if err := Write(&b, JSON, KindAction, a); err != nil {
+		t.Fatalf("Write JSON: %v", err)
+	}
+	var doc struct {
+		SchemaVersion int    `json:"schemaVersion"`
+		Kind          string `json:"kind"`
+		Data          Action `json:"data"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", b.String(), err)
+	}
+	if doc.SchemaVersion != SchemaVersion || doc.Kind != KindAction {
+		t.Errorf("Unexpected envelope: %+v", doc)
+	}
+	if doc.Data.Delta == nil || doc.Data.Delta.Hunger != -20 || doc.Data.Delta.Health != 10 {
+		t.Errorf("Unexpected delta: %+v", doc.Data.Delta)
+	}
+
+	b.Reset()
+	if err := Write(&b, YAML, KindAction, a); err != nil {
+		t.Fatalf("Write YAML: %v", err)
+	}
+	var generic map[string]any
+	if err := yaml.Unmarshal(b.Bytes(), &generic); err != nil {
+		t.Fatalf("Invalid YAML %q: %v", b.String(), err)
+	}
+	data, _ := generic["data"].(map[string]any)
+	if generic["kind"] != KindAction || data["familiar"] != "Pix" {
+		t.Errorf("Unexpected YAML document:\n%s", b.String())
+	}
+
+	if err := Write(&b, Text, KindAction, a); err == nil {
+		t.Error("Expected an error writing a text document")
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	var b bytes.Buffer
+	err := fmt.Errorf("failed to load familiar: %w", Errorf(CodeNoFamiliar, "no familiar found"))
+	if err := WriteError(&b, JSON, err); err != nil {
+		t.Fatal(err)
+	}
+	var doc Document
+	if err := json.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Kind != KindError || doc.Error == nil || doc.Error.Code != CodeNoFamiliar || doc.Data != nil {
+		t.Errorf("Unexpected error document: %s", b.String())
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{errors.New("disk full"), CodeFailed},
+		{WithCode(CodeState, errors.New("stone")), CodeState},
+		{fmt.Errorf("wrapped: %w", WithCode(CodeExists, errors.New("exists"))), CodeExists},
+		{&pet.LookupError{Kind: "message", ID: "ab"}, CodeNotFound},
+		{&pet.LookupError{Kind: "reminder", ID: "a", Ambiguous: true}, CodeAmbiguous},
+	}
+	for _, tt := range tests {
+		if got := CodeOf(tt.err); got != tt.want {
+			t.Errorf("CodeOf(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+	if WithCode(CodeFailed, nil) != nil {
+		t.Error("Expected WithCode(nil) to be nil")
+	}
+}
+
+func TestNewStatus(t *testing.T) {
+	now := time.Now()
+	p := &pet.Pet{Config: pet.PetConfig{Name: "Pix"}, State: pet.PetState{Hunger: 10, Happiness: 90, Energy: 80}}
+	p.State.AddMessage(pet.Message{Text: "deploy", CreatedAt: now})
+	s := NewStatus(p, conditions.DeriveStatus(p, now, 80), now, "/\\_/\\\n( o.o )\n")
+
+	if s.Name != "Pix" || len(s.Messages) != 1 || s.Messages[0].Acknowledged {
+		t.Errorf("Unexpected status: %+v", s)
+	}
+	if len(s.Art) != 2 || s.Reminders == nil || s.Conditions == nil {
+		t.Errorf("Expected art lines and empty, non-nil lists: %+v", s)
+	}
+	b, _ := json.Marshal(s)
+	if strings.Contains(string(b), "sleepUntil") || strings.Contains(string(b), "lastFed") {
+		t.Errorf("Expected zero times to be omitted: %s", b)
+	}
+}
+
+// TestSchemaMatchesTypes keeps schema.json in step with the Go types: every
+// kind has a definition, and each definition lists exactly the type's fields,
+// requiring those that are never omitted
+func TestSchemaMatchesTypes(t *testing.T) {
+	var schema struct {
+		Properties struct {
+			Kind struct {
+				Enum []string `json:"enum"`
+			} `json:"kind"`
+		} `json:"properties"`
+		Defs map[string]struct {
+			Required   []string                   `json:"required"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatalf("Invalid schema.json: %v", err)
+	}
+
+	wantKinds := []string{KindError}
+	for kind := range kinds {
+		wantKinds = append(wantKinds, kind)
+	}
+	sort.Strings(wantKinds)
+	gotKinds := append([]string(nil), schema.Properties.Kind.Enum...)
+	sort.Strings(gotKinds)
+	if !reflect.DeepEqual(gotKinds, wantKinds) {
+		t.Errorf("Schema kinds %v, want %v", gotKinds, wantKinds)
+	}
+	for kind, data := range kinds {
+		if !strings.Contains(string(Schema), fmt.Sprintf(`"const": %q } } }, "then": { "properties": { "data": { "$ref": "#/$defs/%s" }`, kind, reflect.TypeOf(data).Name())) {
+			t.Errorf("Kind %s does not reference $defs/%s", kind, reflect.TypeOf(data).Name())
+		}
+	}
+
+	types := map[string]reflect.Type{"Error": reflect.TypeOf(Error{})}
+	var collect func(reflect.Type)
+	collect = func(typ reflect.Type) {
+		for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || typ.PkgPath() != reflect.TypeOf(Error{}).PkgPath() {
+			return
+		}
+		types[typ.Name()] = typ
+		for i := 0; i < typ.NumField(); i++ {
+			collect(typ.Field(i).Type)
+		}
+	}
+	for _, data := range kinds {
+		collect(reflect.TypeOf(data))
+	}
+
+	for name, typ := range types {
+		def, ok := schema.Defs[name]
+		if !ok {
+			t.Errorf("schema.json has no definition for %s", name)
+			continue
+		}
+		var fields, required []string
+		for i := 0; i < typ.NumField(); i++ {
+			tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")
+			fields = append(fields, tag[0])
+			if len(tag) == 1 {
+				required = append(required, tag[0])
+			}
+		}
+		var props []string
+		for prop := range def.Properties {
+			props = append(props, prop)
+		}
+		sort.Strings(fields)
+		sort.Strings(required)
+		sort.Strings(props)
+		gotRequired := append([]string(nil), def.Required...)
+		sort.Strings(gotRequired)
+		if !reflect.DeepEqual(props, fields) {
+			t.Errorf("%s: schema properties %v, want %v", name, props, fields)
+		}
+		if !reflect.DeepEqual(gotRequired, required) {
+			t.Errorf("%s: schema requires %v, want %v", name, gotRequired, required)
+		}
+	}
+	for name := range schema.Defs {
+		if _, ok := types[name]; !ok {
+			t.Errorf("schema.json defines %s, which no document uses", name)
+		}
+	}
+}
@@ -0,0 +1,458 @@
+package output
+
+import (
+	"strings"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/conditions"
+	"github.com/sethgrid/familiar/internal/health"
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/prompt"
+)
+
+// Document kinds
+const (
+	KindError        = "error"
+	KindVersion      = "version"
+	KindStatus       = "status"
+	KindAction       = "action"
+	KindPrompt       = "prompt"
+	KindMessages     = "messages"
+	KindMessageStats = "message-stats"
+	KindReminders    = "reminders"
+	KindSchedules    = "schedules"
+	KindWatches      = "watches"
+	KindHooks        = "hooks"
+	KindGate         = "gate"
+	KindDoctor       = "doctor"
+	KindArt          = "art"
+	KindArtStates    = "art-states"
+	KindWardrobe     = "wardrobe"
+	KindPalettes     = "palettes"
+)
+
+// kinds maps each kind to the type of its data, for the schema test
+var kinds = map[string]any{
+	KindVersion:      Version{},
+	KindStatus:       Status{},
+	KindAction:       Action{},
+	KindPrompt:       Prompt{},
+	KindMessages:     Messages{},
+	KindMessageStats: MessageStats{},
+	KindReminders:    Reminders{},
+	KindSchedules:    Schedules{},
+	KindWatches:      Watches{},
+	KindHooks:        Hooks{},
+	KindGate:         Gate{},
+	KindDoctor:       Doctor{},
+	KindArt:          Art{},
+	KindArtStates:    ArtStates{},
+	KindWardrobe:     Wardrobe{},
+	KindPalettes:     Palettes{},
+}
+
+// Error is the body of an error document
+type Error struct {
+	Code    string `json:"code" yaml:"code"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// Version is the data of 'familiar --version'
+type Version struct {
+	Version       string `json:"version" yaml:"version"`
+	SchemaVersion int    `json:"schemaVersion" yaml:"schemaVersion"`
+}
+
+// Stats are the familiar's needs and derived health, 0-100. Hunger is
+// inverted: 0 is full.
+type Stats struct {
+	Health    int `json:"health" yaml:"health"`
+	Hunger    int `json:"hunger" yaml:"hunger"`
+	Happiness int `json:"happiness" yaml:"happiness"`
+	Energy    int `json:"energy" yaml:"energy"`
+	Evolution int `json:"evolution" yaml:"evolution"`
+}
+
+// NewStats reads a familiar's stats, computing its health
+func NewStats(p *pet.Pet) Stats {
+	return Stats{
+		Health:    health.ComputeHealth(p.State.Hunger, p.State.Happiness, p.State.Energy, health.ComputationMode(p.Config.HealthComputation)),
+		Hunger:    p.State.Hunger,
+		Happiness: p.State.Happiness,
+		Energy:    p.State.Energy,
+		Evolution: p.State.Evolution,
+	}
+}
+
+// Sub returns the change from before to s
+func (s Stats) Sub(before Stats) Stats {
+	return Stats{
+		Health:    s.Health - before.Health,
+		Hunger:    s.Hunger - before.Hunger,
+		Happiness: s.Happiness - before.Happiness,
+		Energy:    s.Energy - before.Energy,
+		Evolution: s.Evolution - before.Evolution,
+	}
+}
+
+// Status is the data of 'familiar status'
+type Status struct {
+	Name        string     `json:"name" yaml:"name"`
+	Type        string     `json:"type,omitempty" yaml:"type,omitempty"`
+	Condition   string     `json:"condition" yaml:"condition"`
+	Conditions  []string   `json:"conditions" yaml:"conditions"`
+	Stats       Stats      `json:"stats" yaml:"stats"`
+	Asleep      bool       `json:"asleep" yaml:"asleep"`
+	SleepUntil  time.Time  `json:"sleepUntil,omitzero" yaml:"sleepUntil,omitempty"`
+	Stone       bool       `json:"stone" yaml:"stone"`
+	Infirm      bool       `json:"infirm" yaml:"infirm"`
+	LastFed     time.Time  `json:"lastFed,omitzero" yaml:"lastFed,omitempty"`
+	LastPlayed  time.Time  `json:"lastPlayed,omitzero" yaml:"lastPlayed,omitempty"`
+	LastVisited time.Time  `json:"lastVisited,omitzero" yaml:"lastVisited,omitempty"`
+	Messages    []Message  `json:"messages" yaml:"messages"`   // unacknowledged by the current user
+	Reminders   []Reminder `json:"reminders" yaml:"reminders"` // due
+	Art         []string   `json:"art" yaml:"art"`             // the current frame as plain text
+}
+
+// NewStatus describes a familiar and its derived status. art is the plain
+// text of the current frame.
+func NewStatus(p *pet.Pet, status conditions.DerivedStatus, now time.Time, art string) Status {
+	s := Status{
+		Name:        DisplayName(p),
+		Type:        p.Config.PetType,
+		Condition:   string(status.Primary),
+		Conditions:  []string{},
+		Stats:       NewStats(p),
+		Asleep:      p.State.IsAsleep,
+		Stone:       p.State.IsStone,
+		Infirm:      p.State.IsInfirm,
+		LastFed:     p.State.LastFed,
+		LastPlayed:  p.State.LastPlayed,
+		LastVisited: p.State.LastVisited,
+		Messages:    NewMessages(p, p.PendingMessages(now)),
+		Reminders:   NewReminders(p.DueReminders(now), now),
+		Art:         Lines(art),
+	}
+	s.Stats.Health = status.Health
+	if p.State.IsAsleep {
+		s.SleepUntil = p.State.SleepUntil
+	}
+	for _, c := range status.AllOrdered {
+		s.Conditions = append(s.Conditions, string(c))
+	}
+	return s
+}
+
+// DisplayName is the familiar's name, honouring a rename in its state
+func DisplayName(p *pet.Pet) string {
+	if p.State.NameOverride != "" {
+		return p.State.NameOverride
+	}
+	return p.Config.Name
+}
+
+// Lines splits text into lines, dropping a trailing newline
+func Lines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(text, "\n")
+}
+
+// Action is the data of commands that change the familiar, such as feed,
+// play, acknowledge or message add. Before, After and Delta are set when the
+// command can change the familiar's stats.
+type Action struct {
+	Action    string     `json:"action" yaml:"action"`
+	Familiar  string     `json:"familiar,omitempty" yaml:"familiar,omitempty"`
+	Skipped   bool       `json:"skipped,omitempty" yaml:"skipped,omitempty"` // nothing was done, e.g. a throttled visit
+	Notes     []string   `json:"notes" yaml:"notes"`                         // what the text output would say
+	Before    *Stats     `json:"before,omitempty" yaml:"before,omitempty"`
+	After     *Stats     `json:"after,omitempty" yaml:"after,omitempty"`
+	Delta     *Stats     `json:"delta,omitempty" yaml:"delta,omitempty"`
+	Messages  []Message  `json:"messages,omitempty" yaml:"messages,omitempty"` // added, removed or acknowledged
+	Reminders []Reminder `json:"reminders,omitempty" yaml:"reminders,omitempty"`
+	Schedules []Schedule `json:"schedules,omitempty" yaml:"schedules,omitempty"`
+}
+
+// SetStats records the stats before and after the action and the change between them
+func (a *Action) SetStats(before, after Stats) {
+	delta := after.Sub(before)
+	a.Before, a.After, a.Delta = &before, &after, &delta
+}
+
+// Message is a queued message
+type Message struct {
+	ID           string    `json:"id" yaml:"id"`
+	Text         string    `json:"text" yaml:"text"`
+	Severity     string    `json:"severity" yaml:"severity"`
+	Author       string    `json:"author,omitempty" yaml:"author,omitempty"`
+	CreatedAt    time.Time `json:"createdAt" yaml:"createdAt"`
+	ExpiresAt    time.Time `json:"expiresAt,omitzero" yaml:"expiresAt,omitempty"`
+	Links        []string  `json:"links,omitempty" yaml:"links,omitempty"`
+	Acknowledged bool      `json:"acknowledged" yaml:"acknowledged"` // by the current user
+}
+
+// NewMessage describes a message as seen by the current user
+func NewMessage(p *pet.Pet, m pet.Message) Message {
+	return Message{
+		ID:           m.ID,
+		Text:         m.Text,
+		Severity:     string(m.Severity),
+		Author:       m.Author,
+		CreatedAt:    m.CreatedAt,
+		ExpiresAt:    m.ExpiresAt,
+		Links:        m.Links,
+		Acknowledged: p.Acknowledged(m),
+	}
+}
+
+// NewMessages describes messages as seen by the current user
+func NewMessages(p *pet.Pet, messages []pet.Message) []Message {
+	out := make([]Message, 0, len(messages))
+	for _, m := range messages {
+		out = append(out, NewMessage(p, m))
+	}
+	return out
+}
+
+// Messages is the data of 'familiar messages list'
+type Messages struct {
+	Messages []Message `json:"messages" yaml:"messages"`
+}
+
+// MessageStats is the data of 'familiar message stats'
+type MessageStats struct {
+	Message      Message           `json:"message" yaml:"message"`
+	Acknowledged []Acknowledgement `json:"acknowledged" yaml:"acknowledged"`
+	Pending      []string          `json:"pending" yaml:"pending"` // users who have not acknowledged
+}
+
+// Acknowledgement is a user's acknowledgement of a message
+type Acknowledgement struct {
+	User string    `json:"user" yaml:"user"`
+	At   time.Time `json:"at" yaml:"at"`
+}
+
+// Reminder is a personal reminder
+type Reminder struct {
+	ID        string    `json:"id" yaml:"id"`
+	Text      string    `json:"text" yaml:"text"`
+	Due       time.Time `json:"due" yaml:"due"`
+	Overdue   bool      `json:"overdue" yaml:"overdue"` // due now or in the past
+	CreatedAt time.Time `json:"createdAt" yaml:"createdAt"`
+	Snoozes   int       `json:"snoozes" yaml:"snoozes"`
+}
+
+// NewReminder describes a reminder at now
+func NewReminder(r pet.Reminder, now time.Time) Reminder {
+	return Reminder{
+		ID:        r.ID,
+		Text:      r.Text,
+		Due:       r.Due,
+		Overdue:   !r.Due.After(now),
+		CreatedAt: r.CreatedAt,
+		Snoozes:   r.Snoozes,
+	}
+}
+
+// NewReminders describes reminders at now
+func NewReminders(reminders []pet.Reminder, now time.Time) []Reminder {
+	out := make([]Reminder, 0, len(reminders))
+	for _, r := range reminders {
+		out = append(out, NewReminder(r, now))
+	}
+	return out
+}
+
+// Reminders is the data of 'familiar reminders list'
+type Reminders struct {
+	Reminders []Reminder `json:"reminders" yaml:"reminders"`
+}
+
+// Schedule is a scheduled message
+type Schedule struct {
+	ID       string    `json:"id" yaml:"id"`
+	Message  string    `json:"message" yaml:"message"`
+	Rule     string    `json:"rule" yaml:"rule"`
+	Timezone string    `json:"timezone" yaml:"timezone"`
+	Severity string    `json:"severity" yaml:"severity"`
+	Expires  string    `json:"expires,omitempty" yaml:"expires,omitempty"`
+	Until    string    `json:"until,omitempty" yaml:"until,omitempty"`
+	Next     time.Time `json:"next,omitzero" yaml:"next,omitempty"`        // unset when it will not fire again
+	Invalid  string    `json:"invalid,omitempty" yaml:"invalid,omitempty"` // why the schedule cannot run
+}
+
+// NewSchedule describes a schedule and its next occurrence after now
+func NewSchedule(s pet.ScheduleConfig, now time.Time) Schedule {
+	out := Schedule{
+		ID:       s.ID,
+		Message:  s.Message,
+		Severity: string(s.Severity),
+		Expires:  s.Expires,
+		Until:    s.Until,
+	}
+	if out.Severity == "" {
+		out.Severity = string(pet.SeverityInfo)
+	}
+	if err := s.Validate(); err != nil {
+		out.Invalid = err.Error()
+		return out
+	}
+	rule, _ := s.Rule()
+	out.Rule = rule.String()
+	out.Timezone = rule.Location().String()
+	next := rule.Next(now)
+	if ended, _ := s.Ended(next); !ended {
+		out.Next = next
+	}
+	return out
+}
+
+// Schedules is the data of 'familiar schedule list'
+type Schedules struct {
+	Schedules []Schedule `json:"schedules" yaml:"schedules"`
+}
+
+// Watch is a watched path rule
+type Watch struct {
+	Paths    []string `json:"paths" yaml:"paths"`
+	Message  string   `json:"message" yaml:"message"`
+	Severity string   `json:"severity" yaml:"severity"`
+	Expires  string   `json:"expires,omitempty" yaml:"expires,omitempty"`
+}
+
+// Watches is the data of 'familiar watch list'
+type Watches struct {
+	Watches []Watch `json:"watches" yaml:"watches"`
+}
+
+// Hooks is the data of the 'familiar admin hooks' commands
+type Hooks struct {
+	Dir       string `json:"dir" yaml:"dir"`
+	HooksPath string `json:"hooksPath,omitempty" yaml:"hooksPath,omitempty"` // core.hooksPath, when set
+	Hooks     []Hook `json:"hooks" yaml:"hooks"`
+}
+
+// Hook is the state of one git hook
+type Hook struct {
+	Name    string `json:"name" yaml:"name"`
+	State   string `json:"state" yaml:"state"`
+	Changed bool   `json:"changed" yaml:"changed"` // written or removed by this command
+	Chained bool   `json:"chained" yaml:"chained"` // familiar's hook chains one that existed before
+}
+
+// Gate is the data of 'familiar gate'
+type Gate struct {
+	Severity string    `json:"severity" yaml:"severity"`
+	Open     bool      `json:"open" yaml:"open"`
+	Blocking []Message `json:"blocking" yaml:"blocking"`
+	Bypassed []Message `json:"bypassed" yaml:"bypassed"` // let through with --allow
+}
+
+// Doctor is the data of 'familiar shell doctor'
+type Doctor struct {
+	Shell    string  `json:"shell" yaml:"shell"`
+	Checks   []Check `json:"checks" yaml:"checks"`
+	Problems int     `json:"problems" yaml:"problems"`
+}
+
+// Check is one check made by 'familiar shell doctor'
+type Check struct {
+	Name   string `json:"name" yaml:"name"`
+	OK     bool   `json:"ok" yaml:"ok"`
+	Detail string `json:"detail" yaml:"detail"`
+}
+
+// Prompt is the data of 'familiar admin health', the prompt segment
+type Prompt struct {
+	Name      string `json:"name" yaml:"name"`
+	Health    int    `json:"health" yaml:"health"`
+	Condition string `json:"condition" yaml:"condition"`
+	State     string `json:"state" yaml:"state"`
+	Icon      string `json:"icon" yaml:"icon"`
+	Glyph     string `json:"glyph" yaml:"glyph"`
+	Color     string `json:"color" yaml:"color"` // "#rrggbb", empty for the terminal default
+	Messages  int    `json:"messages" yaml:"messages"`
+	Reminders int    `json:"reminders" yaml:"reminders"`
+}
+
+// NewPrompt describes a prompt segment
+func NewPrompt(seg prompt.Segment) Prompt {
+	return Prompt{
+		Name:      seg.Name,
+		Health:    seg.Health,
+		Condition: seg.Condition,
+		State:     seg.State,
+		Icon:      seg.Icon,
+		Glyph:     seg.Glyph,
+		Color:     prompt.HexColor(seg.Color),
+		Messages:  seg.Count,
+		Reminders: seg.Reminders,
+	}
+}
+
+// Art is the data of 'familiar admin art <state>'
+type Art struct {
+	State    string  `json:"state" yaml:"state"` // as requested
+	Key      string  `json:"key" yaml:"key"`     // the animation shown
+	Source   string  `json:"source" yaml:"source"`
+	FPS      int     `json:"fps" yaml:"fps"`
+	Loops    int     `json:"loops" yaml:"loops"`
+	PingPong bool    `json:"pingPong,omitempty" yaml:"pingPong,omitempty"`
+	Frames   []Frame `json:"frames" yaml:"frames"`
+}
+
+// Frame is one animation frame as plain text
+type Frame struct {
+	Lines []string `json:"lines" yaml:"lines"`
+	MS    int      `json:"ms,omitempty" yaml:"ms,omitempty"`
+}
+
+// ArtStates is the data of 'familiar admin art list'
+type ArtStates struct {
+	States []ArtState `json:"states" yaml:"states"`
+}
+
+// ArtState is one configured animation
+type ArtState struct {
+	Key    string `json:"key" yaml:"key"`
+	Source string `json:"source" yaml:"source"`
+	Frames int    `json:"frames" yaml:"frames"`
+}
+
+// Wardrobe is the data of 'familiar wear' without an item
+type Wardrobe struct {
+	Accessories  []Accessory   `json:"accessories" yaml:"accessories"`
+	Achievements []Achievement `json:"achievements" yaml:"achievements"`
+}
+
+// Accessory is one configured accessory
+type Accessory struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Unlocked    bool   `json:"unlocked" yaml:"unlocked"`
+	Wearing     bool   `json:"wearing" yaml:"wearing"`
+	Unlock      string `json:"unlock" yaml:"unlock"` // how it is unlocked
+}
+
+// Achievement is an achievement the familiar has earned
+type Achievement struct {
+	ID          string    `json:"id" yaml:"id"`
+	Description string    `json:"description" yaml:"description"`
+	Earned      time.Time `json:"earned" yaml:"earned"`
+}
+
+// Palettes is the data of 'familiar recolor' without a palette
+type Palettes struct {
+	Active   string    `json:"active" yaml:"active"`
+	Palettes []Palette `json:"palettes" yaml:"palettes"`
+}
+
+// Palette is one of the familiar's palettes, with the default colours it
+// keeps included
+type Palette struct {
+	Name   string            `json:"name" yaml:"name"`
+	Colors map[string]string `json:"colors" yaml:"colors"`
+}
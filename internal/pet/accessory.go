@@ -0,0 +1,124 @@
+package pet
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Achievement is a milestone a familiar earns once. Accessories can be
+// unlocked by them.
+type Achievement struct {
+	ID          string
+	Description string
+	earned      func(s PetState) bool
+}
+
+// Achievements are the milestones a familiar can earn
+var Achievements = []Achievement{
+	{"first-meal", "Fed for the first time", func(s PetState) bool { return s.Feeds >= 1 }},
+	{"well-fed", "Fed 25 times", func(s PetState) bool { return s.Feeds >= 25 }},
+	{"playmate", "Played with 25 times", func(s PetState) bool { return s.Plays >= 25 }},
+	{"regular", "Visited 100 times", func(s PetState) bool { return s.Visits >= 100 }},
+	{"grown-up", "Reached evolution 2", func(s PetState) bool { return s.Evolution >= 2 }},
+}
+
+// FindAchievement returns the achievement with id
+func FindAchievement(id string) (Achievement, bool) {
+	for _, a := range Achievements {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return Achievement{}, false
+}
+
+// AwardAchievements records the achievements s has newly earned and returns them
+func (s *PetState) AwardAchievements(now time.Time) []Achievement {
+	var awarded []Achievement
+	for _, a := range Achievements {
+		if _, ok := s.Achievements[a.ID]; ok || !a.earned(*s) {
+			continue
+		}
+		if s.Achievements == nil {
+			s.Achievements = make(map[string]time.Time)
+		}
+		s.Achievements[a.ID] = now
+		awarded = append(awarded, a)
+	}
+	return awarded
+}
+
+// AccessoryUnlocked reports whether the familiar may wear accessory name
+func (p *Pet) AccessoryUnlocked(name string) bool {
+	a, ok := p.Config.Accessories[name]
+	if !ok {
+		return false
+	}
+	if a.Evolution <= 0 && a.Achievement == "" {
+		return true
+	}
+	if a.Evolution > 0 && p.State.Evolution >= a.Evolution {
+		return true
+	}
+	_, earned := p.State.Achievements[a.Achievement]
+	return a.Achievement != "" && earned
+}
+
+// UnlockedAccessories returns the names of the accessories the familiar may wear, sorted
+func (p *Pet) UnlockedAccessories() []string {
+	var names []string
+	for name := range p.Config.Accessories {
+		if p.AccessoryUnlocked(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Wears reports whether accessory name is worn
+func (p *Pet) Wears(name string) bool {
+	for _, w := range p.State.Wearing {
+		if w == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Unlock describes how the accessory is unlocked, e.g. "evolution 2 or the
+// playmate achievement"
+func (a AccessoryConfig) Unlock() string {
+	var ways []string
+	if a.Evolution > 0 {
+		ways = append(ways, fmt.Sprintf("evolution %d", a.Evolution))
+	}
+	if a.Achievement != "" {
+		ways = append(ways, fmt.Sprintf("the %s achievement", a.Achievement))
+	}
+	if len(ways) == 0 {
+		return "always available"
+	}
+	return strings.Join(ways, " or ")
+}
+
+// evolutionKey matches the e<N> prefix of an animation key
+var evolutionKey = regexp.MustCompile(`^(e\d+):(.*)$`)
+
+// Offset returns where the layer goes on frames of animation key: the
+// anchor for the key, its evolution or its state, else X and Y
+func (a AccessoryConfig) Offset(key string) (x, y int) {
+	candidates := []string{key}
+	if m := evolutionKey.FindStringSubmatch(key); m != nil {
+		candidates = append(candidates, m[1], m[2])
+	}
+	for _, c := range candidates {
+		if anchor, ok := a.Anchors[c]; ok {
+			return anchor[0], anchor[1]
+		}
+	}
+	return a.X, a.Y
+}
@@ -1,7 +1,10 @@
 package pet
 
 import (
+	"fmt"
 	"time"
+
+	"github.com/sethgrid/familiar/internal/duration"
 )
 
 type EvolutionMode string
@@ -18,6 +21,15 @@ const (
 	HealthComputationWeighted HealthComputationMode = "weighted"
 )
 
+type AckStore string
+
+const (
+	// AckStoreState records acknowledgements on each message in pet.state.toml
+	AckStoreState AckStore = "state"
+	// AckStoreUser records each user's acknowledgements in .familiar/acks/<user>.toml
+	AckStoreUser AckStore = "user"
+)
+
 type PetConfig struct {
 	Version               string                `toml:"version"`
 	Name                  string                `toml:"name"`
@@ -40,23 +52,178 @@ type PetConfig struct {
 	HealthComputation     HealthComputationMode `toml:"healthComputation"`
 	InteractionThreshold  int                   `toml:"interactionThreshold"`
 
+	AckStore AckStore `toml:"ackStore,omitempty"` // "state" (default) | "user"
+
 	CacheTTL            time.Duration `toml:"cacheTTL"`
 	AllowAnsiAnimations bool          `toml:"allowAnsiAnimations"`
+	SpeechBubble        string        `toml:"speechBubble,omitempty"` // "round" (default) | "ascii" | "off"
+	Graphics            string        `toml:"graphics,omitempty"`     // "auto" (default) | "blocks" | "braille" | "kitty" | "iterm" | "sixel"
+	Braille             BrailleConfig `toml:"braille,omitempty"`
+
+	Prompt PromptConfig `toml:"prompt,omitempty"`
+
+	Watches   []WatchConfig    `toml:"watches,omitempty"`
+	Schedules []ScheduleConfig `toml:"schedules,omitempty"`
+	Gate      GateConfig       `toml:"gate,omitempty"`
 
 	Animations map[string]AnimationConfig `toml:"animations"`
+
+	// Palettes name the colours pixel frames may use as symbols; see Palette
+	Palettes map[string]Palette `toml:"palettes,omitempty"`
+
+	// Legend maps the characters of grid frames to colours for every
+	// animation and accessory; their own legends add to it
+	Legend map[string]string `toml:"legend,omitempty"`
+
+	// Accessories are layers drawn over pixel animations once unlocked and worn
+	Accessories map[string]AccessoryConfig `toml:"accessories,omitempty"`
+}
+
+// PromptConfig customises the segment printed by 'familiar admin health'.
+// Glyph and colour keys are the prompt states: health, message, warn, critical,
+// reminder, asleep, stone and evolution-ready; colours also accept the health
+// ladder keys excellent, good, fair, poor and failing.
+type PromptConfig struct {
+	Theme  string            `toml:"theme,omitempty"`  // built-in theme: "default" | "ascii" | "minimal"
+	Icon   string            `toml:"icon,omitempty"`   // replaces the theme's icon, e.g. "🐈"
+	Count  string            `toml:"count,omitempty"`  // unread count: "auto" (default, when >1) | "always" | "never"
+	Glyphs map[string]string `toml:"glyphs,omitempty"` // per-state glyph overrides
+	Colors map[string]string `toml:"colors,omitempty"` // per-state colours: SGR parameters ("38;5;208") or "#rrggbb"
+}
+
+// IsZero reports whether the prompt has not been customised
+func (c PromptConfig) IsZero() bool {
+	return c.Theme == "" && c.Icon == "" && c.Count == "" && len(c.Glyphs) == 0 && len(c.Colors) == 0
+}
+
+// AccessoryConfig is a layer of pixel frames composited with the familiar's
+// pixel animations, such as a hat. It is unlocked at Evolution or by earning
+// Achievement (either will do; with neither it is always available), and
+// drawn while worn ('familiar wear'). Layer frames are cycled with the
+// familiar's frames.
+type AccessoryConfig struct {
+	Description string `toml:"description,omitempty"`
+	Evolution   int    `toml:"evolution,omitempty"`
+	Achievement string `toml:"achievement,omitempty"` // see Achievements
+	Z           int    `toml:"z,omitempty"`           // drawing order; negative draws behind the familiar
+	// X and Y place the layer's top-left pixel relative to the frame's and
+	// may be negative, e.g. a hat above the head. Anchors override them per
+	// animation key ("e2:asleep"), evolution ("e2") or state ("asleep").
+	X       int               `toml:"x,omitempty"`
+	Y       int               `toml:"y,omitempty"`
+	Anchors map[string][2]int `toml:"anchors,omitempty"`
+	Frames  []Frame           `toml:"frames"`
+	Legend  map[string]string `toml:"legend,omitempty"`
+}
+
+// BrailleConfig tunes the braille renderer (graphics = "braille"), which draws
+// each 2x4 block of pixels as the dots of one braille character
+type BrailleConfig struct {
+	Threshold int  `toml:"threshold,omitempty"` // 0-255: pixels at least this bright become dots; 0 keeps every pixel
+	Dither    bool `toml:"dither,omitempty"`    // ordered dithering by brightness instead of a threshold
+}
+
+// IsZero reports whether no braille settings are set
+func (c BrailleConfig) IsZero() bool {
+	return c.Threshold == 0 && !c.Dither
+}
+
+// GateConfig controls 'familiar gate', which fails while unacknowledged
+// messages at or above Severity are pending
+type GateConfig struct {
+	Severity Severity `toml:"severity,omitempty"` // default "critical"
+	Hooks    []string `toml:"hooks,omitempty"`    // git hooks that run the gate, e.g. ["pre-push"]
+}
+
+// WatchConfig queues a message when files matching Paths change between the
+// last commit familiar saw and HEAD (see 'familiar watch check')
+type WatchConfig struct {
+	Paths    []string `toml:"paths"`
+	Message  string   `toml:"message"` // supports {{FILES}}, {{COUNT}}, {{FROM}}, {{TO}}
+	Severity Severity `toml:"severity,omitempty"`
+	Expires  string   `toml:"expires,omitempty"` // e.g. "7d"
+}
+
+// Validate checks that the watch has paths and that its severity and expiry parse
+func (w WatchConfig) Validate() error {
+	if len(w.Paths) == 0 {
+		return fmt.Errorf("watch is missing paths")
+	}
+	if _, err := ParseSeverity(string(w.Severity)); err != nil {
+		return fmt.Errorf("watch %v: %w", w.Paths, err)
+	}
+	if w.Expires != "" {
+		if _, err := duration.Parse(w.Expires); err != nil {
+			return fmt.Errorf("watch %v: %w", w.Paths, err)
+		}
+	}
+	return nil
 }
 
+// ScheduleConfig queues a message each time its rule comes due. Familiar has
+// no daemon, so due messages are queued on the next invocation (see 'familiar schedule').
+type ScheduleConfig struct {
+	ID       string   `toml:"id"`
+	Message  string   `toml:"message"`
+	Cron     string   `toml:"cron,omitempty"`     // e.g. "0 9 * * 1"
+	Every    string   `toml:"every,omitempty"`    // e.g. "monday 09:00", used when Cron is empty
+	Timezone string   `toml:"timezone,omitempty"` // IANA name, default local time
+	Severity Severity `toml:"severity,omitempty"`
+	Expires  string   `toml:"expires,omitempty"` // message lifetime, default until the next occurrence
+	Until    string   `toml:"until,omitempty"`   // last day the schedule fires, "2006-01-02"
+}
+
+// AnimationConfig is one animation. File and url animations keep their frames
+// outside pet.toml: plain text separated by Delimiter lines, or a TOML frame
+// set when the name ends in .toml. They are loaded when shown (see
+// storage.LoadFrames) and never written back.
 type AnimationConfig struct {
-	Source string  `toml:"source"` // "inline" | "pixel" | "url" | "file"
-	URL    string  `toml:"url,omitempty"`
-	Path   string  `toml:"path,omitempty"`
-	FPS    int     `toml:"fps"`
-	Loops  int     `toml:"loops"`  // 0 or -1 = infinite
-	Frames []Frame `toml:"frames"` // for source == "inline" or "pixel"
+	Source    string  `toml:"source"` // "inline" | "pixel" | "url" | "file"
+	URL       string  `toml:"url,omitempty"`
+	Path      string  `toml:"path,omitempty"`      // for "file", relative to the pet directory
+	SHA256    string  `toml:"sha256,omitempty"`    // pins the frame file or url content
+	Delimiter string  `toml:"delimiter,omitempty"` // frame separator in plain-text files, default "---"
+	FPS       int     `toml:"fps"`
+	Loops     int     `toml:"loops"`              // 0 or -1 = infinite
+	PingPong  bool    `toml:"pingPong,omitempty"` // play the frames forward, then back
+	Frames    []Frame `toml:"frames"`             // for source == "inline" or "pixel"
+
+	Legend map[string]string `toml:"legend,omitempty"` // grid characters, on top of the config's legend
+}
+
+// External reports whether the frames live in a file or at a url
+func (a AnimationConfig) External() bool {
+	return a.Source == "file" || a.Source == "url"
+}
+
+// Pass returns the frame indexes of one pass through the animation. A
+// ping-pong pass goes forward then back without repeating either end, so
+// passes join up: 0 1 2 1, 0 1 2 1, ...
+func (a AnimationConfig) Pass() []int {
+	pass := make([]int, 0, 2*len(a.Frames))
+	for i := range a.Frames {
+		pass = append(pass, i)
+	}
+	if a.PingPong {
+		for i := len(a.Frames) - 2; i > 0; i-- {
+			pass = append(pass, i)
+		}
+	}
+	return pass
+}
+
+// Pixel reports whether the frames are pixel art: a pixel animation, or a
+// file or url frame set of pixels
+func (a AnimationConfig) Pixel() bool {
+	if a.Source == "pixel" {
+		return true
+	}
+	return a.External() && len(a.Frames) > 0 && len(a.Frames[0].Pixels) > 0
 }
 
 type Frame struct {
 	Art    string     `toml:"art,omitempty"`    // For inline ASCII art
-	Pixels [][]string `toml:"pixels,omitempty"` // For pixel art: 2D array of color hex codes
+	Pixels [][]string `toml:"pixels,omitempty"` // For pixel art: 2D array of color hex codes or palette symbols
+	Grid   []string   `toml:"grid,omitempty"`   // Pixel art as rows of legend characters, . for transparent
 	MS     int        `toml:"ms,omitempty"`
 }
@@ -5,6 +5,13 @@ import (
 )
 
 func ApplyTimeStep(p *Pet, now time.Time) error {
+	// Expired messages leave the queue regardless of decay settings
+	p.State.PruneExpiredMessages(now)
+	// Scheduled messages come due whether or not the pet decays
+	if err := materializeSchedules(p, now); err != nil {
+		return err
+	}
+
 	// Initialize LastChecked if zero
 	if p.State.LastChecked.IsZero() {
 		p.State.LastChecked = now
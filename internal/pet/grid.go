@@ -0,0 +1,213 @@
+package pet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// GridTransparent is the grid character for a transparent pixel
+const GridTransparent = '.'
+
+// gridChars are the characters GridLegend gives colours without a letter of
+// their own, in order of preference
+const gridChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789#@%&*+=~^$?!<>:;|/()[]{}"
+
+// ExpandGrid turns the rows of a grid frame into pixels: every character is
+// looked up in legend (hex colours or palette symbols by character), and .
+// is transparent. Rows may differ in width, as rows of pixels may; pixels past
+// the end of a short row are drawn transparent.
+func ExpandGrid(grid []string, legend map[string]string) ([][]string, error) {
+	pixels := make([][]string, len(grid))
+	for y, row := range grid {
+		pixels[y] = make([]string, 0, utf8.RuneCountInString(row))
+		for x, r := range []rune(row) {
+			if r == GridTransparent {
+				pixels[y] = append(pixels[y], "")
+				continue
+			}
+			c, ok := legend[string(r)]
+			if !ok {
+				return nil, fmt.Errorf("row %d column %d: '%c' is not in the legend", y+1, x+1, r)
+			}
+			pixels[y] = append(pixels[y], c)
+		}
+	}
+	return pixels, nil
+}
+
+// mergeLegends returns the shared legend with the entries of own on top
+func mergeLegends(shared, own map[string]string) map[string]string {
+	if len(own) == 0 {
+		return shared
+	}
+	merged := make(map[string]string, len(shared)+len(own))
+	for k, v := range shared {
+		merged[k] = v
+	}
+	for k, v := range own {
+		merged[k] = v
+	}
+	return merged
+}
+
+// expandFrames fills in the pixels of grid frames
+func expandFrames(frames []Frame, legend map[string]string) error {
+	for i := range frames {
+		if len(frames[i].Grid) == 0 {
+			continue
+		}
+		pixels, err := ExpandGrid(frames[i].Grid, legend)
+		if err != nil {
+			return fmt.Errorf("frame %d: %w", i+1, err)
+		}
+		frames[i].Pixels = pixels
+	}
+	return nil
+}
+
+// ExpandGrids fills in the pixels of every grid frame of the animations and
+// accessories, through their own legend on top of the config's. The grids
+// are kept so the config is saved as it was written (see Compact).
+func (c *PetConfig) ExpandGrids() error {
+	for key, anim := range c.Animations {
+		if err := expandFrames(anim.Frames, mergeLegends(c.Legend, anim.Legend)); err != nil {
+			return fmt.Errorf("animation %s: %w", key, err)
+		}
+	}
+	for name, a := range c.Accessories {
+		if err := expandFrames(a.Frames, mergeLegends(c.Legend, a.Legend)); err != nil {
+			return fmt.Errorf("accessory %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Compact returns a copy of the config without the pixels ExpandGrids
+// filled in, for saving
+func (c PetConfig) Compact() PetConfig {
+	compact := c
+	compact.Animations = make(map[string]AnimationConfig, len(c.Animations))
+	for key, anim := range c.Animations {
+		anim.Frames = compactFrames(anim.Frames)
+		compact.Animations[key] = anim
+	}
+	if c.Accessories != nil {
+		compact.Accessories = make(map[string]AccessoryConfig, len(c.Accessories))
+		for name, a := range c.Accessories {
+			a.Frames = compactFrames(a.Frames)
+			compact.Accessories[name] = a
+		}
+	}
+	return compact
+}
+
+func compactFrames(frames []Frame) []Frame {
+	if frames == nil {
+		return nil
+	}
+	compact := make([]Frame, len(frames))
+	for i, frame := range frames {
+		if len(frame.Grid) > 0 {
+			frame.Pixels = nil
+		}
+		compact[i] = frame
+	}
+	return compact
+}
+
+// GridLegend extends legend with a character for every colour of frames
+// that has none, so the frames can be written as grids. A palette symbol
+// gets its initial, in lower or upper case, or another of its letters when
+// one is free.
+func GridLegend(frames []Frame, legend map[string]string) map[string]string {
+	extended := make(map[string]string, len(legend))
+	used := map[string]bool{string(GridTransparent): true}
+	chars := map[string]string{} // colour -> character
+	for k, v := range legend {
+		extended[k] = v
+		used[k] = true
+		if existing, ok := chars[v]; !ok || k < existing {
+			chars[v] = k
+		}
+	}
+
+	var colors []string
+	seen := map[string]bool{}
+	for _, frame := range frames {
+		for _, row := range frame.Pixels {
+			for _, px := range row {
+				if !gridTransparent(px) && chars[px] == "" && !seen[px] {
+					seen[px] = true
+					colors = append(colors, px)
+				}
+			}
+		}
+	}
+	// Symbols choose first, so they get their own letters
+	sort.Slice(colors, func(i, j int) bool {
+		hi, hj := strings.HasPrefix(colors[i], "#"), strings.HasPrefix(colors[j], "#")
+		if hi != hj {
+			return hj
+		}
+		return colors[i] < colors[j]
+	})
+
+	for _, color := range colors {
+		candidates := gridChars
+		if !strings.HasPrefix(color, "#") {
+			candidates = color[:1] + strings.ToUpper(color[:1]) + color + strings.ToUpper(color) + gridChars
+		}
+		for _, r := range candidates {
+			k := string(r)
+			if used[k] || r == ' ' || !strings.ContainsRune(gridChars, r) {
+				continue
+			}
+			used[k] = true
+			extended[k] = color
+			chars[color] = k
+			break
+		}
+	}
+	return extended
+}
+
+// gridTransparent reports whether a pixel is written as . in a grid
+func gridTransparent(px string) bool {
+	return px == "" || px == "transparent"
+}
+
+// GridFrames writes pixel frames as grids with legend, which must have a
+// character for every colour (see GridLegend)
+func GridFrames(frames []Frame, legend map[string]string) ([]Frame, error) {
+	chars := map[string]rune{}
+	for k, v := range legend {
+		r, _ := utf8.DecodeRuneInString(k)
+		if existing, ok := chars[v]; !ok || r < existing {
+			chars[v] = r
+		}
+	}
+
+	grids := make([]Frame, len(frames))
+	for i, frame := range frames {
+		grid := make([]string, len(frame.Pixels))
+		for y, row := range frame.Pixels {
+			var line strings.Builder
+			for _, px := range row {
+				if gridTransparent(px) {
+					line.WriteRune(GridTransparent)
+					continue
+				}
+				r, ok := chars[px]
+				if !ok {
+					return nil, fmt.Errorf("frame %d: no legend character for %s", i+1, px)
+				}
+				line.WriteRune(r)
+			}
+			grid[y] = line.String()
+		}
+		grids[i] = Frame{Grid: grid, MS: frame.MS}
+	}
+	return grids, nil
+}
@@ -0,0 +1,130 @@
+package pet
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandGrid(t *testing.T) {
+	legend := map[string]string{"g": "#00FF00", "f": "fur", "é": "#E0E0E0"}
+	tests := map[string]struct {
+		grid    []string
+		want    [][]string
+		wantErr string
+	}{
+		"colours and symbols": {
+			grid: []string{"g.f", ".é."},
+			want: [][]string{{"#00FF00", "", "fur"}, {"", "#E0E0E0", ""}},
+		},
+		// Short rows are kept as written, like rows of pixels
+		"ragged rows": {
+			grid: []string{"ggg", "g", ""},
+			want: [][]string{{"#00FF00", "#00FF00", "#00FF00"}, {"#00FF00"}, {}},
+		},
+		"bad legend symbol": {
+			grid:    []string{"gg", "gx"},
+			wantErr: "row 2 column 2: 'x' is not in the legend",
+		},
+		"space is not transparent": {
+			grid:    []string{"g g"},
+			wantErr: "row 1 column 2: ' ' is not in the legend",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ExpandGrid(tt.grid, legend)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ExpandGrid() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandGrid() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandGrid() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandGrids(t *testing.T) {
+	tests := map[string]struct {
+		config  PetConfig
+		wantErr string
+	}{
+		"own legend over shared": {
+			config: PetConfig{
+				Legend:     map[string]string{"g": "#00FF00"},
+				Animations: map[string]AnimationConfig{"happy": {Legend: map[string]string{"h": "#FF0000"}, Frames: []Frame{{Grid: []string{"gh"}}}}},
+			},
+		},
+		"bad animation symbol": {
+			config: PetConfig{
+				Animations: map[string]AnimationConfig{"happy": {Frames: []Frame{{Pixels: [][]string{{"#FFF"}}}, {Grid: []string{"z"}}}}},
+			},
+			wantErr: "animation happy: frame 2: row 1 column 1: 'z' is not in the legend",
+		},
+		"bad accessory symbol": {
+			config: PetConfig{
+				Legend:      map[string]string{"g": "#00FF00"},
+				Accessories: map[string]AccessoryConfig{"hat": {Frames: []Frame{{Grid: []string{"g", "gq"}}}}},
+			},
+			wantErr: "accessory hat: frame 1: row 2 column 2: 'q' is not in the legend",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.config.ExpandGrids()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ExpandGrids() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandGrids() error = %v", err)
+			}
+			if got := tt.config.Animations["happy"].Frames[0].Pixels; !reflect.DeepEqual(got, [][]string{{"#00FF00", "#FF0000"}}) {
+				t.Errorf("Expanded pixels = %q", got)
+			}
+			if len(tt.config.Compact().Animations["happy"].Frames[0].Pixels) != 0 {
+				t.Error("Expected Compact to drop the expanded pixels")
+			}
+		})
+	}
+}
+
+func TestGridRoundTrip(t *testing.T) {
+	frames := []Frame{
+		{Pixels: [][]string{{"#00FF00", "", "fur"}, {"transparent", "#00FF00"}}, MS: 50},
+		{Pixels: [][]string{{"fur"}, {}}},
+	}
+	legend := GridLegend(frames, map[string]string{"g": "#00FF00"})
+	if legend["g"] != "#00FF00" || legend["f"] != "fur" {
+		t.Errorf("GridLegend() = %v, want g kept and f for fur", legend)
+	}
+
+	grids, err := GridFrames(frames, legend)
+	if err != nil {
+		t.Fatalf("GridFrames() error = %v", err)
+	}
+	if got := strings.Join(grids[0].Grid, "/"); got != "g.f/.g" || grids[0].MS != 50 {
+		t.Errorf("GridFrames() = %q, ms %d", got, grids[0].MS)
+	}
+	for i, frame := range grids {
+		pixels, err := ExpandGrid(frame.Grid, legend)
+		if err != nil {
+			t.Fatalf("ExpandGrid() error = %v", err)
+		}
+		if len(pixels) != len(frames[i].Pixels) || len(pixels[0]) != len(frames[i].Pixels[0]) {
+			t.Errorf("Frame %d expanded to %q, want the shape of %q", i, pixels, frames[i].Pixels)
+		}
+	}
+
+	if _, err := GridFrames(frames, map[string]string{"g": "#00FF00"}); err == nil || !strings.Contains(err.Error(), "no legend character for fur") {
+		t.Errorf("GridFrames() without a character for fur error = %v", err)
+	}
+}
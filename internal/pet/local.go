@@ -0,0 +1,95 @@
+package pet
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// LocalState is per-user, per-familiar state kept on the user's machine and
+// never written to the .familiar directory
+type LocalState struct {
+	PetDir string `toml:"petDir"`
+
+	// LastSeenCommit is the HEAD processed by the last 'familiar watch check'
+	LastSeenCommit string `toml:"lastSeenCommit,omitempty"`
+
+	// Reminders are personal nudges set with 'familiar remind'
+	Reminders []Reminder `toml:"reminders,omitempty"`
+}
+
+// Reminder is a personal message that surfaces once Due has passed
+type Reminder struct {
+	ID        string    `toml:"id"`
+	Text      string    `toml:"text"`
+	Due       time.Time `toml:"due"`
+	CreatedAt time.Time `toml:"createdAt"`
+	Snoozes   int       `toml:"snoozes,omitempty"`
+}
+
+// AddReminder appends a reminder, assigning it an id
+func (l *LocalState) AddReminder(r Reminder) Reminder {
+	if r.ID == "" {
+		r.ID = NewMessageID()
+		for l.findReminder(r.ID) >= 0 {
+			r.ID = NewMessageID()
+		}
+	}
+	l.Reminders = append(l.Reminders, r)
+	return r
+}
+
+// DueReminders returns the reminders whose due time has passed, oldest first
+func (l *LocalState) DueReminders(now time.Time) []Reminder {
+	var due []Reminder
+	for _, r := range l.Reminders {
+		if !r.Due.After(now) {
+			due = append(due, r)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].Due.Before(due[j].Due) })
+	return due
+}
+
+// FindReminder returns the reminder with the given id or unique id prefix
+func (l *LocalState) FindReminder(id string) (*Reminder, error) {
+	i := l.findReminder(id)
+	if i >= 0 {
+		return &l.Reminders[i], nil
+	}
+
+	match := -1
+	for i, r := range l.Reminders {
+		if id != "" && strings.HasPrefix(r.ID, id) {
+			if match >= 0 {
+				return nil, &LookupError{Kind: "reminder", ID: id, Ambiguous: true}
+			}
+			match = i
+		}
+	}
+	if match < 0 {
+		return nil, &LookupError{Kind: "reminder", ID: id}
+	}
+	return &l.Reminders[match], nil
+}
+
+// RemoveReminder deletes a reminder by id or unique id prefix
+func (l *LocalState) RemoveReminder(id string) (Reminder, error) {
+	r, err := l.FindReminder(id)
+	if err != nil {
+		return Reminder{}, err
+	}
+	removed := *r
+	i := l.findReminder(removed.ID)
+	l.Reminders = append(l.Reminders[:i], l.Reminders[i+1:]...)
+	return removed, nil
+}
+
+func (l *LocalState) findReminder(id string) int {
+	for i, r := range l.Reminders {
+		if r.ID == id {
+			return i
+		}
+	}
+	return -1
+}
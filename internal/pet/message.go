@@ -0,0 +1,192 @@
+package pet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Severity string
+
+// LookupError is returned when an id or id prefix matches no message or
+// reminder, or more than one
+type LookupError struct {
+	Kind      string // "message" or "reminder"
+	ID        string
+	Ambiguous bool
+}
+
+func (e *LookupError) Error() string {
+	if e.Ambiguous {
+		return fmt.Sprintf("%s id '%s' is ambiguous", e.Kind, e.ID)
+	}
+	return fmt.Sprintf("no %s with id '%s'", e.Kind, e.ID)
+}
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarn     Severity = "warn"
+	SeverityCritical Severity = "critical"
+)
+
+// Rank orders severities from least (0) to most (2) urgent. Unknown values rank as info.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityWarn:
+		return 1
+	case SeverityCritical:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// ParseSeverity validates a severity name. An empty string means info.
+func ParseSeverity(s string) (Severity, error) {
+	switch Severity(strings.ToLower(s)) {
+	case "", SeverityInfo:
+		return SeverityInfo, nil
+	case SeverityWarn, "warning":
+		return SeverityWarn, nil
+	case SeverityCritical:
+		return SeverityCritical, nil
+	}
+	return "", fmt.Errorf("unknown severity '%s' (expected info, warn or critical)", s)
+}
+
+type Message struct {
+	ID        string    `toml:"id"`
+	Text      string    `toml:"text"`
+	Severity  Severity  `toml:"severity"`
+	Author    string    `toml:"author,omitempty"`
+	CreatedAt time.Time `toml:"createdAt"`
+	ExpiresAt time.Time `toml:"expiresAt"`
+	Links     []string  `toml:"links,omitempty"`
+
+	// AckedBy lists who has acknowledged the message when acknowledgements
+	// are kept in the shared state (ackStore = "state")
+	AckedBy []Acknowledgement `toml:"ackedBy,omitempty"`
+}
+
+// Expired reports whether the message has an expiry that has passed
+func (m Message) Expired(now time.Time) bool {
+	return !m.ExpiresAt.IsZero() && !now.Before(m.ExpiresAt)
+}
+
+// NewMessageID returns a short random id that is easy to type on the command line
+func NewMessageID() string {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		// Fall back to the clock; ids only need to be unique within one queue
+		return fmt.Sprintf("%06x", time.Now().UnixNano()&0xffffff)
+	}
+	return hex.EncodeToString(b)
+}
+
+// AddMessage appends a message to the queue, filling in an id and severity if missing
+func (s *PetState) AddMessage(m Message) Message {
+	if m.ID == "" {
+		m.ID = NewMessageID()
+		for s.findMessage(m.ID) >= 0 {
+			m.ID = NewMessageID()
+		}
+	}
+	if m.Severity == "" {
+		m.Severity = SeverityInfo
+	}
+	s.Messages = append(s.Messages, m)
+	return m
+}
+
+// ActiveMessages returns the messages that have not expired, in the order they were added
+func (s *PetState) ActiveMessages(now time.Time) []Message {
+	var active []Message
+	for _, m := range s.Messages {
+		if !m.Expired(now) {
+			active = append(active, m)
+		}
+	}
+	return active
+}
+
+// PruneExpiredMessages drops expired messages from the queue
+func (s *PetState) PruneExpiredMessages(now time.Time) {
+	if len(s.Messages) == 0 {
+		return
+	}
+	s.Messages = s.ActiveMessages(now)
+}
+
+// FindMessage looks up a message by id or unique id prefix
+func (s *PetState) FindMessage(id string) (*Message, error) {
+	idx := s.findMessage(id)
+	if idx == -2 {
+		return nil, &LookupError{Kind: "message", ID: id, Ambiguous: true}
+	}
+	if idx < 0 {
+		return nil, &LookupError{Kind: "message", ID: id}
+	}
+	return &s.Messages[idx], nil
+}
+
+// RemoveMessage deletes a message by id or unique id prefix
+func (s *PetState) RemoveMessage(id string) (Message, error) {
+	m, err := s.FindMessage(id)
+	if err != nil {
+		return Message{}, err
+	}
+	removed := *m
+	idx := s.findMessage(removed.ID)
+	s.Messages = append(s.Messages[:idx], s.Messages[idx+1:]...)
+	return removed, nil
+}
+
+// findMessage returns the index of the matching message, -1 if none matches
+// and -2 if a prefix matches more than one message
+func (s *PetState) findMessage(id string) int {
+	if id == "" {
+		return -1
+	}
+	match := -1
+	for i, m := range s.Messages {
+		if m.ID == id {
+			return i
+		}
+		if strings.HasPrefix(m.ID, id) {
+			if match >= 0 {
+				return -2
+			}
+			match = i
+		}
+	}
+	return match
+}
+
+// HighestSeverity returns the most urgent severity in msgs, or "" if msgs is empty
+func HighestSeverity(msgs []Message) Severity {
+	var highest Severity
+	for _, m := range msgs {
+		if highest == "" || m.Severity.Rank() > highest.Rank() {
+			highest = m.Severity
+		}
+	}
+	return highest
+}
+
+// Acknowledgement records that a user has read a message
+type Acknowledgement struct {
+	User string    `toml:"user"`
+	At   time.Time `toml:"at"`
+}
+
+// AckedAt returns when user acknowledged the message, if they have
+func (m Message) AckedAt(user string) (time.Time, bool) {
+	for _, a := range m.AckedBy {
+		if a.User == user {
+			return a.At, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,136 @@
+package pet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultPalette is the palette used unless another is chosen. Other
+// palettes only need the colours they change.
+const DefaultPalette = "default"
+
+// Palette maps the colour symbols pixel frames may use instead of hex
+// values, such as "fur", to hex colours
+type Palette map[string]string
+
+// Color resolves a pixel through the palette. Hex colours (with a leading
+// #), transparent pixels and unknown symbols are returned unchanged.
+func (pal Palette) Color(pixel string) string {
+	if pixel == "" || strings.HasPrefix(pixel, "#") {
+		return pixel
+	}
+	if c, ok := pal[pixel]; ok {
+		return c
+	}
+	return pixel
+}
+
+// Apply returns anim with its pixel symbols replaced by their colours
+func (pal Palette) Apply(anim AnimationConfig) AnimationConfig {
+	if len(pal) == 0 || !anim.Pixel() {
+		return anim
+	}
+	applied := anim
+	applied.Frames = make([]Frame, len(anim.Frames))
+	for i, frame := range anim.Frames {
+		pixels := make([][]string, len(frame.Pixels))
+		for y, row := range frame.Pixels {
+			pixels[y] = make([]string, len(row))
+			for x, px := range row {
+				pixels[y][x] = pal.Color(px)
+			}
+		}
+		applied.Frames[i] = Frame{Art: frame.Art, Pixels: pixels, MS: frame.MS}
+	}
+	return applied
+}
+
+// Palette returns the named palette on top of the default one
+func (c PetConfig) Palette(name string) Palette {
+	pal := Palette{}
+	for symbol, color := range c.Palettes[DefaultPalette] {
+		pal[symbol] = color
+	}
+	for symbol, color := range c.Palettes[name] {
+		pal[symbol] = color
+	}
+	return pal
+}
+
+// ActivePalette is the palette the familiar is drawn with
+func (p *Pet) ActivePalette() Palette {
+	return p.Config.Palette(p.State.Palette)
+}
+
+// hexColor reports whether s is a #RGB or #RRGGBB colour
+func hexColor(s string) bool {
+	if !strings.HasPrefix(s, "#") || (len(s) != 4 && len(s) != 7) {
+		return false
+	}
+	for _, r := range s[1:] {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// blankPixel reports whether a pixel is drawn as nothing: empty,
+// "transparent", or all spaces or all # for alignment
+func blankPixel(px string) bool {
+	return px == "transparent" || strings.Trim(px, " ") == "" || strings.Trim(px, "#") == ""
+}
+
+// Check returns an error for the first pixel of anim's frames that is neither
+// blank, a hex colour nor a symbol of the palette
+func (pal Palette) Check(anim AnimationConfig) error {
+	return pal.checkFrames(anim.Frames)
+}
+
+func (pal Palette) checkFrames(frames []Frame) error {
+	for i, frame := range frames {
+		for y, row := range frame.Pixels {
+			for x, px := range row {
+				if blankPixel(px) || hexColor(px) {
+					continue
+				}
+				if _, ok := pal[px]; ok {
+					continue
+				}
+				if strings.HasPrefix(px, "#") {
+					return fmt.Errorf("frame %d row %d column %d: %q is not a #RGB or #RRGGBB colour", i+1, y+1, x+1, px)
+				}
+				return fmt.Errorf("frame %d row %d column %d: %q is not in the default palette", i+1, y+1, x+1, px)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidatePalettes checks that every palette maps its symbols to hex colours
+// and that every symbol the pixel frames use is in the default palette, which
+// the other palettes add to
+func (c PetConfig) ValidatePalettes() error {
+	for name, pal := range c.Palettes {
+		for symbol, color := range pal {
+			if blankPixel(symbol) || strings.HasPrefix(symbol, "#") {
+				return fmt.Errorf("palette %s: %q cannot be a symbol", name, symbol)
+			}
+			if !hexColor(color) {
+				return fmt.Errorf("palette %s: %s is %q, not a #RGB or #RRGGBB colour", name, symbol, color)
+			}
+		}
+	}
+	pal := c.Palette(DefaultPalette)
+	for key, anim := range c.Animations {
+		if err := pal.Check(anim); err != nil {
+			return fmt.Errorf("animation %s: %w", key, err)
+		}
+	}
+	for name, accessory := range c.Accessories {
+		if err := pal.checkFrames(accessory.Frames); err != nil {
+			return fmt.Errorf("accessory %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,101 @@
 package pet
 
+import (
+	"time"
+)
+
 type Pet struct {
 	Config PetConfig
 	State  PetState
+
+	// User is the identity key of whoever is running familiar. Messages are
+	// acknowledged per user, so each contributor sees them until they have read them.
+	User string
+	// UserAcks holds User's acknowledgements (message id -> time) when the
+	// config keeps them in per-user files instead of the shared state.
+	UserAcks map[string]time.Time
+	// Reminders are User's personal reminders for this familiar, loaded from
+	// their local state. They are never part of the shared state.
+	Reminders []Reminder
+}
+
+// StageAge is how long a by-age familiar spends at each stage after hatching
+const StageAge = 7 * 24 * time.Hour
+
+// ReadyToEvolve reports whether the familiar has met the criteria for its
+// next stage, which Evolve then applies. An egg hatches once it has been fed
+// or played with, so a new egg is not ready. In by-age mode a hatched
+// familiar is ready for stage N+1 once it is N stage ages old, and shows as
+// ready until the next feed or play evolves it.
+func (p *Pet) ReadyToEvolve(now time.Time) bool {
+	if p.State.Evolution >= p.Config.MaxEvolution {
+		return false
+	}
+	if p.State.Evolution == 0 {
+		return p.State.Feeds+p.State.Plays > 0
+	}
+	if p.Config.EvolutionMode != EvolutionModeByAge || p.Config.CreatedAt.IsZero() {
+		return false
+	}
+	return now.Sub(p.Config.CreatedAt) >= time.Duration(p.State.Evolution)*StageAge
+}
+
+// Evolve advances the familiar a stage when it is ready to, reporting whether it did
+func (p *Pet) Evolve(now time.Time) bool {
+	if !p.ReadyToEvolve(now) {
+		return false
+	}
+	p.State.Evolution++
+	return true
+}
+
+// DueReminders returns the current user's reminders that are due, oldest first
+func (p *Pet) DueReminders(now time.Time) []Reminder {
+	local := LocalState{Reminders: p.Reminders}
+	return local.DueReminders(now)
+}
+
+// Acknowledged reports whether the current user has acknowledged m
+func (p *Pet) Acknowledged(m Message) bool {
+	if p.User == "" {
+		return false
+	}
+	if _, ok := p.UserAcks[m.ID]; ok {
+		return true
+	}
+	_, ok := m.AckedAt(p.User)
+	return ok
+}
+
+// PendingMessages returns the active messages the current user has not acknowledged
+func (p *Pet) PendingMessages(now time.Time) []Message {
+	var pending []Message
+	for _, m := range p.State.ActiveMessages(now) {
+		if !p.Acknowledged(m) {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// AcknowledgeMessage records the current user's acknowledgement of a message,
+// in the shared state or the per-user store depending on Config.AckStore
+func (p *Pet) AcknowledgeMessage(id string, now time.Time) (Message, error) {
+	m, err := p.State.FindMessage(id)
+	if err != nil {
+		return Message{}, err
+	}
+	if p.Acknowledged(*m) {
+		return *m, nil
+	}
+
+	if p.Config.AckStore == AckStoreUser {
+		if p.UserAcks == nil {
+			p.UserAcks = make(map[string]time.Time)
+		}
+		p.UserAcks[m.ID] = now
+	} else {
+		m.AckedBy = append(m.AckedBy, Acknowledgement{User: p.User, At: now})
+	}
+	return *m, nil
 }
@@ -0,0 +1,152 @@
+package pet
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/duration"
+	"github.com/sethgrid/familiar/internal/schedule"
+)
+
+// ScheduleAuthor is the author recorded on messages queued by schedules
+const ScheduleAuthor = "familiar schedule"
+
+// Location returns the schedule's time zone, defaulting to local time
+func (s ScheduleConfig) Location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("schedule %s: unknown timezone '%s'", s.ID, s.Timezone)
+	}
+	return loc, nil
+}
+
+// Rule parses the schedule's cron or "every" rule in its time zone
+func (s ScheduleConfig) Rule() (*schedule.Rule, error) {
+	loc, err := s.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	var rule *schedule.Rule
+	switch {
+	case s.Cron != "":
+		rule, err = schedule.ParseCron(s.Cron, loc)
+	case s.Every != "":
+		rule, err = schedule.ParseEvery(s.Every, loc)
+	default:
+		return nil, fmt.Errorf("schedule %s: set either cron or every", s.ID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("schedule %s: %w", s.ID, err)
+	}
+	return rule, nil
+}
+
+// Ended reports whether t falls after the schedule's Until day
+func (s ScheduleConfig) Ended(t time.Time) (bool, error) {
+	if s.Until == "" {
+		return false, nil
+	}
+	loc, err := s.Location()
+	if err != nil {
+		return false, err
+	}
+	until, err := time.ParseInLocation("2006-01-02", s.Until, loc)
+	if err != nil {
+		return false, fmt.Errorf("schedule %s: invalid until date '%s' (expected YYYY-MM-DD)", s.ID, s.Until)
+	}
+	return !t.Before(until.AddDate(0, 0, 1)), nil
+}
+
+// Validate checks that the schedule's rule, time zone, expiry and end date parse
+func (s ScheduleConfig) Validate() error {
+	if s.ID == "" {
+		return fmt.Errorf("schedule is missing an id")
+	}
+	if s.Message == "" {
+		return fmt.Errorf("schedule %s: message is empty", s.ID)
+	}
+	if _, err := s.Rule(); err != nil {
+		return err
+	}
+	if s.Expires != "" {
+		if _, err := duration.Parse(s.Expires); err != nil {
+			return fmt.Errorf("schedule %s: %w", s.ID, err)
+		}
+	}
+	if _, err := s.Ended(time.Time{}); err != nil {
+		return err
+	}
+	if _, err := ParseSeverity(string(s.Severity)); err != nil {
+		return fmt.Errorf("schedule %s: %w", s.ID, err)
+	}
+	return nil
+}
+
+// materializeSchedules queues the most recent occurrence of each schedule that
+// came due since it was last evaluated. Occurrences missed while nobody ran
+// familiar collapse into one message, and are skipped once they would have expired.
+// A schedule seen for the first time only records now, so it never fires retroactively.
+func materializeSchedules(p *Pet, now time.Time) error {
+	if len(p.Config.Schedules) == 0 {
+		return nil
+	}
+	if p.State.ScheduleRuns == nil {
+		p.State.ScheduleRuns = make(map[string]time.Time)
+	}
+
+	for _, s := range p.Config.Schedules {
+		last, seen := p.State.ScheduleRuns[s.ID]
+		p.State.ScheduleRuns[s.ID] = now
+		if !seen || s.Validate() != nil {
+			// Invalid schedules are reported by 'familiar schedule list'
+			continue
+		}
+
+		rule, _ := s.Rule()
+		due := rule.Prev(now)
+		if due.IsZero() || !due.After(last) {
+			continue
+		}
+		if ended, _ := s.Ended(due); ended {
+			continue
+		}
+
+		var expiresAt time.Time
+		if s.Expires != "" {
+			d, _ := duration.Parse(s.Expires)
+			expiresAt = due.Add(d)
+		} else if expiresAt = rule.Next(due); expiresAt.IsZero() {
+			return fmt.Errorf("schedule %s: no occurrence after %s to expire at", s.ID, due.Format(time.RFC3339))
+		}
+		if !expiresAt.After(now) {
+			continue
+		}
+		if p.State.hasScheduledMessage(s.Message, now) {
+			continue
+		}
+
+		p.State.AddMessage(Message{
+			Text:      s.Message,
+			Severity:  s.Severity,
+			Author:    ScheduleAuthor,
+			CreatedAt: due,
+			ExpiresAt: expiresAt,
+		})
+	}
+	return nil
+}
+
+// hasScheduledMessage reports whether an earlier occurrence of a schedule's
+// message is still active, so long expiries do not stack duplicates
+func (s *PetState) hasScheduledMessage(text string, now time.Time) bool {
+	for _, m := range s.ActiveMessages(now) {
+		if m.Author == ScheduleAuthor && m.Text == text {
+			return true
+		}
+	}
+	return false
+}
@@ -17,6 +17,13 @@ type Interaction struct {
 	Action InteractionType `toml:"action"`
 }
 
+type GateBypass struct {
+	MessageID string    `toml:"messageId"`
+	User      string    `toml:"user"`
+	At        time.Time `toml:"at"`
+	Context   string    `toml:"context,omitempty"` // e.g. the git hook that ran the gate
+}
+
 type PetState struct {
 	ConfigRef    string `toml:"configRef"`
 	NameOverride string `toml:"nameOverride"`
@@ -34,14 +41,38 @@ type PetState struct {
 	SleepUntil    time.Time `toml:"sleepUntil"`
 	SleepAttempts int       `toml:"sleepAttempts"` // Tracks attempts to interact while asleep
 
-	Message string `toml:"message"`
+	Messages []Message `toml:"messages"`
+
+	// LegacyMessage is the single message field used before the message queue.
+	// It is migrated into Messages when the state is loaded.
+	LegacyMessage string `toml:"message,omitempty"`
 
 	LastFed     time.Time `toml:"lastFed"`
 	LastPlayed  time.Time `toml:"lastPlayed"`
 	LastVisited time.Time `toml:"lastVisited"`
 	LastChecked time.Time `toml:"lastChecked"`
 
+	// ScheduleRuns records when each schedule (by id) was last evaluated
+	ScheduleRuns map[string]time.Time `toml:"scheduleRuns,omitempty"`
+
+	// GateBypasses logs messages let through 'familiar gate' with --allow
+	GateBypasses []GateBypass `toml:"gateBypasses,omitempty"`
+
 	LastVisits []Interaction `toml:"lastVisits"`
 	LastFeeds  []Interaction `toml:"lastFeeds"`
 	LastPlays  []Interaction `toml:"lastPlays"`
+
+	// Lifetime interaction counts, for achievements
+	Visits int `toml:"visits,omitempty"`
+	Feeds  int `toml:"feeds,omitempty"`
+	Plays  int `toml:"plays,omitempty"`
+
+	// Achievements records when each achievement (by id) was earned
+	Achievements map[string]time.Time `toml:"achievements,omitempty"`
+
+	// Palette is the palette the familiar is drawn with; empty is the default
+	Palette string `toml:"palette,omitempty"`
+
+	// Wearing lists the accessories worn, in the order they were put on
+	Wearing []string `toml:"wearing,omitempty"`
 }
@@ -0,0 +1,124 @@
+package prompt
+
+import (
+	"time"
+
+	"github.com/sethgrid/familiar/internal/health"
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+// minHorizon keeps a familiar sitting on a threshold from recomputing on
+// every prompt
+const minHorizon = time.Minute
+
+// Expiry returns the earliest time after now at which the segment built for p
+// may change although no file has: a reminder falls due, a message expires,
+// sleep ends, a schedule fires, an interaction leaves the lonely window, or
+// decay moves health to another colour or a stat past a condition threshold.
+// The zero time means the segment only changes with the files.
+func Expiry(p *pet.Pet, now time.Time) time.Time {
+	var next time.Time
+	consider := func(t time.Time) {
+		if t.After(now) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+
+	for _, r := range p.Reminders {
+		consider(r.Due)
+	}
+	for _, m := range p.State.Messages {
+		consider(m.ExpiresAt)
+	}
+	if p.State.IsAsleep {
+		consider(p.State.SleepUntil)
+	}
+	for _, sc := range p.Config.Schedules {
+		rule, err := sc.Rule()
+		if err != nil {
+			continue
+		}
+		t := rule.Next(now)
+		if ended, _ := sc.Ended(t); !t.IsZero() && !ended {
+			consider(t)
+		}
+	}
+	// Interactions count towards loneliness for a day
+	for _, events := range [][]pet.Interaction{p.State.LastVisits, p.State.LastFeeds, p.State.LastPlays} {
+		for _, e := range events {
+			consider(e.Time.Add(lonelyWindow))
+		}
+	}
+	if d := decayHorizon(p); d > 0 {
+		consider(now.Add(d))
+	}
+	return next
+}
+
+// lonelyWindow matches the interaction window of the lonely condition
+const lonelyWindow = 24 * time.Hour
+
+// decayHorizon estimates how long awake decay takes to move health below its
+// current ladder step, the stone or infirm threshold, or a stat past one of
+// the condition thresholds. It errs early: clamped stats stop decaying, which
+// only makes the real crossing later.
+func decayHorizon(p *pet.Pet) time.Duration {
+	if !p.Config.DecayEnabled || p.State.IsAsleep || p.State.IsStone {
+		return 0
+	}
+
+	mult := p.Config.DecayRate
+	if p.State.IsInfirm {
+		mult *= p.Config.InfirmDecayMultiplier
+	}
+	hunger := p.Config.HungerDecayPerHour * mult
+	happiness := p.Config.HappinessDecayPerHour * mult
+	energy := p.Config.EnergyDecayPerHour * mult
+
+	var healthPerHour float64
+	switch p.Config.HealthComputation {
+	case pet.HealthComputationWeighted:
+		healthPerHour = hunger*0.3 + happiness*0.4 + energy*0.3
+	default:
+		healthPerHour = (hunger + happiness + energy) / 3
+	}
+
+	h := health.ComputeHealth(p.State.Hunger, p.State.Happiness, p.State.Energy, health.ComputationMode(p.Config.HealthComputation))
+	healthBoundaries := []int{80, 60, 40, 20, p.Config.StoneThreshold}
+	if p.Config.InfirmEnabled {
+		healthBoundaries = append(healthBoundaries, 30)
+	}
+
+	var horizon time.Duration
+	for _, d := range []time.Duration{
+		untilBelow(h, healthPerHour, healthBoundaries...),
+		// Hunger rises, so track its satisfaction score like health
+		untilBelow(100-p.State.Hunger, hunger, 71, 50),
+		untilBelow(p.State.Happiness, happiness, 71, 50),
+		untilBelow(p.State.Energy, energy, 71, 40),
+	} {
+		if d > 0 && (horizon == 0 || d < horizon) {
+			horizon = d
+		}
+	}
+	return horizon
+}
+
+// untilBelow returns how long value, falling at perHour, takes to drop below
+// the highest boundary it has reached, or 0 when it never will
+func untilBelow(value int, perHour float64, boundaries ...int) time.Duration {
+	if perHour <= 0 {
+		return 0
+	}
+	floor := 0
+	for _, b := range boundaries {
+		if b <= value && b > floor {
+			floor = b
+		}
+	}
+	if floor == 0 {
+		return 0
+	}
+	d := time.Duration(float64(value-floor) / perHour * float64(time.Hour))
+	return max(d, minHorizon)
+}
@@ -0,0 +1,132 @@
+package prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/sethgrid/familiar/internal/term"
+)
+
+// Presets are the built-in --format values. Each one escapes colours the way
+// its prompt expects, so the output can be used without post-processing.
+var Presets = map[string]string{
+	// ansi is the default: raw escape sequences, fine for fish and most status lines
+	"ansi": `{{.String}}`,
+	// bash marks escapes with readline's \001 \002, which work in PS1 command
+	// substitutions where \[ \] would be printed literally
+	"bash": `{{with .Icon}}{{.}} {{end}}{{with .Color}}{{bash (fg .)}}{{end}}{{.Glyph}}{{.CountText}}{{with .Color}}{{bash reset}}{{end}}{{.ReminderText}}`,
+	// zsh wraps escapes in %{ %} for PROMPT and p10k segments
+	"zsh": `{{with .Icon}}{{.}} {{end}}{{with .Color}}{{zsh (fg .)}}{{end}}{{.Glyph}}{{.CountText}}{{with .Color}}{{zsh reset}}{{end}}{{.ReminderText}}`,
+	// fish measures escape sequences itself
+	"fish": `{{.String}}`,
+	// starship styles custom modules itself, so its output carries no colour
+	"starship": `{{with .Icon}}{{.}} {{end}}{{.Glyph}}{{.CountText}}{{.ReminderText}}`,
+	// tmux uses its own #[fg=...] style syntax, and # must be doubled
+	"tmux": `{{with .Icon}}{{tmuxText .}} {{end}}{{with .Color}}#[fg={{tmux .}}]{{end}}{{tmuxText .Glyph}}{{.CountText}}{{with .Color}}#[default]{{end}}{{tmuxText .ReminderText}}`,
+	"json": `{{json .}}`,
+}
+
+// PresetNames returns the preset names in order
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// templateFuncs are available to --format templates
+var templateFuncs = template.FuncMap{
+	// fg returns the escape sequence that starts a colour, e.g. {{fg .Color}}
+	"fg": func(color string) string {
+		if color == "" {
+			return ""
+		}
+		return "\033[" + color + "m"
+	},
+	"reset": func() string { return "\033[0m" },
+	// bash and zsh mark escape sequences as zero-width for the shell
+	"bash": func(s string) string { return wrapNonEmpty(s, "\001", "\002") },
+	"zsh":  func(s string) string { return wrapNonEmpty(s, "%{", "%}") },
+	// tmux converts a colour to a tmux colour name, e.g. colour208 or #ff8800
+	"tmux":     TmuxColor,
+	"tmuxText": func(s string) string { return strings.ReplaceAll(s, "#", "##") },
+	"hex":      HexColor,
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+func wrapNonEmpty(s, open, close string) string {
+	if s == "" {
+		return ""
+	}
+	return open + s + close
+}
+
+// Format renders the segment with a preset name or a text/template
+func Format(seg Segment, format string) (string, error) {
+	text, ok := Presets[format]
+	if !ok {
+		text = format
+	}
+	tmpl, err := template.New("prompt").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid format: %w", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, seg); err != nil {
+		return "", fmt.Errorf("invalid format: %w", err)
+	}
+	return b.String(), nil
+}
+
+// MarshalJSON renders the segment for the json preset, with the colour as hex
+func (s Segment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name      string `json:"name"`
+		Health    int    `json:"health"`
+		Condition string `json:"condition"`
+		State     string `json:"state"`
+		Icon      string `json:"icon"`
+		Glyph     string `json:"glyph"`
+		Color     string `json:"color"`
+		Messages  int    `json:"messages"`
+		Reminders int    `json:"reminders"`
+	}{s.Name, s.Health, s.Condition, s.State, s.Icon, s.Glyph, HexColor(s.Color), s.Count, s.Reminders})
+}
+
+var basicNames = [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// TmuxColor converts SGR parameters to a tmux colour, or "default"
+func TmuxColor(color string) string {
+	c, ok := term.ParseSGR(color)
+	if !ok {
+		return "default"
+	}
+	switch c.Depth {
+	case term.Depth16:
+		if c.Index >= 8 {
+			return "bright" + basicNames[c.Index-8]
+		}
+		return basicNames[c.Index]
+	case term.Depth256:
+		return "colour" + strconv.Itoa(c.Index)
+	}
+	return c.Hex()
+}
+
+// HexColor converts SGR parameters to "#rrggbb", or "" for no colour
+func HexColor(color string) string {
+	c, ok := term.ParseSGR(color)
+	if !ok {
+		return ""
+	}
+	return c.Hex()
+}
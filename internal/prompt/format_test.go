@@ -0,0 +1,101 @@
+package prompt
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+func warnSegment() Segment {
+	now := time.Now()
+	p := testPet()
+	p.State.AddMessage(pet.Message{Text: "a", Severity: pet.SeverityWarn, CreatedAt: now})
+	p.State.AddMessage(pet.Message{Text: "b", CreatedAt: now})
+	return Build(p, now)
+}
+
+func TestFormatPresets(t *testing.T) {
+	seg := warnSegment()
+	tests := map[string]string{
+		"ansi":     "🐾 \033[38;5;208m▲2\033[0m",
+		"fish":     "🐾 \033[38;5;208m▲2\033[0m",
+		"bash":     "🐾 \001\033[38;5;208m\002▲2\001\033[0m\002",
+		"zsh":      "🐾 %{\033[38;5;208m%}▲2%{\033[0m%}",
+		"starship": "🐾 ▲2",
+		"tmux":     "🐾 #[fg=colour208]▲2#[default]",
+	}
+	for preset, want := range tests {
+		got, err := Format(seg, preset)
+		if err != nil {
+			t.Fatalf("Format(%s): %v", preset, err)
+		}
+		if got != want {
+			t.Errorf("Format(%s) = %q, want %q", preset, got, want)
+		}
+	}
+}
+
+func TestFormatTmuxEscapesHash(t *testing.T) {
+	seg := Segment{Icon: "#", Glyph: "#", Color: "90"}
+	got, err := Format(seg, "tmux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "## #[fg=brightblack]###[default]"; got != want {
+		t.Errorf("Format(tmux) = %q, want %q", got, want)
+	}
+}
+
+func TestFormatJSON(t *testing.T) {
+	out, err := Format(warnSegment(), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if got["name"] != "Pip" || got["state"] != StateWarn || got["messages"] != float64(2) || got["color"] != "#ff8700" {
+		t.Errorf("unexpected JSON: %s", out)
+	}
+	if got["condition"] != "has-message" {
+		t.Errorf("condition = %v, want has-message", got["condition"])
+	}
+}
+
+func TestFormatTemplate(t *testing.T) {
+	got, err := Format(warnSegment(), `{{.Name}} {{.Health}} {{zsh (fg .Color)}}{{.Count}}{{zsh reset}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Pip 100 %{\033[38;5;208m%}2%{\033[0m%}"; got != want {
+		t.Errorf("Format = %q, want %q", got, want)
+	}
+
+	if _, err := Format(warnSegment(), "{{.Missing}}"); err == nil || !strings.Contains(err.Error(), "invalid format") {
+		t.Errorf("expected an invalid format error, got %v", err)
+	}
+}
+
+func TestColorConversions(t *testing.T) {
+	tests := []struct{ sgr, tmux, hex string }{
+		{"32", "green", "#00cd00"},
+		{"93", "brightyellow", "#ffff00"},
+		{"1;31", "red", "#cd0000"},
+		{"38;5;208", "colour208", "#ff8700"},
+		{"38;5;244", "colour244", "#808080"},
+		{"38;2;0;255;128", "#00ff80", "#00ff80"},
+		{"", "default", ""},
+	}
+	for _, tt := range tests {
+		if got := TmuxColor(tt.sgr); got != tt.tmux {
+			t.Errorf("TmuxColor(%q) = %q, want %q", tt.sgr, got, tt.tmux)
+		}
+		if got := HexColor(tt.sgr); got != tt.hex {
+			t.Errorf("HexColor(%q) = %q, want %q", tt.sgr, got, tt.hex)
+		}
+	}
+}
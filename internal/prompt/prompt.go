@@ -0,0 +1,280 @@
+package prompt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/conditions"
+	"github.com/sethgrid/familiar/internal/health"
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+// Prompt states, used as glyph and colour keys
+const (
+	StateHealth         = "health"
+	StateMessage        = "message"
+	StateWarn           = "warn"
+	StateCritical       = "critical"
+	StateReminder       = "reminder"
+	StateAsleep         = "asleep"
+	StateStone          = "stone"
+	StateEvolutionReady = "evolution-ready"
+)
+
+// Health ladder colour keys
+const (
+	HealthExcellent = "excellent"
+	HealthGood      = "good"
+	HealthFair      = "fair"
+	HealthPoor      = "poor"
+	HealthFailing   = "failing"
+)
+
+// Count modes for unread messages and due reminders
+const (
+	CountAuto   = "auto"   // show counts above one
+	CountAlways = "always" // show counts, including 1
+	CountNever  = "never"
+)
+
+// Theme is a set of glyphs and colours for the prompt segment
+type Theme struct {
+	Icon   string
+	Glyphs map[string]string
+	Colors map[string]string // SGR parameters
+}
+
+// Themes are the built-in themes, selected with [prompt] theme in pet.toml
+var Themes = map[string]Theme{
+	"default": {
+		Icon: "🐾",
+		Glyphs: map[string]string{
+			StateHealth:         "●",
+			StateMessage:        "▲",
+			StateWarn:           "▲",
+			StateCritical:       "‼",
+			StateReminder:       "⏰",
+			StateAsleep:         "☾",
+			StateStone:          "■",
+			StateEvolutionReady: "✦",
+		},
+		Colors: defaultColors,
+	},
+	"ascii": {
+		Icon: "@",
+		Glyphs: map[string]string{
+			StateHealth:         "o",
+			StateMessage:        "!",
+			StateWarn:           "!",
+			StateCritical:       "!!",
+			StateReminder:       "*",
+			StateAsleep:         "z",
+			StateStone:          "#",
+			StateEvolutionReady: "^",
+		},
+		Colors: defaultColors,
+	},
+	// minimal drops the icon and keeps only the state glyph
+	"minimal": {
+		Glyphs: map[string]string{
+			StateHealth:         "●",
+			StateMessage:        "▲",
+			StateWarn:           "▲",
+			StateCritical:       "‼",
+			StateReminder:       "⏰",
+			StateAsleep:         "☾",
+			StateStone:          "■",
+			StateEvolutionReady: "✦",
+		},
+		Colors: defaultColors,
+	},
+}
+
+var defaultColors = map[string]string{
+	HealthExcellent:     "32",       // Green
+	HealthGood:          "33",       // Yellow
+	HealthFair:          "93",       // Bright Yellow
+	HealthPoor:          "38;5;208", // Orange
+	HealthFailing:       "31",       // Red
+	StateMessage:        "",         // keeps the health colour
+	StateWarn:           "38;5;208", // Orange
+	StateCritical:       "31",       // Red
+	StateStone:          "90",       // Gray
+	StateAsleep:         "34",       // Blue
+	StateEvolutionReady: "35",       // Magenta
+	StateReminder:       "",
+}
+
+// Segment is the computed prompt segment for a familiar. It is the data
+// available to --format templates.
+type Segment struct {
+	Icon      string `toml:"icon"`
+	State     string `toml:"state"` // one of the State constants other than StateReminder
+	Glyph     string `toml:"glyph"`
+	Color     string `toml:"color"`     // SGR parameters, empty for the terminal default
+	Count     int    `toml:"count"`     // unread messages
+	Reminders int    `toml:"reminders"` // due reminders
+	Health    int    `toml:"health"`
+	Name      string `toml:"name"`
+	Condition string `toml:"condition"` // primary condition, as in 'familiar status'
+
+	ReminderGlyph string `toml:"reminderGlyph"`
+	CountMode     string `toml:"countMode"`
+}
+
+// ResolveTheme returns the configured theme with the pet's overrides applied
+func ResolveTheme(cfg pet.PromptConfig) Theme {
+	base, ok := Themes[cfg.Theme]
+	if !ok {
+		base = Themes["default"]
+	}
+
+	theme := Theme{Icon: base.Icon, Glyphs: make(map[string]string), Colors: make(map[string]string)}
+	for k, v := range base.Glyphs {
+		theme.Glyphs[k] = v
+	}
+	for k, v := range base.Colors {
+		theme.Colors[k] = v
+	}
+	if cfg.Icon != "" {
+		theme.Icon = cfg.Icon
+	}
+	for k, v := range cfg.Glyphs {
+		theme.Glyphs[k] = v
+	}
+	for k, v := range cfg.Colors {
+		theme.Colors[k] = ParseColor(v)
+	}
+	return theme
+}
+
+// Build computes the prompt segment for p. The state glyph is chosen in
+// priority order: critical, warn and info messages, stone, asleep, ready to
+// evolve, then health.
+func Build(p *pet.Pet, now time.Time) Segment {
+	theme := ResolveTheme(p.Config.Prompt)
+	h := health.ComputeHealth(p.State.Hunger, p.State.Happiness, p.State.Energy, health.ComputationMode(p.Config.HealthComputation))
+
+	name := p.Config.Name
+	if p.State.NameOverride != "" {
+		name = p.State.NameOverride
+	}
+
+	seg := Segment{
+		Icon:          theme.Icon,
+		Health:        h,
+		Name:          name,
+		Reminders:     len(p.DueReminders(now)),
+		Condition:     string(conditions.DeriveStatus(p, now, h).Primary),
+		ReminderGlyph: theme.Glyphs[StateReminder],
+		CountMode:     p.Config.Prompt.Count,
+	}
+
+	// Same stone check as conditions: IsStone OR health < threshold
+	isStone := p.State.IsStone || h < p.Config.StoneThreshold
+	messages := p.PendingMessages(now)
+	seg.Count = len(messages)
+
+	switch {
+	case len(messages) > 0:
+		switch pet.HighestSeverity(messages) {
+		case pet.SeverityCritical:
+			seg.State = StateCritical
+		case pet.SeverityWarn:
+			seg.State = StateWarn
+		default:
+			seg.State = StateMessage
+		}
+	case isStone:
+		seg.State = StateStone
+	case p.State.IsAsleep:
+		seg.State = StateAsleep
+	case p.ReadyToEvolve(now):
+		seg.State = StateEvolutionReady
+	default:
+		seg.State = StateHealth
+	}
+
+	seg.Glyph = theme.Glyphs[seg.State]
+	seg.Color = theme.Colors[seg.State]
+	if seg.Color == "" {
+		// Info messages, health and unstyled states use the health ladder
+		seg.Color = theme.Colors[healthLevel(h, isStone)]
+	}
+	return seg
+}
+
+// healthLevel maps health to its ladder colour key
+func healthLevel(h int, isStone bool) string {
+	switch {
+	case isStone:
+		return StateStone
+	case h >= 80:
+		return HealthExcellent
+	case h >= 60:
+		return HealthGood
+	case h >= 40:
+		return HealthFair
+	case h >= 20:
+		return HealthPoor
+	default:
+		return HealthFailing
+	}
+}
+
+// String renders the segment with ANSI colour, e.g. "🐾 \033[32m●\033[0m"
+func (s Segment) String() string {
+	var b strings.Builder
+	if s.Icon != "" {
+		b.WriteString(s.Icon)
+		b.WriteString(" ")
+	}
+	if s.Color != "" {
+		b.WriteString("\033[" + s.Color + "m")
+	}
+	b.WriteString(s.Glyph)
+	b.WriteString(s.CountText())
+	if s.Color != "" {
+		b.WriteString("\033[0m")
+	}
+	b.WriteString(s.ReminderText())
+	return b.String()
+}
+
+// CountText returns the unread count to show after the glyph, if any
+func (s Segment) CountText() string {
+	return countText(s.Count, s.CountMode)
+}
+
+// ReminderText returns the reminder glyph and count, or "" when none are due
+func (s Segment) ReminderText() string {
+	if s.Reminders == 0 {
+		return ""
+	}
+	return s.ReminderGlyph + countText(s.Reminders, s.CountMode)
+}
+
+func countText(n int, mode string) string {
+	switch {
+	case n == 0 || mode == CountNever:
+		return ""
+	case n == 1 && mode != CountAlways:
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// ParseColor converts a "#rrggbb" colour to 24-bit SGR parameters and passes
+// SGR parameters through unchanged
+func ParseColor(c string) string {
+	if !strings.HasPrefix(c, "#") || len(c) != 7 {
+		return c
+	}
+	v, err := strconv.ParseUint(c[1:], 16, 32)
+	if err != nil {
+		return c
+	}
+	return fmt.Sprintf("38;2;%d;%d;%d", v>>16, (v>>8)&0xff, v&0xff)
+}
@@ -0,0 +1,136 @@
+package prompt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+func testPet() *pet.Pet {
+	return &pet.Pet{
+		Config: pet.PetConfig{Name: "Pip", StoneThreshold: 10, MaxEvolution: 5, HealthComputation: "average"},
+		State:  pet.PetState{Hunger: 0, Happiness: 100, Energy: 100, Evolution: 1},
+		User:   "me@example.com",
+	}
+}
+
+func TestBuildStates(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		setup func(p *pet.Pet)
+		state string
+		glyph string
+		color string
+	}{
+		{"healthy", func(p *pet.Pet) {}, StateHealth, "●", "32"},
+		{"new egg", func(p *pet.Pet) { p.State.Evolution = 0 }, StateHealth, "●", "32"},
+		{"week-old familiar", func(p *pet.Pet) {
+			p.Config.EvolutionMode, p.Config.CreatedAt = pet.EvolutionModeByAge, now.Add(-pet.StageAge)
+		}, StateEvolutionReady, "✦", "35"},
+		{"young familiar", func(p *pet.Pet) {
+			p.Config.EvolutionMode, p.Config.CreatedAt = pet.EvolutionModeByAge, now.Add(-pet.StageAge/2)
+		}, StateHealth, "●", "32"},
+		{"asleep", func(p *pet.Pet) { p.State.IsAsleep = true }, StateAsleep, "☾", "34"},
+		{"stone", func(p *pet.Pet) { p.State.IsStone = true }, StateStone, "■", "90"},
+		{"info message keeps health colour", func(p *pet.Pet) {
+			p.State.AddMessage(pet.Message{Text: "hi", CreatedAt: now})
+		}, StateMessage, "▲", "32"},
+		{"critical wins", func(p *pet.Pet) {
+			p.State.AddMessage(pet.Message{Text: "hi", CreatedAt: now})
+			p.State.AddMessage(pet.Message{Text: "down", Severity: pet.SeverityCritical, CreatedAt: now})
+			p.State.IsStone = true
+		}, StateCritical, "‼", "31"},
+	}
+
+	for _, tt := range tests {
+		p := testPet()
+		tt.setup(p)
+		seg := Build(p, now)
+		if seg.State != tt.state || seg.Glyph != tt.glyph || seg.Color != tt.color {
+			t.Errorf("%s: got state=%s glyph=%s color=%s, want %s %s %s", tt.name, seg.State, seg.Glyph, seg.Color, tt.state, tt.glyph, tt.color)
+		}
+	}
+}
+
+func TestSegmentString(t *testing.T) {
+	now := time.Now()
+	p := testPet()
+	p.State.AddMessage(pet.Message{Text: "a", Severity: pet.SeverityWarn, CreatedAt: now})
+	p.State.AddMessage(pet.Message{Text: "b", CreatedAt: now})
+	p.Reminders = []pet.Reminder{{ID: "r1", Text: "call", Due: now.Add(-time.Minute)}}
+
+	if got, want := Build(p, now).String(), "🐾 \033[38;5;208m▲2\033[0m⏰"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	p.Config.Prompt = pet.PromptConfig{Theme: "minimal", Count: CountNever}
+	if got, want := Build(p, now).String(), "\033[38;5;208m▲\033[0m⏰"; got != want {
+		t.Errorf("minimal String() = %q, want %q", got, want)
+	}
+}
+
+func TestThemeOverrides(t *testing.T) {
+	p := testPet()
+	p.Config.Prompt = pet.PromptConfig{
+		Theme:  "ascii",
+		Icon:   "🐈",
+		Count:  CountAlways,
+		Glyphs: map[string]string{StateHealth: "+"},
+		Colors: map[string]string{HealthExcellent: "#00ff80"},
+	}
+	p.Reminders = []pet.Reminder{{ID: "r1", Due: time.Now().Add(-time.Minute)}}
+
+	if got, want := Build(p, time.Now()).String(), "🐈 \033[38;2;0;255;128m+\033[0m*1"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	p := testPet()
+	if got := Expiry(p, now); !got.IsZero() {
+		t.Errorf("Expiry without decay or events = %v, want zero", got)
+	}
+
+	p.Reminders = []pet.Reminder{{ID: "r1", Due: now.Add(3 * time.Hour)}, {ID: "r2", Due: now.Add(-time.Hour)}}
+	p.State.AddMessage(pet.Message{Text: "a", CreatedAt: now, ExpiresAt: now.Add(2 * time.Hour)})
+	if got, want := Expiry(p, now), now.Add(2*time.Hour); !got.Equal(want) {
+		t.Errorf("Expiry = %v, want message expiry %v", got, want)
+	}
+
+	p.Config.Schedules = []pet.ScheduleConfig{{ID: "s", Message: "m", Every: "day 10:30", Timezone: "UTC"}}
+	if got, want := Expiry(p, now), now.Add(30*time.Minute); !got.Equal(want) {
+		t.Errorf("Expiry = %v, want next schedule %v", got, want)
+	}
+}
+
+func TestExpiryHealthHorizon(t *testing.T) {
+	now := time.Now()
+	p := testPet()
+	p.Config.DecayEnabled = true
+	p.Config.DecayRate = 1
+	p.Config.HungerDecayPerHour = 2
+	p.Config.HappinessDecayPerHour = 2
+	p.Config.EnergyDecayPerHour = 2
+
+	// Health 90 loses 2 points an hour and leaves "excellent" below 80
+	p.State = pet.PetState{Hunger: 10, Happiness: 90, Energy: 90, Evolution: 1}
+	if got, want := Expiry(p, now), now.Add(5*time.Hour); !got.Equal(want) {
+		t.Errorf("Expiry = %v, want %v", got, want)
+	}
+
+	// Sitting on a boundary still waits a little
+	p.State = pet.PetState{Hunger: 20, Happiness: 80, Energy: 80, Evolution: 1}
+	if got, want := Expiry(p, now), now.Add(minHorizon); !got.Equal(want) {
+		t.Errorf("Expiry on boundary = %v, want %v", got, want)
+	}
+
+	// Stone familiars no longer change colour
+	p.State.IsStone = true
+	if got := Expiry(p, now); !got.IsZero() {
+		t.Errorf("Expiry for stone = %v, want zero", got)
+	}
+}
@@ -0,0 +1,272 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule is a parsed five-field cron expression (minute hour day-of-month month day-of-week)
+type Rule struct {
+	expr    string
+	minute  uint64
+	hour    uint64
+	dom     uint64
+	month   uint64
+	dow     uint64
+	anyDom  bool
+	anyDow  bool
+	loc     *time.Location
+	display string
+}
+
+var (
+	monthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	dayNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
+// maxSearch bounds Next/Prev so impossible rules (e.g. "0 0 31 2 *") terminate
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// ParseCron parses a cron expression evaluated in loc (nil means UTC)
+func ParseCron(expr string, loc *time.Location) (*Rule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression '%s' must have 5 fields", expr)
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	r := &Rule{expr: strings.Join(fields, " "), loc: loc, display: strings.Join(fields, " ")}
+	var err error
+	if r.minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if r.hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if r.dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if r.month, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if r.dow, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	// 7 is an alias for Sunday
+	if r.dow&(1<<7) != 0 {
+		r.dow |= 1
+	}
+	// As in cron, a field starting with * (such as */2) is unrestricted for
+	// the OR of day-of-month and day-of-week
+	r.anyDom = strings.HasPrefix(fields[2], "*")
+	r.anyDow = strings.HasPrefix(fields[4], "*")
+	return r, nil
+}
+
+// ParseEvery parses a simple rule such as "monday 09:00", "weekday 17:30",
+// "mon,thu 10:00", "day 08:00", "weekend" or "hour". A leading "every" is optional.
+func ParseEvery(spec string, loc *time.Location) (*Rule, error) {
+	words := strings.Fields(strings.ToLower(spec))
+	if len(words) > 0 && words[0] == "every" {
+		words = words[1:]
+	}
+	if len(words) == 0 || len(words) > 2 {
+		return nil, fmt.Errorf("cannot parse schedule 'every %s' (try 'every monday 09:00')", spec)
+	}
+
+	if words[0] == "hour" {
+		if len(words) > 1 {
+			return nil, fmt.Errorf("'every hour' does not take a time")
+		}
+		return everyRule("0 * * * *", spec, loc)
+	}
+
+	hour, minute := 0, 0
+	if len(words) == 2 {
+		t, err := time.Parse("15:04", words[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid time '%s' (expected HH:MM)", words[1])
+		}
+		hour, minute = t.Hour(), t.Minute()
+	}
+
+	var dow string
+	switch words[0] {
+	case "day", "daily":
+		dow = "*"
+	case "weekday", "weekdays":
+		dow = "1-5"
+	case "weekend", "weekends":
+		dow = "0,6"
+	default:
+		var days []string
+		for _, name := range strings.Split(words[0], ",") {
+			name = strings.TrimSuffix(name, "s") // "mondays"
+			if len(name) < 3 {
+				return nil, fmt.Errorf("unknown day '%s'", name)
+			}
+			if _, ok := dayNames[name[:3]]; !ok {
+				return nil, fmt.Errorf("unknown day '%s'", name)
+			}
+			days = append(days, name[:3])
+		}
+		dow = strings.Join(days, ",")
+	}
+
+	return everyRule(fmt.Sprintf("%d %d * * %s", minute, hour, dow), spec, loc)
+}
+
+// everyRule parses the cron equivalent of an "every" rule, keeping the
+// human-readable form for listings
+func everyRule(expr, spec string, loc *time.Location) (*Rule, error) {
+	r, err := ParseCron(expr, loc)
+	if err != nil {
+		return nil, err
+	}
+	spec = strings.Join(strings.Fields(spec), " ")
+	if !strings.HasPrefix(strings.ToLower(spec), "every ") {
+		spec = "every " + spec
+	}
+	r.display = spec
+	return r, nil
+}
+
+// String returns the rule as written ("every monday 09:00" or the cron expression)
+func (r *Rule) String() string {
+	return r.display
+}
+
+// Cron returns the equivalent cron expression
+func (r *Rule) Cron() string {
+	return r.expr
+}
+
+// Location returns the time zone the rule is evaluated in
+func (r *Rule) Location() *time.Location {
+	return r.loc
+}
+
+// Matches reports whether t (to the minute) satisfies the rule
+func (r *Rule) Matches(t time.Time) bool {
+	t = t.In(r.loc)
+	return has(r.minute, t.Minute()) && has(r.hour, t.Hour()) && has(r.month, int(t.Month())) && r.dayMatches(t)
+}
+
+// dayMatches applies cron's rule that a restricted day-of-month and a
+// restricted day-of-week are OR-ed together
+func (r *Rule) dayMatches(t time.Time) bool {
+	domOK := has(r.dom, t.Day())
+	dowOK := has(r.dow, int(t.Weekday()))
+	if r.anyDom || r.anyDow {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+// Next returns the first matching time strictly after t, or the zero time if none is found
+func (r *Rule) Next(t time.Time) time.Time {
+	t = t.In(r.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+	for t.Before(limit) {
+		switch {
+		case !has(r.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, r.loc)
+		case !r.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, r.loc)
+		case !has(r.hour, t.Hour()):
+			// Step by the local clock: Truncate works in absolute time, which
+			// lands on :30 in zones such as Asia/Kolkata
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, r.loc)
+		case !has(r.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Prev returns the last matching time at or before t, or the zero time if none is found
+func (r *Rule) Prev(t time.Time) time.Time {
+	t = t.In(r.loc).Truncate(time.Minute)
+	limit := t.Add(-maxSearch)
+	for t.After(limit) {
+		switch {
+		case !has(r.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, r.loc).Add(-time.Minute)
+		case !r.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, r.loc).Add(-time.Minute)
+		case !has(r.hour, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, r.loc).Add(-time.Minute)
+		case !has(r.minute, t.Minute()):
+			t = t.Add(-time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
+
+// parseField parses one cron field: "*", "5", "1-5", "*/15", "1-30/2", "mon,wed", ...
+func parseField(field string, min, max int, names map[string]int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(strings.ToLower(field), ",") {
+		step := 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			s, err := strconv.Atoi(part[idx+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("invalid step in '%s'", part)
+			}
+			step = s
+			part = part[:idx]
+		}
+
+		lo, hi := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = parseValue(bounds[0], names); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = parseValue(bounds[1], names); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("'%s' is out of range %d-%d", field, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func parseValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[s]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value '%s'", s)
+	}
+	return v, nil
+}
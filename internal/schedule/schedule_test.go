@@ -0,0 +1,127 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * * foo *"} {
+		if _, err := ParseCron(expr, time.UTC); err == nil {
+			t.Errorf("ParseCron(%q) should fail", expr)
+		}
+	}
+}
+
+func TestNextAndPrev(t *testing.T) {
+	// Wednesday
+	base := time.Date(2026, 3, 11, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		next string
+		prev string
+	}{
+		{"0 9 * * 1", "2026-03-16 09:00", "2026-03-09 09:00"},
+		{"*/15 * * * *", "2026-03-11 10:45", "2026-03-11 10:30"},
+		{"0 9 1 * *", "2026-04-01 09:00", "2026-03-01 09:00"},
+		{"30 17 * * mon-fri", "2026-03-11 17:30", "2026-03-10 17:30"},
+		{"0 0 * * 7", "2026-03-15 00:00", "2026-03-08 00:00"},
+		{"0 12 29 feb *", "2028-02-29 12:00", "2024-02-29 12:00"},
+		// Restricted day-of-month and day-of-week are OR-ed: the 13th or any Friday
+		{"0 8 13 * fri", "2026-03-13 08:00", "2026-03-06 08:00"},
+		// A stepped day-of-month starts with *, so it is AND-ed like *: odd days that are Mondays
+		{"0 9 */2 * mon", "2026-03-23 09:00", "2026-03-09 09:00"},
+	}
+
+	for _, tt := range tests {
+		r, err := ParseCron(tt.expr, time.UTC)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		if got := r.Next(base).Format("2006-01-02 15:04"); got != tt.next {
+			t.Errorf("%q Next = %s, want %s", tt.expr, got, tt.next)
+		}
+		if got := r.Prev(base).Format("2006-01-02 15:04"); got != tt.prev {
+			t.Errorf("%q Prev = %s, want %s", tt.expr, got, tt.prev)
+		}
+	}
+}
+
+func TestHalfHourZones(t *testing.T) {
+	for _, name := range []string{"Asia/Kolkata", "Australia/Adelaide", "America/St_Johns"} {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Skipf("No zone data for %s: %v", name, err)
+		}
+		r, err := ParseEvery("monday 09:00", loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Wednesday
+		base := time.Date(2026, 3, 11, 10, 30, 0, 0, loc)
+		if got := r.Next(base); !got.Equal(time.Date(2026, 3, 16, 9, 0, 0, 0, loc)) {
+			t.Errorf("%s Next = %v, want Monday 09:00", name, got)
+		}
+		if got := r.Prev(base); !got.Equal(time.Date(2026, 3, 9, 9, 0, 0, 0, loc)) {
+			t.Errorf("%s Prev = %v, want Monday 09:00", name, got)
+		}
+	}
+}
+
+func TestImpossibleRule(t *testing.T) {
+	r, err := ParseCron("0 0 31 2 *", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := r.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Expected no occurrence, got %v", next)
+	}
+}
+
+func TestParseEvery(t *testing.T) {
+	tests := []struct {
+		spec string
+		cron string
+	}{
+		{"every Monday 09:00", "0 9 * * mon"},
+		{"mondays 9:05", "5 9 * * mon"},
+		{"every weekday 17:30", "30 17 * * 1-5"},
+		{"every weekend", "0 0 * * 0,6"},
+		{"every day 08:00", "0 8 * * *"},
+		{"every mon,thu 10:00", "0 10 * * mon,thu"},
+		{"every hour", "0 * * * *"},
+	}
+	for _, tt := range tests {
+		r, err := ParseEvery(tt.spec, nil)
+		if err != nil {
+			t.Errorf("ParseEvery(%q): %v", tt.spec, err)
+			continue
+		}
+		if r.Cron() != tt.cron {
+			t.Errorf("ParseEvery(%q) = %q, want %q", tt.spec, r.Cron(), tt.cron)
+		}
+	}
+
+	for _, spec := range []string{"", "every", "every someday 09:00", "every monday 9am", "every hour 10:00"} {
+		if _, err := ParseEvery(spec, nil); err == nil {
+			t.Errorf("ParseEvery(%q) should fail", spec)
+		}
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	r, err := ParseEvery("monday 09:00", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := r.Next(time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC))
+	// 09:00 EDT is 13:00 UTC
+	if got := next.UTC().Format("2006-01-02 15:04"); got != "2026-03-16 13:00" {
+		t.Errorf("Next = %s, want 2026-03-16 13:00 UTC", got)
+	}
+}
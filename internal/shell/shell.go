@@ -0,0 +1,318 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Names are the shells 'familiar shell init' supports
+var Names = []string{"bash", "zsh", "fish"}
+
+// Version identifies the generated integration. Bump it when the snippets
+// change so 'familiar shell doctor' can report shells running an old one.
+const Version = 2
+
+// Environment variables exported by the integration, read back by Detect
+const (
+	EnvShell    = "FAMILIAR_SHELL"
+	EnvVersion  = "FAMILIAR_SHELL_INTEGRATION"
+	EnvFeatures = "FAMILIAR_SHELL_FEATURES"
+	EnvPID      = "FAMILIAR_SHELL_PID"
+)
+
+// Features of the integration
+const (
+	FeaturePrompt     = "prompt"
+	FeatureVisits     = "visits"
+	FeatureAsync      = "async"
+	FeatureCompletion = "completion"
+)
+
+// Options selects what the snippet installs
+type Options struct {
+	Prompt     bool // prepend the prompt segment to PS1, PROMPT or fish_prompt
+	Visits     bool // record a visit when the working directory changes
+	Async      bool // refresh the segment in the background, showing the previous result
+	Completion bool // load familiar's completions
+	// VisitThrottle is passed to 'familiar visit --throttle', e.g. "1h"
+	VisitThrottle string
+}
+
+// DefaultOptions enables everything except async refresh
+func DefaultOptions() Options {
+	return Options{Prompt: true, Visits: true, Completion: true, VisitThrottle: "1h"}
+}
+
+// Features lists the enabled features, in the form exported as FAMILIAR_SHELL_FEATURES
+func (o Options) Features() []string {
+	var features []string
+	for _, f := range []struct {
+		on   bool
+		name string
+	}{
+		{o.Prompt, FeaturePrompt},
+		{o.Visits, FeatureVisits},
+		{o.Async, FeatureAsync},
+		{o.Completion, FeatureCompletion},
+	} {
+		if f.on {
+			features = append(features, f.name)
+		}
+	}
+	return features
+}
+
+// Init returns the snippet to eval in the given shell's startup file
+func Init(shell string, opts Options) (string, error) {
+	tmpl, ok := templates[shell]
+	if !ok {
+		return "", fmt.Errorf("unsupported shell '%s' (expected %s)", shell, strings.Join(Names, ", "))
+	}
+	var b strings.Builder
+	err := tmpl.Execute(&b, struct {
+		Options
+		Shell    string
+		Version  int
+		Features string
+		Load     string
+	}{opts, shell, Version, strings.Join(opts.Features(), ","), LoadLine(shell)})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate %s integration: %w", shell, err)
+	}
+	return b.String(), nil
+}
+
+// LoadLine is the line that loads the integration from a startup file
+func LoadLine(shell string) string {
+	if shell == "fish" {
+		return "familiar shell init fish | source"
+	}
+	return fmt.Sprintf(`eval "$(familiar shell init %s)"`, shell)
+}
+
+// StartupFiles returns the files a shell reads at startup, relative to home
+func StartupFiles(shell string) []string {
+	switch shell {
+	case "bash":
+		return []string{".bashrc", ".bash_profile", ".profile"}
+	case "zsh":
+		return []string{".zshrc", ".zprofile"}
+	case "fish":
+		return []string{filepath.Join(".config", "fish", "config.fish"), filepath.Join(".config", "fish", "conf.d", "familiar.fish")}
+	}
+	return nil
+}
+
+// Persisted returns the first startup file under home that loads the integration
+func Persisted(shell, home string) (string, bool) {
+	for _, name := range StartupFiles(shell) {
+		path := filepath.Join(home, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if strings.Contains(string(data), "familiar shell init") {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// Integration is what a loaded snippet exported into the environment
+type Integration struct {
+	Shell    string
+	Version  int
+	Features []string
+}
+
+// Detect reads the integration exported by the calling shell, whose process
+// id is parent. ok is false when the integration is not loaded, including
+// when the variables were inherited from another shell that loaded it, such
+// as bash started from a zsh with the integration.
+func Detect(getenv func(string) string, parent int) (Integration, bool) {
+	in := Integration{Shell: getenv(EnvShell)}
+	if in.Shell == "" {
+		return in, false
+	}
+	// Integrations before version 2 do not export the shell's pid
+	if pid := getenv(EnvPID); pid != "" && pid != strconv.Itoa(parent) {
+		return Integration{}, false
+	}
+	in.Version, _ = strconv.Atoi(getenv(EnvVersion))
+	if features := getenv(EnvFeatures); features != "" {
+		in.Features = strings.Split(features, ",")
+	}
+	return in, true
+}
+
+// Has reports whether the integration has a feature enabled
+func (in Integration) Has(feature string) bool {
+	for _, f := range in.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+var templates = map[string]*template.Template{
+	"bash": template.Must(template.New("bash").Parse(bashInit)),
+	"zsh":  template.Must(template.New("zsh").Parse(zshInit)),
+	"fish": template.Must(template.New("fish").Parse(fishInit)),
+}
+
+const header = `# familiar shell integration for {{.Shell}} (version {{.Version}})
+# Generated by 'familiar shell init {{.Shell}}'. Load it from your startup file with:
+#   {{.Load}}
+`
+
+// The async refresh writes the next segment to a per-shell file and shows
+// the last one written, so a slow disk never stalls the prompt.
+const bashInit = header + `export FAMILIAR_SHELL=bash
+export FAMILIAR_SHELL_INTEGRATION={{.Version}}
+export FAMILIAR_SHELL_FEATURES={{.Features}}
+export FAMILIAR_SHELL_PID=$$
+
+_familiar_segment=""
+_familiar_dir=""
+{{- if .Async}}
+_familiar_async_file="${XDG_RUNTIME_DIR:-${TMPDIR:-/tmp}}/familiar-prompt.$$"
+{{- end}}
+
+_familiar_precmd() {
+  local status=$?
+{{- if .Visits}}
+  if [[ $PWD != "$_familiar_dir" ]]; then
+    _familiar_dir=$PWD
+    (command familiar visit --silent --throttle {{.VisitThrottle}} >/dev/null 2>&1 &)
+  fi
+{{- end}}
+{{- if .Prompt}}
+{{- if .Async}}
+  [[ -r $_familiar_async_file ]] && _familiar_segment=$(<"$_familiar_async_file")
+  local tmp="$_familiar_async_file.$RANDOM"
+  (command familiar admin health --format bash >"$tmp" 2>/dev/null &&
+    command mv -f "$tmp" "$_familiar_async_file" 2>/dev/null &)
+{{- else}}
+  _familiar_segment=$(command familiar admin health --format bash 2>/dev/null)
+{{- end}}
+{{- end}}
+  return $status
+}
+
+if [[ $PROMPT_COMMAND != *_familiar_precmd* ]]; then
+  PROMPT_COMMAND="_familiar_precmd${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+{{- if .Prompt}}
+if [[ $PS1 != *_familiar_segment* ]]; then
+  PS1='${_familiar_segment:+$_familiar_segment }'"$PS1"
+fi
+{{- end}}
+{{- if .Completion}}
+source <(command familiar admin completion bash)
+{{- end}}
+`
+
+const zshInit = header + `export FAMILIAR_SHELL=zsh
+export FAMILIAR_SHELL_INTEGRATION={{.Version}}
+export FAMILIAR_SHELL_FEATURES={{.Features}}
+export FAMILIAR_SHELL_PID=$$
+
+typeset -g _familiar_segment=""
+{{- if .Async}}
+typeset -g _familiar_async_file="${XDG_RUNTIME_DIR:-${TMPDIR:-/tmp}}/familiar-prompt.$$"
+{{- end}}
+autoload -Uz add-zsh-hook
+{{- if .Visits}}
+
+_familiar_chpwd() {
+  (command familiar visit --silent --throttle {{.VisitThrottle}} >/dev/null 2>&1 &)
+}
+add-zsh-hook chpwd _familiar_chpwd
+_familiar_chpwd
+{{- end}}
+{{- if .Prompt}}
+
+_familiar_precmd() {
+{{- if .Async}}
+  [[ -r $_familiar_async_file ]] && _familiar_segment=$(<"$_familiar_async_file")
+  local tmp="$_familiar_async_file.$RANDOM"
+  (command familiar admin health --format zsh >"$tmp" 2>/dev/null &&
+    command mv -f "$tmp" "$_familiar_async_file" 2>/dev/null &)
+{{- else}}
+  _familiar_segment=$(command familiar admin health --format zsh 2>/dev/null)
+{{- end}}
+}
+add-zsh-hook precmd _familiar_precmd
+
+# Frameworks such as powerlevel10k can show $_familiar_segment in a segment of their own
+setopt prompt_subst
+if [[ $PROMPT != *_familiar_segment* ]]; then
+  PROMPT='${_familiar_segment:+$_familiar_segment }'"$PROMPT"
+fi
+{{- end}}
+{{- if .Completion}}
+
+if (( $+functions[compdef] )); then
+  source <(command familiar admin completion zsh)
+fi
+{{- end}}
+`
+
+const fishInit = header + `set -gx FAMILIAR_SHELL fish
+set -gx FAMILIAR_SHELL_INTEGRATION {{.Version}}
+set -gx FAMILIAR_SHELL_FEATURES {{.Features}}
+set -gx FAMILIAR_SHELL_PID $fish_pid
+
+set -g _familiar_segment ""
+{{- if .Async}}
+set -l _familiar_dir /tmp
+set -q TMPDIR; and set _familiar_dir (string trim -r -c / -- $TMPDIR)
+set -q XDG_RUNTIME_DIR; and set _familiar_dir $XDG_RUNTIME_DIR
+set -g _familiar_async_file $_familiar_dir/familiar-prompt.$fish_pid
+{{- end}}
+{{- if .Visits}}
+
+function _familiar_visit --on-variable PWD
+    command familiar visit --silent --throttle {{.VisitThrottle}} >/dev/null 2>&1 &
+    disown $last_pid 2>/dev/null
+end
+_familiar_visit
+{{- end}}
+{{- if .Prompt}}
+
+function _familiar_refresh --on-event fish_prompt
+{{- if .Async}}
+    test -r $_familiar_async_file; and set -g _familiar_segment (cat $_familiar_async_file)
+    command sh -c 'familiar admin health --format fish >"$1.$$" 2>/dev/null && mv -f "$1.$$" "$1" 2>/dev/null' sh $_familiar_async_file &
+    disown $last_pid 2>/dev/null
+{{- else}}
+    set -g _familiar_segment (command familiar admin health --format fish 2>/dev/null)
+{{- end}}
+end
+
+function _familiar_return
+    return $argv[1]
+end
+
+if not functions -q _familiar_original_prompt
+    functions -q fish_prompt; and functions -c fish_prompt _familiar_original_prompt
+    function fish_prompt
+        set -l last_status $status
+        test -n "$_familiar_segment"; and printf '%s ' $_familiar_segment
+        functions -q _familiar_original_prompt; or return
+        # The original prompt may show the last command's status
+        _familiar_return $last_status
+        _familiar_original_prompt
+    end
+end
+{{- end}}
+{{- if .Completion}}
+
+command familiar admin completion fish | source
+{{- end}}
+`
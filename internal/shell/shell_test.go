@@ -0,0 +1,105 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitSnippets(t *testing.T) {
+	for _, name := range Names {
+		for _, async := range []bool{false, true} {
+			opts := DefaultOptions()
+			opts.Async = async
+			snippet, err := Init(name, opts)
+			if err != nil {
+				t.Fatalf("Init(%s): %v", name, err)
+			}
+
+			for _, want := range []string{
+				"FAMILIAR_SHELL_INTEGRATION",
+				"FAMILIAR_SHELL_PID",
+				"familiar visit --silent --throttle 1h",
+				"familiar admin health --format " + name,
+				"familiar admin completion " + name,
+				LoadLine(name),
+			} {
+				if !strings.Contains(snippet, want) {
+					t.Errorf("%s (async=%v) snippet lacks %q:\n%s", name, async, want, snippet)
+				}
+			}
+			if got := strings.Contains(snippet, "_familiar_async_file"); got != async {
+				t.Errorf("%s (async=%v) snippet has async refresh: %v", name, async, got)
+			}
+
+			// Check the syntax with the shell itself when it is installed
+			if path, err := exec.LookPath(name); err == nil {
+				cmd := exec.Command(path, "-n")
+				cmd.Stdin = strings.NewReader(snippet)
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Errorf("%s -n rejected the snippet: %v\n%s", name, err, out)
+				}
+			}
+		}
+	}
+}
+
+func TestInitOptions(t *testing.T) {
+	snippet, err := Init("zsh", Options{Visits: true, VisitThrottle: "30m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(snippet, "PROMPT=") || strings.Contains(snippet, "completion") {
+		t.Errorf("disabled features present:\n%s", snippet)
+	}
+	if !strings.Contains(snippet, "FAMILIAR_SHELL_FEATURES=visits\n") || !strings.Contains(snippet, "--throttle 30m") {
+		t.Errorf("unexpected snippet:\n%s", snippet)
+	}
+
+	if _, err := Init("tcsh", DefaultOptions()); err == nil {
+		t.Error("expected an error for an unsupported shell")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	env := map[string]string{EnvShell: "zsh", EnvVersion: "2", EnvFeatures: "prompt,async", EnvPID: "42"}
+	in, ok := Detect(func(k string) string { return env[k] }, 42)
+	if !ok || in.Shell != "zsh" || in.Version != 2 {
+		t.Fatalf("Detect = %+v, %v", in, ok)
+	}
+	if !in.Has(FeatureAsync) || in.Has(FeatureVisits) {
+		t.Errorf("unexpected features %v", in.Features)
+	}
+
+	if _, ok := Detect(func(string) string { return "" }, 42); ok {
+		t.Error("expected no integration in an empty environment")
+	}
+	if _, ok := Detect(func(k string) string { return env[k] }, 43); ok {
+		t.Error("expected no integration in a shell started from the one that loaded it")
+	}
+	delete(env, EnvPID)
+	env[EnvVersion] = "1"
+	if in, ok := Detect(func(k string) string { return env[k] }, 43); !ok || in.Version != 1 {
+		t.Errorf("expected an old integration without a pid to be detected, got %+v, %v", in, ok)
+	}
+}
+
+func TestPersisted(t *testing.T) {
+	home := t.TempDir()
+	if _, ok := Persisted("bash", home); ok {
+		t.Error("expected no startup file")
+	}
+
+	rc := filepath.Join(home, ".bashrc")
+	if err := os.WriteFile(rc, []byte("alias ll='ls -l'\n"+LoadLine("bash")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if path, ok := Persisted("bash", home); !ok || path != rc {
+		t.Errorf("Persisted = %s, %v; want %s", path, ok, rc)
+	}
+	if _, ok := Persisted("zsh", home); ok {
+		t.Error("bash startup files must not count for zsh")
+	}
+}
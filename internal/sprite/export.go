@@ -0,0 +1,452 @@
+package sprite
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"html"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sethgrid/familiar/internal/pet"
+	"github.com/sethgrid/familiar/internal/term"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Export formats
+const (
+	FormatGIF  = "gif"
+	FormatAPNG = "apng"
+	FormatSVG  = "svg"
+)
+
+const (
+	// DefaultPixelScale is the size in image pixels of one pixel of art
+	DefaultPixelScale = 8
+
+	// DefaultTextScale enlarges the 7x13 glyphs of inline art
+	DefaultTextScale = 2
+
+	// glyphWidth and glyphHeight are the cell size of basicfont.Face7x13
+	glyphWidth  = 7
+	glyphHeight = 13
+)
+
+var (
+	textBackground = color.NRGBA{R: 0x1e, G: 0x1e, B: 0x1e, A: 0xff}
+	textForeground = color.NRGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff}
+)
+
+// ExportOptions control how an animation is written out
+type ExportOptions struct {
+	Format string // FormatGIF, FormatAPNG or FormatSVG
+	Scale  int    // image pixels per art pixel, or glyph magnification for text; 0 uses the default
+}
+
+// FormatFor picks the export format from the extension of path
+func FormatFor(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gif":
+		return FormatGIF, nil
+	case ".png", ".apng":
+		return FormatAPNG, nil
+	case ".svg":
+		return FormatSVG, nil
+	}
+	return "", fmt.Errorf("unsupported export file '%s' (expected .gif, .png, .apng or .svg)", path)
+}
+
+// Export writes anim as an animated image. Pixel frames are drawn as blocks
+// of opts.Scale pixels; inline frames are drawn as monospace text with escape
+// sequences removed. Each frame shows for its MS, or 1000/FPS milliseconds,
+// and the animation plays Loops times (0 or -1 forever), ping-pong if set.
+func Export(w io.Writer, anim pet.AnimationConfig, opts ExportOptions) error {
+	if len(anim.Frames) == 0 {
+		return fmt.Errorf("animation has no frames")
+	}
+	if anim.PingPong {
+		frames := make([]pet.Frame, 0, 2*len(anim.Frames))
+		for _, i := range anim.Pass() {
+			frames = append(frames, anim.Frames[i])
+		}
+		anim.Frames = frames
+	}
+	pixel := anim.Pixel()
+	scale := opts.Scale
+	if scale <= 0 {
+		scale = DefaultTextScale
+		if pixel {
+			scale = DefaultPixelScale
+		}
+	}
+
+	delays := make([]int, len(anim.Frames))
+	for i, f := range anim.Frames {
+		delays[i] = frameMS(anim, f)
+	}
+
+	if opts.Format == FormatSVG {
+		return writeSVG(w, anim, pixel, scale, delays)
+	}
+
+	var frames []*image.NRGBA
+	if pixel {
+		frames = rasterPixels(anim.Frames, scale)
+	} else {
+		frames = rasterText(anim.Frames, scale)
+	}
+	switch opts.Format {
+	case FormatGIF:
+		return writeGIF(w, frames, delays, anim.Loops)
+	case FormatAPNG:
+		return writeAPNG(w, frames, delays, anim.Loops)
+	}
+	return fmt.Errorf("unknown export format '%s'", opts.Format)
+}
+
+// frameMS is how long a frame shows, as the terminal player times it
+func frameMS(anim pet.AnimationConfig, f pet.Frame) int {
+	if f.MS > 0 {
+		return f.MS
+	}
+	return 1000 / max(anim.FPS, 1)
+}
+
+// parseColor reads a pixel value, reporting false for transparent ones
+// ("", "transparent", or padding of spaces or hashes)
+func parseColor(pixel string) (color.NRGBA, bool) {
+	hex := strings.TrimPrefix(strings.TrimSpace(pixel), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.NRGBA{}, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, true
+}
+
+// textLines splits inline art into lines without escape sequences or the
+// trailing newline
+func textLines(art string) []string {
+	return strings.Split(strings.TrimRight(term.StripANSI(art), "\n"), "\n")
+}
+
+// rasterPixels draws pixel frames on canvases sized to the largest frame
+func rasterPixels(frames []pet.Frame, scale int) []*image.NRGBA {
+	var size image.Point
+	for _, f := range frames {
+		size.Y = max(size.Y, len(f.Pixels))
+		for _, row := range f.Pixels {
+			size.X = max(size.X, len(row))
+		}
+	}
+
+	out := make([]*image.NRGBA, len(frames))
+	for i, f := range frames {
+		img := image.NewNRGBA(image.Rect(0, 0, max(size.X, 1)*scale, max(size.Y, 1)*scale))
+		for y, row := range f.Pixels {
+			for x, pixel := range row {
+				if c, ok := parseColor(pixel); ok {
+					draw.Draw(img, image.Rect(x*scale, y*scale, (x+1)*scale, (y+1)*scale), image.NewUniform(c), image.Point{}, draw.Src)
+				}
+			}
+		}
+		out[i] = img
+	}
+	return out
+}
+
+// rasterText draws inline frames as light text on a dark background, with
+// the glyphs magnified scale times
+func rasterText(frames []pet.Frame, scale int) []*image.NRGBA {
+	cols, rows := 1, 1
+	lines := make([][]string, len(frames))
+	for i, f := range frames {
+		lines[i] = textLines(f.Art)
+		rows = max(rows, len(lines[i]))
+		for _, line := range lines[i] {
+			cols = max(cols, len([]rune(line)))
+		}
+	}
+
+	out := make([]*image.NRGBA, len(frames))
+	for i := range frames {
+		small := image.NewNRGBA(image.Rect(0, 0, cols*glyphWidth, rows*glyphHeight))
+		draw.Draw(small, small.Bounds(), image.NewUniform(textBackground), image.Point{}, draw.Src)
+		d := font.Drawer{Dst: small, Src: image.NewUniform(textForeground), Face: basicfont.Face7x13}
+		for y, line := range lines[i] {
+			for x, r := range []rune(line) {
+				d.Dot = fixed.P(x*glyphWidth, y*glyphHeight+basicfont.Face7x13.Ascent)
+				d.DrawString(string(r))
+			}
+		}
+		out[i] = enlarge(small, scale)
+	}
+	return out
+}
+
+// enlarge repeats every pixel of img scale times in each direction
+func enlarge(img *image.NRGBA, scale int) *image.NRGBA {
+	if scale == 1 {
+		return img
+	}
+	b := img.Bounds()
+	big := image.NewNRGBA(image.Rect(0, 0, b.Dx()*scale, b.Dy()*scale))
+	for y := 0; y < big.Rect.Dy(); y++ {
+		for x := 0; x < big.Rect.Dx(); x++ {
+			big.SetNRGBA(x, y, img.NRGBAAt(x/scale, y/scale))
+		}
+	}
+	return big
+}
+
+// writeGIF encodes frames with a shared palette. GIF delays are in
+// hundredths of a second, and browsers treat anything under 2 as 10.
+func writeGIF(w io.Writer, frames []*image.NRGBA, delays []int, loops int) error {
+	pal := gifPalette(frames)
+	g := &gif.GIF{LoopCount: gifLoopCount(loops)}
+	for i, img := range frames {
+		p := image.NewPaletted(img.Bounds(), pal)
+		for y := 0; y < img.Rect.Dy(); y++ {
+			for x := 0; x < img.Rect.Dx(); x++ {
+				c := img.NRGBAAt(x, y)
+				if c.A == 0 {
+					p.SetColorIndex(x, y, 0)
+				} else {
+					p.SetColorIndex(x, y, uint8(pal.Index(c)))
+				}
+			}
+		}
+		g.Image = append(g.Image, p)
+		g.Delay = append(g.Delay, max(delays[i]/10, 2))
+		g.Disposal = append(g.Disposal, gif.DisposalBackground)
+	}
+	if err := gif.EncodeAll(w, g); err != nil {
+		return fmt.Errorf("failed to encode GIF: %w", err)
+	}
+	return nil
+}
+
+// gifPalette is a transparent entry followed by every opaque colour in
+// frames, or the web-safe colours when there are more than fit
+func gifPalette(frames []*image.NRGBA) color.Palette {
+	pal := color.Palette{color.NRGBA{}}
+	seen := map[color.NRGBA]bool{}
+	for _, img := range frames {
+		for i := 0; i < len(img.Pix); i += 4 {
+			c := color.NRGBA{R: img.Pix[i], G: img.Pix[i+1], B: img.Pix[i+2], A: img.Pix[i+3]}
+			if c.A == 0 || seen[c] {
+				continue
+			}
+			if len(pal) == 256 {
+				return append(color.Palette{color.NRGBA{}}, palette.WebSafe...)
+			}
+			seen[c] = true
+			pal = append(pal, c)
+		}
+	}
+	return pal
+}
+
+// gifLoopCount converts Loops, the number of plays, to the GIF convention of
+// 0 for forever, -1 for once and n for n+1 plays
+func gifLoopCount(loops int) int {
+	switch {
+	case loops <= 0:
+		return 0
+	case loops == 1:
+		return -1
+	}
+	return loops - 1
+}
+
+// writeAPNG encodes frames as an animated PNG. The standard library has no
+// APNG encoder, so the chunks are written directly: every frame is a full
+// 8-bit RGBA image, cleared before the next is drawn.
+func writeAPNG(w io.Writer, frames []*image.NRGBA, delays []int, loops int) error {
+	b := frames[0].Bounds()
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(b.Dx()))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(b.Dy()))
+	ihdr[8], ihdr[9] = 8, 6 // bit depth, colour type RGBA
+	writeChunk(&buf, "IHDR", ihdr)
+
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:], uint32(len(frames)))
+	binary.BigEndian.PutUint32(actl[4:], uint32(max(loops, 0)))
+	writeChunk(&buf, "acTL", actl)
+
+	seq := uint32(0)
+	for i, img := range frames {
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(b.Dx()))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(b.Dy()))
+		binary.BigEndian.PutUint16(fctl[20:], uint16(min(delays[i], 0xffff)))
+		binary.BigEndian.PutUint16(fctl[22:], 1000)
+		fctl[24] = 1 // dispose to transparent black
+		writeChunk(&buf, "fcTL", fctl)
+		seq++
+
+		data, err := compressRows(img)
+		if err != nil {
+			return fmt.Errorf("failed to encode PNG frame: %w", err)
+		}
+		if i == 0 {
+			writeChunk(&buf, "IDAT", data)
+			continue
+		}
+		fdat := binary.BigEndian.AppendUint32(nil, seq)
+		writeChunk(&buf, "fdAT", append(fdat, data...))
+		seq++
+	}
+	writeChunk(&buf, "IEND", nil)
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write PNG: %w", err)
+	}
+	return nil
+}
+
+// compressRows deflates the scanlines of img, each with filter type 0
+func compressRows(img *image.NRGBA) ([]byte, error) {
+	var buf bytes.Buffer
+	z := zlib.NewWriter(&buf)
+	width := img.Rect.Dx() * 4
+	for y := 0; y < img.Rect.Dy(); y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+width]
+		if _, err := z.Write(append([]byte{0}, row...)); err != nil {
+			return nil, err
+		}
+	}
+	if err := z.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeChunk(buf *bytes.Buffer, kind string, data []byte) {
+	binary.Write(buf, binary.BigEndian, uint32(len(data)))
+	crc := crc32.NewIEEE()
+	io.WriteString(crc, kind)
+	crc.Write(data)
+	buf.WriteString(kind)
+	buf.Write(data)
+	binary.Write(buf, binary.BigEndian, crc.Sum32())
+}
+
+// writeSVG draws each frame as a group that a discrete animation shows in
+// turn: pixel art as rectangles, inline art as monospace text
+func writeSVG(w io.Writer, anim pet.AnimationConfig, pixel bool, scale int, delays []int) error {
+	var groups []string
+	var size image.Point
+	for _, f := range anim.Frames {
+		var g strings.Builder
+		if pixel {
+			for y, row := range f.Pixels {
+				size.Y = max(size.Y, (y+1)*scale)
+				// Merge runs of one colour so each row needs few rectangles
+				for x := 0; x < len(row); {
+					c, ok := parseColor(row[x])
+					run := 1
+					for x+run < len(row) {
+						next, nextOK := parseColor(row[x+run])
+						if nextOK != ok || next != c {
+							break
+						}
+						run++
+					}
+					if ok {
+						fmt.Fprintf(&g, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`, x*scale, y*scale, run*scale, scale, Hex(c))
+					}
+					x += run
+				}
+				size.X = max(size.X, len(row)*scale)
+			}
+		} else {
+			cellW, cellH := glyphWidth*scale, glyphHeight*scale
+			lines := textLines(f.Art)
+			size.Y = max(size.Y, len(lines)*cellH)
+			for y, line := range lines {
+				size.X = max(size.X, len([]rune(line))*cellW)
+				fmt.Fprintf(&g, `<text x="0" y="%d">%s</text>`, y*cellH+basicfont.Face7x13.Ascent*scale, html.EscapeString(line))
+			}
+		}
+		groups = append(groups, g.String())
+	}
+
+	total := 0
+	for _, d := range delays {
+		total += d
+	}
+	repeat := "indefinite"
+	if anim.Loops > 0 {
+		repeat = strconv.Itoa(anim.Loops)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", size.X, size.Y, size.X, size.Y)
+	if !pixel {
+		fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", Hex(textBackground))
+		// Monospace glyphs are about 0.6em wide; size them to fill a 7 pixel cell
+		fmt.Fprintf(&b, `<g font-family="monospace" font-size="%.1f" fill="%s" xml:space="preserve">`+"\n", float64(glyphWidth*scale)/0.6, Hex(textForeground))
+	}
+	elapsed := 0
+	for i, g := range groups {
+		display := "inline"
+		if i > 0 {
+			display = "none"
+		}
+		fmt.Fprintf(&b, `<g display="%s">%s`, display, g)
+		if len(groups) > 1 {
+			keyTimes, values := svgTimeline(elapsed, delays[i], total)
+			fmt.Fprintf(&b, `<animate attributeName="display" calcMode="discrete" dur="%dms" repeatCount="%s" fill="freeze" keyTimes="%s" values="%s"/>`,
+				total, repeat, keyTimes, values)
+		}
+		b.WriteString("</g>\n")
+		elapsed += delays[i]
+	}
+	if !pixel {
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</svg>\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write SVG: %w", err)
+	}
+	return nil
+}
+
+// svgTimeline shows a frame from start for its delay within a cycle of total
+// milliseconds, and hides it otherwise. The final value is held when the
+// animation ends, so finite animations stop on their last frame.
+func svgTimeline(start, delay, total int) (keyTimes, values string) {
+	t := func(ms int) string { return strconv.FormatFloat(float64(ms)/float64(total), 'f', 4, 64) }
+	var times, vals []string
+	if start > 0 {
+		times, vals = append(times, "0"), append(vals, "none")
+	}
+	times, vals = append(times, t(start)), append(vals, "inline")
+	if start+delay < total {
+		times, vals = append(times, t(start+delay)), append(vals, "none")
+	}
+	return strings.Join(times, ";"), strings.Join(vals, ";")
+}
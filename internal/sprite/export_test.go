@@ -0,0 +1,159 @@
+package sprite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/gif"
+	"strings"
+	"testing"
+
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+// blink is a two frame pixel animation: the second frame has its own delay
+var blink = pet.AnimationConfig{
+	Source: "pixel",
+	FPS:    4,
+	Loops:  3,
+	Frames: []pet.Frame{
+		{Pixels: [][]string{{"#FF0000", ""}}},
+		{Pixels: [][]string{{"", "#00F"}}, MS: 500},
+	},
+}
+
+func TestExportGIF(t *testing.T) {
+	var b bytes.Buffer
+	if err := Export(&b, blink, ExportOptions{Format: FormatGIF, Scale: 2}); err != nil {
+		t.Fatal(err)
+	}
+	g, err := gif.DecodeAll(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Image) != 2 || g.Config.Width != 4 || g.Config.Height != 2 {
+		t.Fatalf("Expected two 4x2 frames, got %d at %dx%d", len(g.Image), g.Config.Width, g.Config.Height)
+	}
+	if g.Delay[0] != 25 || g.Delay[1] != 50 {
+		t.Errorf("Expected delays 25 and 50, got %v", g.Delay)
+	}
+	if g.LoopCount != 2 {
+		t.Errorf("Expected 3 plays (LoopCount 2), got %d", g.LoopCount)
+	}
+	if _, _, _, a := g.Image[0].At(3, 0).RGBA(); a != 0 {
+		t.Error("Expected transparent pixels to stay transparent")
+	}
+	if r, _, _, _ := g.Image[0].At(1, 1).RGBA(); r != 0xffff {
+		t.Error("Expected the red pixel to fill its 2x2 block")
+	}
+}
+
+func TestExportGIFLoops(t *testing.T) {
+	tests := map[string]struct {
+		loops int
+		want  int
+	}{
+		"once":    {loops: 1, want: -1},
+		"twice":   {loops: 2, want: 1},
+		"forever": {loops: -1, want: 0},
+		"unset":   {loops: 0, want: 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			anim := blink
+			anim.Loops = tt.loops
+			var b bytes.Buffer
+			if err := Export(&b, anim, ExportOptions{Format: FormatGIF, Scale: 1}); err != nil {
+				t.Fatal(err)
+			}
+			g, err := gif.DecodeAll(&b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g.LoopCount != tt.want {
+				t.Errorf("Loops %d: expected LoopCount %d, got %d", tt.loops, tt.want, g.LoopCount)
+			}
+		})
+	}
+}
+
+func TestExportPingPong(t *testing.T) {
+	anim := pet.AnimationConfig{Source: "pixel", FPS: 4, Loops: -1, PingPong: true, Frames: []pet.Frame{
+		{Pixels: [][]string{{"#F00"}}},
+		{Pixels: [][]string{{"#0F0"}}},
+		{Pixels: [][]string{{"#00F"}}, MS: 500},
+	}}
+	var b bytes.Buffer
+	if err := Export(&b, anim, ExportOptions{Format: FormatGIF, Scale: 1}); err != nil {
+		t.Fatal(err)
+	}
+	g, err := gif.DecodeAll(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Forward then back, without the ends twice: red green blue green
+	if len(g.Image) != 4 || g.Delay[2] != 50 {
+		t.Fatalf("Expected 4 frames with blue third, got %d with delays %v", len(g.Image), g.Delay)
+	}
+	if _, gr, _, _ := g.Image[3].At(0, 0).RGBA(); gr != 0xffff {
+		t.Error("Expected the last frame to be green")
+	}
+}
+
+func TestExportAPNG(t *testing.T) {
+	var b bytes.Buffer
+	if err := Export(&b, blink, ExportOptions{Format: FormatAPNG}); err != nil {
+		t.Fatal(err)
+	}
+	data := b.Bytes()
+	if !bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+		t.Fatal("Expected a PNG signature")
+	}
+
+	var kinds []string
+	for i := 8; i+8 <= len(data); {
+		n := int(binary.BigEndian.Uint32(data[i:]))
+		kind := string(data[i+4 : i+8])
+		kinds = append(kinds, kind)
+		if kind == "acTL" && binary.BigEndian.Uint32(data[i+12:]) != 3 {
+			t.Errorf("Expected 3 plays in acTL")
+		}
+		i += 12 + n
+	}
+	want := "IHDR acTL fcTL IDAT fcTL fdAT IEND"
+	if got := strings.Join(kinds, " "); got != want {
+		t.Errorf("Chunks = %s, want %s", got, want)
+	}
+}
+
+func TestExportSVG(t *testing.T) {
+	var b bytes.Buffer
+	if err := Export(&b, blink, ExportOptions{Format: FormatSVG}); err != nil {
+		t.Fatal(err)
+	}
+	svg := b.String()
+	for _, want := range []string{`width="16" height="8"`, `fill="#0000FF"`, `dur="750ms"`, `repeatCount="3"`, `keyTimes="0;0.3333" values="none;inline"`} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("Expected SVG to contain %s:\n%s", want, svg)
+		}
+	}
+
+	text := pet.AnimationConfig{Source: "inline", Frames: []pet.Frame{{Art: "\033[33m<a&b>\033[0m\n"}}}
+	b.Reset()
+	if err := Export(&b, text, ExportOptions{Format: FormatSVG}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "&lt;a&amp;b&gt;</text>") || strings.Contains(b.String(), "<animate") {
+		t.Errorf("Expected escaped text and no animation for one frame:\n%s", b.String())
+	}
+}
+
+func TestFormatFor(t *testing.T) {
+	for path, want := range map[string]string{"a.gif": FormatGIF, "b.PNG": FormatAPNG, "c.apng": FormatAPNG, "d.svg": FormatSVG} {
+		if got, err := FormatFor(path); err != nil || got != want {
+			t.Errorf("FormatFor(%s) = %s, %v", path, got, err)
+		}
+	}
+	if _, err := FormatFor("e.jpg"); err == nil {
+		t.Error("Expected an error for .jpg")
+	}
+}
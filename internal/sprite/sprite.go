@@ -0,0 +1,353 @@
+// Package sprite converts PNG and GIF images, including animated GIFs and
+// sprite sheets, into pixel animation frames, and exports animations back out
+// as GIF, APNG or SVG.
+package sprite
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	_ "image/png" // register the PNG decoder
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+const (
+	// MaxGrid is the longest side of a frame when no grid is given; pixel
+	// art wider than this no longer fits beside a prompt
+	MaxGrid = 32
+
+	// DefaultMaxColors keeps imported art close to hand-drawn palettes
+	DefaultMaxColors = 16
+
+	// defaultDelayMS is used for GIF frames without a delay, as browsers do
+	defaultDelayMS = 100
+)
+
+// Options control how an image becomes frames
+type Options struct {
+	Grid      image.Point // frame size in pixels; zero fits each frame within MaxGrid
+	Sheet     image.Point // columns and rows of a sprite sheet; zero is a single sprite
+	MaxColors int         // palette size; 0 keeps every colour
+}
+
+// frame is one decoded image and how long it shows
+type frame struct {
+	img image.Image
+	ms  int
+}
+
+// Import decodes a PNG or GIF and returns it as pixel frames. Every frame of
+// an animated GIF, and every non-empty cell of a sprite sheet (left to right,
+// top to bottom), becomes a frame. Pixels less than half opaque are
+// transparent ("").
+func Import(r io.Reader, opts Options) ([]pet.Frame, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	var decoded []frame
+	if bytes.HasPrefix(data, []byte("GIF8")) {
+		decoded, err = decodeGIF(data)
+	} else {
+		var img image.Image
+		img, _, err = image.Decode(bytes.NewReader(data))
+		decoded = []frame{{img: img}}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	cells := decoded
+	if opts.Sheet.X > 1 || opts.Sheet.Y > 1 {
+		cells, err = splitSheet(decoded, opts.Sheet)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(cells) == 0 {
+		return nil, fmt.Errorf("image has no visible frames")
+	}
+
+	grid := opts.Grid
+	if grid.X <= 0 || grid.Y <= 0 {
+		grid = fitGrid(cells[0].img.Bounds().Size(), MaxGrid)
+	}
+
+	scaled := make([][][]color.NRGBA, len(cells))
+	for i, c := range cells {
+		scaled[i] = downscale(c.img, grid)
+	}
+	mapColor := quantize(scaled, opts.MaxColors)
+
+	frames := make([]pet.Frame, len(scaled))
+	for i, rows := range scaled {
+		pixels := make([][]string, len(rows))
+		for y, row := range rows {
+			pixels[y] = make([]string, len(row))
+			for x, c := range row {
+				if c.A != 0 {
+					pixels[y][x] = Hex(mapColor(c))
+				}
+			}
+		}
+		frames[i] = pet.Frame{Pixels: pixels, MS: cells[i].ms}
+	}
+	return frames, nil
+}
+
+// ParseSize parses "WIDTHxHEIGHT", as in --grid 16x16
+func ParseSize(s string) (image.Point, error) {
+	w, h, ok := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "x")
+	x, errW := strconv.Atoi(w)
+	y, errH := strconv.Atoi(h)
+	if !ok || errW != nil || errH != nil || x <= 0 || y <= 0 {
+		return image.Point{}, fmt.Errorf("invalid size '%s' (expected WIDTHxHEIGHT, e.g. 16x16)", s)
+	}
+	return image.Pt(x, y), nil
+}
+
+// Hex formats a colour the way pet.toml writes pixels, "#RRGGBB"
+func Hex(c color.NRGBA) string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+// decodeGIF composes each GIF frame onto the canvas, honouring disposal, so
+// partial frames come out whole
+func decodeGIF(data []byte) ([]frame, error) {
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	canvas := image.NewNRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height))
+	frames := make([]frame, 0, len(g.Image))
+	for i, img := range g.Image {
+		disposal := byte(0)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		var previous *image.NRGBA
+		if disposal == gif.DisposalPrevious {
+			previous = clone(canvas)
+		}
+
+		draw.Draw(canvas, img.Bounds(), img, img.Bounds().Min, draw.Over)
+		ms := defaultDelayMS
+		if i < len(g.Delay) && g.Delay[i] > 0 {
+			ms = g.Delay[i] * 10
+		}
+		frames = append(frames, frame{img: clone(canvas), ms: ms})
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, img.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return frames, nil
+}
+
+func clone(img *image.NRGBA) *image.NRGBA {
+	c := image.NewNRGBA(img.Bounds())
+	copy(c.Pix, img.Pix)
+	return c
+}
+
+// splitSheet cuts every frame into sheet.X by sheet.Y cells, dropping empty ones
+func splitSheet(frames []frame, sheet image.Point) ([]frame, error) {
+	sheet.X, sheet.Y = max(sheet.X, 1), max(sheet.Y, 1)
+	var cells []frame
+	for _, f := range frames {
+		b := f.img.Bounds()
+		w, h := b.Dx()/sheet.X, b.Dy()/sheet.Y
+		if w == 0 || h == 0 {
+			return nil, fmt.Errorf("a %dx%d image cannot be split into %dx%d cells", b.Dx(), b.Dy(), sheet.X, sheet.Y)
+		}
+		for row := 0; row < sheet.Y; row++ {
+			for col := 0; col < sheet.X; col++ {
+				origin := b.Min.Add(image.Pt(col*w, row*h))
+				cell := image.NewNRGBA(image.Rect(0, 0, w, h))
+				draw.Draw(cell, cell.Bounds(), f.img, origin, draw.Src)
+				if !transparent(cell) {
+					cells = append(cells, frame{img: cell, ms: f.ms})
+				}
+			}
+		}
+	}
+	return cells, nil
+}
+
+func transparent(img *image.NRGBA) bool {
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// fitGrid keeps size, shrinking it to fit within limit on its longest side
+func fitGrid(size image.Point, limit int) image.Point {
+	longest := max(size.X, size.Y)
+	if longest <= limit {
+		return size
+	}
+	return image.Pt(max(1, size.X*limit/longest), max(1, size.Y*limit/longest))
+}
+
+// downscale resizes img to grid by averaging the source pixels behind each
+// cell. Cells less than half opaque become fully transparent.
+func downscale(img image.Image, grid image.Point) [][]color.NRGBA {
+	b := img.Bounds()
+	rows := make([][]color.NRGBA, grid.Y)
+	for y := 0; y < grid.Y; y++ {
+		rows[y] = make([]color.NRGBA, grid.X)
+		y0 := b.Min.Y + y*b.Dy()/grid.Y
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/grid.Y)
+		for x := 0; x < grid.X; x++ {
+			x0 := b.Min.X + x*b.Dx()/grid.X
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/grid.X)
+
+			// Sum premultiplied channels, so transparent pixels add no colour
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			if a == 0 || a/n < 0x8000 {
+				continue
+			}
+			rows[y][x] = color.NRGBA{
+				R: uint8(r * 0xff / a),
+				G: uint8(g * 0xff / a),
+				B: uint8(bl * 0xff / a),
+				A: 0xff,
+			}
+		}
+	}
+	return rows
+}
+
+// quantize returns a mapping from each opaque colour in frames to the
+// nearest colour of a median cut palette of at most maxColors
+func quantize(frames [][][]color.NRGBA, maxColors int) func(color.NRGBA) color.NRGBA {
+	counts := map[color.NRGBA]int{}
+	for _, rows := range frames {
+		for _, row := range rows {
+			for _, c := range row {
+				if c.A != 0 {
+					counts[c]++
+				}
+			}
+		}
+	}
+	if maxColors <= 0 || len(counts) <= maxColors {
+		return func(c color.NRGBA) color.NRGBA { return c }
+	}
+
+	palette := medianCut(counts, maxColors)
+	nearest := make(map[color.NRGBA]color.NRGBA, len(counts))
+	for c := range counts {
+		best, bestDist := palette[0], -1
+		for _, p := range palette {
+			dr, dg, db := int(c.R)-int(p.R), int(c.G)-int(p.G), int(c.B)-int(p.B)
+			if d := dr*dr + dg*dg + db*db; bestDist < 0 || d < bestDist {
+				best, bestDist = p, d
+			}
+		}
+		nearest[c] = best
+	}
+	return func(c color.NRGBA) color.NRGBA { return nearest[c] }
+}
+
+// colorCount is a colour and how many pixels use it
+type colorCount struct {
+	c     color.NRGBA
+	count int
+}
+
+// medianCut splits the colours into n boxes, each time halving the box with
+// the widest channel range at its pixel-weighted median, and returns each
+// box's weighted mean
+func medianCut(counts map[color.NRGBA]int, n int) []color.NRGBA {
+	all := make([]colorCount, 0, len(counts))
+	for c, count := range counts {
+		all = append(all, colorCount{c, count})
+	}
+	// Map order is random; sort so the palette is deterministic
+	sort.Slice(all, func(i, j int) bool { return Hex(all[i].c) < Hex(all[j].c) })
+
+	boxes := [][]colorCount{all}
+	for len(boxes) < n {
+		widest, channel, span := -1, 0, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			for ch := 0; ch < 3; ch++ {
+				lo, hi := 255, 0
+				for _, cc := range box {
+					v := channelOf(cc.c, ch)
+					lo, hi = min(lo, v), max(hi, v)
+				}
+				if hi-lo > span || widest < 0 {
+					widest, channel, span = i, ch, hi-lo
+				}
+			}
+		}
+		if widest < 0 {
+			break
+		}
+
+		box := boxes[widest]
+		sort.SliceStable(box, func(i, j int) bool { return channelOf(box[i].c, channel) < channelOf(box[j].c, channel) })
+		total := 0
+		for _, cc := range box {
+			total += cc.count
+		}
+		split, seen := 1, 0
+		for i, cc := range box[:len(box)-1] {
+			seen += cc.count
+			split = i + 1
+			if seen*2 >= total {
+				break
+			}
+		}
+		boxes[widest] = box[:split]
+		boxes = append(boxes, box[split:])
+	}
+
+	palette := make([]color.NRGBA, len(boxes))
+	for i, box := range boxes {
+		var r, g, b, total int
+		for _, cc := range box {
+			r += int(cc.c.R) * cc.count
+			g += int(cc.c.G) * cc.count
+			b += int(cc.c.B) * cc.count
+			total += cc.count
+		}
+		palette[i] = color.NRGBA{R: uint8(r / total), G: uint8(g / total), B: uint8(b / total), A: 0xff}
+	}
+	return palette
+}
+
+func channelOf(c color.NRGBA, ch int) int {
+	switch ch {
+	case 0:
+		return int(c.R)
+	case 1:
+		return int(c.G)
+	}
+	return int(c.B)
+}
@@ -0,0 +1,150 @@
+package sprite
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"testing"
+)
+
+var (
+	red   = color.NRGBA{R: 0xff, A: 0xff}
+	blue  = color.NRGBA{B: 0xff, A: 0xff}
+	clear = color.NRGBA{}
+)
+
+// fill paints a w by h image from a function of the pixel position
+func fill(w, h int, at func(x, y int) color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, at(x, y))
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) *bytes.Buffer {
+	t.Helper()
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		t.Fatal(err)
+	}
+	return &b
+}
+
+func TestImportPNG(t *testing.T) {
+	// Left half red, right half transparent, downscaled from 8x4 to 4x2
+	img := fill(8, 4, func(x, y int) color.NRGBA {
+		if x < 4 {
+			return red
+		}
+		return clear
+	})
+	frames, err := Import(encodePNG(t, img), Options{Grid: image.Pt(4, 2), MaxColors: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 1 || len(frames[0].Pixels) != 2 || len(frames[0].Pixels[0]) != 4 {
+		t.Fatalf("Expected one 4x2 frame, got %+v", frames)
+	}
+	want := []string{"#FF0000", "#FF0000", "", ""}
+	for x, c := range frames[0].Pixels[1] {
+		if c != want[x] {
+			t.Errorf("Pixel %d = %q, want %q", x, c, want[x])
+		}
+	}
+	if frames[0].MS != 0 {
+		t.Errorf("Expected no delay for a still image, got %d", frames[0].MS)
+	}
+}
+
+func TestImportSheet(t *testing.T) {
+	// A 3x1 sheet of 2x2 cells: red, blue and an empty cell that is dropped
+	img := fill(6, 2, func(x, y int) color.NRGBA {
+		switch x / 2 {
+		case 0:
+			return red
+		case 1:
+			return blue
+		}
+		return clear
+	})
+	frames, err := Import(encodePNG(t, img), Options{Sheet: image.Pt(3, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("Expected 2 frames, got %d", len(frames))
+	}
+	if frames[0].Pixels[0][0] != "#FF0000" || frames[1].Pixels[1][1] != "#0000FF" {
+		t.Errorf("Unexpected frames: %+v", frames)
+	}
+
+	if _, err := Import(encodePNG(t, img), Options{Sheet: image.Pt(12, 1)}); err == nil {
+		t.Error("Expected an error splitting 6 pixels into 12 cells")
+	}
+}
+
+func TestImportGIF(t *testing.T) {
+	// The second frame only covers one pixel; the rest comes from the first
+	g := &gif.GIF{Config: image.Config{Width: 2, Height: 1, ColorModel: color.Palette(palette.Plan9)}}
+	first := image.NewPaletted(image.Rect(0, 0, 2, 1), palette.Plan9)
+	first.Set(0, 0, red)
+	first.Set(1, 0, red)
+	second := image.NewPaletted(image.Rect(1, 0, 2, 1), palette.Plan9)
+	second.Set(1, 0, blue)
+	g.Image = []*image.Paletted{first, second}
+	g.Delay = []int{25, 0}
+	g.Disposal = []byte{gif.DisposalNone, gif.DisposalNone}
+
+	var b bytes.Buffer
+	if err := gif.EncodeAll(&b, g); err != nil {
+		t.Fatal(err)
+	}
+	frames, err := Import(&b, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("Expected 2 frames, got %d", len(frames))
+	}
+	if frames[0].MS != 250 || frames[1].MS != defaultDelayMS {
+		t.Errorf("Expected delays 250 and %d, got %d and %d", defaultDelayMS, frames[0].MS, frames[1].MS)
+	}
+	if got := frames[1].Pixels[0]; got[0] != "#FF0000" || got[1] != "#0000FF" {
+		t.Errorf("Expected the second frame composed over the first, got %v", got)
+	}
+}
+
+func TestQuantize(t *testing.T) {
+	// A gradient of 64 reds reduced to 4 colours
+	img := fill(64, 1, func(x, y int) color.NRGBA {
+		return color.NRGBA{R: uint8(x * 4), A: 0xff}
+	})
+	frames, err := Import(encodePNG(t, img), Options{MaxColors: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{}
+	for _, c := range frames[0].Pixels[0] {
+		seen[c] = true
+	}
+	if len(frames[0].Pixels[0]) != MaxGrid || len(seen) != 4 {
+		t.Errorf("Expected %d pixels in 4 colours, got %d in %d", MaxGrid, len(frames[0].Pixels[0]), len(seen))
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	if got, err := ParseSize("16X8"); err != nil || got != image.Pt(16, 8) {
+		t.Errorf("ParseSize(16X8) = %v, %v", got, err)
+	}
+	for _, bad := range []string{"", "16", "0x4", "axb", "4x-1"} {
+		if _, err := ParseSize(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
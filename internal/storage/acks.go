@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// userAcksFile is the on-disk form of one user's acknowledgements
+type userAcksFile struct {
+	User string               `toml:"user"`
+	Acks map[string]time.Time `toml:"acks"`
+}
+
+// AcksDir returns the directory holding per-user acknowledgement files.
+// Each user writes only their own file, so the directory can be committed
+// without merge conflicts or ignored to keep acknowledgements private.
+func AcksDir(petDir string) string {
+	return filepath.Join(petDir, "acks")
+}
+
+// UserAcksPath returns the acknowledgement file of user
+func UserAcksPath(petDir, user string) string {
+	return filepath.Join(AcksDir(petDir), safeFileName(user)+".toml")
+}
+
+// LoadUserAcks loads the acknowledgements recorded by user. A missing file is not an error.
+func LoadUserAcks(petDir, user string) (map[string]time.Time, error) {
+	data, err := os.ReadFile(UserAcksPath(petDir, user))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]time.Time{}, nil
+		}
+		return nil, fmt.Errorf("failed to read acknowledgements: %w", err)
+	}
+
+	var f userAcksFile
+	if err := toml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse acknowledgements: %w", err)
+	}
+	if f.Acks == nil {
+		f.Acks = map[string]time.Time{}
+	}
+	return f.Acks, nil
+}
+
+// SaveUserAcks writes user's acknowledgements, keeping only ids in keep
+// so acknowledgements of removed or expired messages do not pile up
+func SaveUserAcks(petDir, user string, acks map[string]time.Time, keep map[string]bool) error {
+	pruned := make(map[string]time.Time, len(acks))
+	for id, at := range acks {
+		if keep[id] {
+			pruned[id] = at
+		}
+	}
+
+	data, err := toml.Marshal(userAcksFile{User: user, Acks: pruned})
+	if err != nil {
+		return fmt.Errorf("failed to marshal acknowledgements: %w", err)
+	}
+
+	if err := os.MkdirAll(AcksDir(petDir), 0755); err != nil {
+		return fmt.Errorf("failed to create acknowledgements directory: %w", err)
+	}
+	if err := os.WriteFile(UserAcksPath(petDir, user), data, 0644); err != nil {
+		return fmt.Errorf("failed to write acknowledgements: %w", err)
+	}
+	return nil
+}
+
+// LoadAllUserAcks loads every user's acknowledgement file, keyed by user
+func LoadAllUserAcks(petDir string) (map[string]map[string]time.Time, error) {
+	all := make(map[string]map[string]time.Time)
+
+	entries, err := os.ReadDir(AcksDir(petDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return all, nil
+		}
+		return nil, fmt.Errorf("failed to read acknowledgements directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".toml") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(AcksDir(petDir), entry.Name()))
+		if err != nil {
+			continue
+		}
+		var f userAcksFile
+		if err := toml.Unmarshal(data, &f); err != nil || f.User == "" {
+			continue
+		}
+		all[f.User] = f.Acks
+	}
+	return all, nil
+}
+
+// safeFileName replaces characters that are awkward in file names
+func safeFileName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '@', r == '.', r == '-', r == '_', r == '+':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
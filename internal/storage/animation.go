@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+// bareKey matches TOML keys that need no quotes
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// SetAnimation writes one animation into a pet config or template, replacing
+// any animation with the same key. Unlike SavePetConfig it edits the file as
+// text, so comments, other animations and template placeholders are kept.
+func SetAnimation(configPath, key string, anim pet.AnimationConfig) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	updated := replaceAnimation(string(data), key, FormatAnimation(key, anim))
+	if err := os.WriteFile(configPath, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// FormatAnimation renders an animation as the [animations.<key>] table and
+// its [[animations.<key>.frames]], laid out like the bundled templates
+func FormatAnimation(key string, anim pet.AnimationConfig) string {
+	table := "animations." + tomlKey(key)
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s]\n", table)
+	fmt.Fprintf(&b, "source = %s\n", strconv.Quote(anim.Source))
+	if anim.Path != "" {
+		fmt.Fprintf(&b, "path = %s\n", strconv.Quote(anim.Path))
+	}
+	if anim.URL != "" {
+		fmt.Fprintf(&b, "url = %s\n", strconv.Quote(anim.URL))
+	}
+	if anim.SHA256 != "" {
+		fmt.Fprintf(&b, "sha256 = %s\n", strconv.Quote(anim.SHA256))
+	}
+	if anim.Delimiter != "" {
+		fmt.Fprintf(&b, "delimiter = %s\n", strconv.Quote(anim.Delimiter))
+	}
+	fmt.Fprintf(&b, "fps = %d\n", anim.FPS)
+	fmt.Fprintf(&b, "loops = %d\n", anim.Loops)
+	if anim.PingPong {
+		b.WriteString("pingPong = true\n")
+	}
+	if len(anim.Legend) > 0 {
+		fmt.Fprintf(&b, "legend = { %s }\n", strings.Join(legendEntries(anim.Legend), ", "))
+	}
+
+	for _, frame := range anim.Frames {
+		fmt.Fprintf(&b, "\n[[%s.frames]]\n", table)
+		if frame.MS > 0 {
+			fmt.Fprintf(&b, "ms = %d\n", frame.MS)
+		}
+		if frame.Art != "" {
+			if strings.Contains(frame.Art, "'''") {
+				fmt.Fprintf(&b, "art = %s\n", strconv.Quote(frame.Art))
+			} else {
+				fmt.Fprintf(&b, "art = '''\n%s'''\n", frame.Art)
+			}
+		}
+		if len(frame.Grid) > 0 {
+			b.WriteString("grid = [\n")
+			for _, row := range frame.Grid {
+				fmt.Fprintf(&b, "  %s,\n", strconv.Quote(row))
+			}
+			b.WriteString("]\n")
+		} else if len(frame.Pixels) > 0 {
+			b.WriteString("pixels = [\n")
+			for _, row := range frame.Pixels {
+				cells := make([]string, len(row))
+				for i, c := range row {
+					cells[i] = strconv.Quote(c)
+				}
+				fmt.Fprintf(&b, "  [%s],\n", strings.Join(cells, ", "))
+			}
+			b.WriteString("]\n")
+		}
+	}
+	return b.String()
+}
+
+// SetLegend writes the config's shared [legend] table, replacing any there
+// is, or adding it above the animations
+func SetLegend(configPath string, legend map[string]string) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	section := "[legend]\n" + strings.Join(legendEntries(legend), "\n") + "\n"
+	content := string(data)
+	if !hasTable(content, "legend") {
+		content = insertBeforeTable(content, "animations", "[legend]\n")
+	}
+	updated := replaceTables(content, map[string]bool{"legend": true}, section)
+	if err := os.WriteFile(configPath, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// legendEntries renders a legend as sorted key = "colour" pairs
+func legendEntries(legend map[string]string) []string {
+	keys := make([]string, 0, len(legend))
+	for k := range legend {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	entries := make([]string, len(keys))
+	for i, k := range keys {
+		entries[i] = fmt.Sprintf("%s = %s", tomlKey(k), strconv.Quote(legend[k]))
+	}
+	return entries
+}
+
+// tomlKey quotes key when it is not a bare key, e.g. "e2:happy"
+func tomlKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// tableHeader matches a [table] or [[array.of.tables]] line and captures
+// its dotted name. The first key must be bare, so rows of a pixels array
+// such as ["", "#FFD700"] never match.
+var tableHeader = regexp.MustCompile(`^\s*\[\[?\s*([A-Za-z0-9_-]+(?:\s*\.\s*(?:[A-Za-z0-9_-]+|"[^"]*"))*)\s*\]\]?\s*(?:#.*)?$`)
+
+// dots normalises spacing around the dots of a table name
+var dots = regexp.MustCompile(`\s*\.\s*`)
+
+// replaceAnimation swaps the tables of animation key in content for section,
+// or appends section when there are none
+func replaceAnimation(content, key, section string) string {
+	own := map[string]bool{}
+	for _, k := range []string{key, strconv.Quote(key)} {
+		own["animations."+k] = true
+		own["animations."+k+".frames"] = true
+	}
+	return replaceTables(content, own, section)
+}
+
+// tableNames returns the dotted name of each table header of content, by
+// line, skipping multi-line strings
+func tableNames(content string) []string {
+	lines := strings.Split(content, "\n")
+	names := make([]string, len(lines))
+	multiline := ""
+	for i, line := range lines {
+		if multiline == "" {
+			if m := tableHeader.FindStringSubmatch(line); m != nil {
+				names[i] = dots.ReplaceAllString(m[1], ".")
+			}
+		}
+		multiline = multilineState(line, multiline)
+	}
+	return names
+}
+
+// hasTable reports whether content has a table called name
+func hasTable(content, name string) bool {
+	for _, n := range tableNames(content) {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// insertBeforeTable adds section above the first table called name or
+// within it, and the comments directly above that table, or appends it
+func insertBeforeTable(content, name, section string) string {
+	lines := strings.Split(content, "\n")
+	for i, n := range tableNames(content) {
+		if n != name && !strings.HasPrefix(n, name+".") {
+			continue
+		}
+		for i > 0 && strings.HasPrefix(strings.TrimSpace(lines[i-1]), "#") {
+			i--
+		}
+		out := append(append([]string{}, lines[:i]...), strings.Split(section, "\n")...)
+		return strings.Join(append(out, lines[i:]...), "\n")
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + section
+}
+
+// replaceTables swaps the tables in own for section, or appends section when
+// there are none. Comments directly above the next table stay with it.
+func replaceTables(content string, own map[string]bool, section string) string {
+	sectionLines := strings.Split(strings.TrimSuffix(section, "\n"), "\n")
+
+	var out, held []string
+	inserted, skipping, multiline := false, false, ""
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		name, isTable := "", false
+		if multiline == "" {
+			if m := tableHeader.FindStringSubmatch(line); m != nil {
+				name, isTable = dots.ReplaceAllString(m[1], "."), true
+			}
+		}
+		multiline = multilineState(line, multiline)
+
+		switch {
+		case isTable && own[name]:
+			if !skipping && !inserted {
+				out = append(out, sectionLines...)
+				out = append(out, "")
+				inserted = true
+			}
+			skipping, held = true, nil
+			continue
+		case isTable && skipping:
+			// Comments just above this table describe it, not the animation replaced
+			skipping = false
+			out = append(out, held...)
+		case skipping:
+			trimmed := strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(trimmed, "#") && multiline == "":
+				held = append(held, line)
+			case trimmed != "":
+				held = nil
+			}
+			continue
+		}
+		out = append(out, line)
+	}
+
+	if !inserted {
+		out = append(out, "")
+		out = append(out, sectionLines...)
+	}
+	return strings.TrimRight(strings.Join(out, "\n"), "\n") + "\n"
+}
+
+// multilineState returns the delimiter of the multi-line string left open
+// after line, given the one open before it ("" for none)
+func multilineState(line, open string) string {
+	for {
+		if open != "" {
+			i := strings.Index(line, open)
+			if i < 0 {
+				return open
+			}
+			line, open = line[i+3:], ""
+			continue
+		}
+		i, j := strings.Index(line, "'''"), strings.Index(line, `"""`)
+		switch {
+		case i < 0 && j < 0:
+			return ""
+		case j < 0 || (i >= 0 && i < j):
+			line, open = line[i+3:], "'''"
+		default:
+			line, open = line[j+3:], `"""`
+		}
+	}
+}
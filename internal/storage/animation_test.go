@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+const animationConfig = `name = "Pip"
+
+# The familiar's default look
+[animations.default]
+source = "inline"
+fps = 1
+loops = 1
+
+[[animations.default.frames]]
+art = '''
+[animations.happy]
+'''
+
+# Shown when happy
+[animations.happy]
+source = "inline"
+fps = 1
+loops = 1
+
+[[animations.happy.frames]]
+art = "^_^"
+`
+
+func TestSetAnimation(t *testing.T) {
+	grid := pet.AnimationConfig{
+		Source: "pixel",
+		FPS:    4,
+		Loops:  2,
+		Legend: map[string]string{"g": "#00FF00"},
+		Frames: []pet.Frame{{Grid: []string{"g.", ".g"}, MS: 80}},
+	}
+	tests := map[string]struct {
+		key        string
+		anim       pet.AnimationConfig
+		wantErr    string // from loading the written config
+		animations int
+		wantKeep   []string
+		wantFrame  string
+	}{
+		"replace": {
+			key:        "happy",
+			anim:       grid,
+			animations: 2,
+			wantKeep:   []string{"# The familiar's default look", "# Shown when happy", "art = '''\n[animations.happy]\n'''"},
+			wantFrame:  `"g."`,
+		},
+		"append": {
+			key:        "e2:happy",
+			anim:       pet.AnimationConfig{Source: "inline", FPS: 1, Frames: []pet.Frame{{Art: "o'''o\n"}}},
+			animations: 3,
+			wantKeep:   []string{`art = "^_^"`, `[animations."e2:happy"]`},
+			wantFrame:  `art = "o'''o\n"`,
+		},
+		"bad legend symbol": {
+			key:     "happy",
+			anim:    pet.AnimationConfig{Source: "pixel", Legend: grid.Legend, Frames: []pet.Frame{{Grid: []string{"gx"}}}},
+			wantErr: "animation happy: frame 1: row 1 column 2: 'x' is not in the legend",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pet.toml")
+			if err := os.WriteFile(path, []byte(animationConfig), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := SetAnimation(path, tt.key, tt.anim); err != nil {
+				t.Fatalf("SetAnimation() error = %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var config pet.PetConfig
+			if err := toml.Unmarshal(data, &config); err != nil {
+				t.Fatalf("Written config does not parse: %v\n%s", err, data)
+			}
+			err = config.ExpandGrids()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ExpandGrids() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandGrids() error = %v", err)
+			}
+			if len(config.Animations) != tt.animations {
+				t.Errorf("Expected %d animations, the others kept, got %d", tt.animations, len(config.Animations))
+			}
+			for _, want := range append(tt.wantKeep, tt.wantFrame) {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("Expected %q in the written config:\n%s", want, data)
+				}
+			}
+			if got := config.Animations[tt.key]; len(got.Frames) != len(tt.anim.Frames) || got.FPS != tt.anim.FPS {
+				t.Errorf("Animation %s read back as %+v", tt.key, got)
+			}
+		})
+	}
+
+	if err := SetAnimation(filepath.Join(t.TempDir(), "missing.toml"), "happy", grid); err == nil || !strings.Contains(err.Error(), "failed to read config file") {
+		t.Errorf("SetAnimation() on a missing file error = %v", err)
+	}
+}
+
+func TestSetLegend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pet.toml")
+	if err := os.WriteFile(path, []byte(animationConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, legend := range []map[string]string{{"g": "#00FF00"}, {"g": "#008800", "e2:x": "fur"}} {
+		if err := SetLegend(path, legend); err != nil {
+			t.Fatalf("SetLegend() error = %v", err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config pet.PetConfig
+	if err := toml.Unmarshal(data, &config); err != nil {
+		t.Fatalf("Written config does not parse: %v\n%s", err, data)
+	}
+	if config.Legend["g"] != "#008800" || config.Legend["e2:x"] != "fur" || len(config.Animations) != 2 {
+		t.Errorf("Unexpected config after replacing the legend:\n%s", data)
+	}
+	if i, j := strings.Index(string(data), "[legend]"), strings.Index(string(data), "# The familiar's default look"); i < 0 || i > j {
+		t.Errorf("Expected the legend above the animations and their comments:\n%s", data)
+	}
+
+	if err := SetLegend(filepath.Join(t.TempDir(), "missing.toml"), nil); err == nil {
+		t.Error("Expected an error for a missing config file")
+	}
+}
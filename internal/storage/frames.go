@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+const (
+	// DefaultFrameDelimiter separates frames in plain-text frame files
+	DefaultFrameDelimiter = "---"
+
+	// maxFrameFileSize bounds frame files read from disk or fetched
+	maxFrameFileSize = 4 << 20
+)
+
+// fetchTimeout bounds a url fetch so a slow network never stalls a command
+var fetchTimeout = 5 * time.Second
+
+// frameSet is the TOML frame file format: the frames table of an animation
+type frameSet struct {
+	Frames []pet.Frame       `toml:"frames"`
+	Legend map[string]string `toml:"legend"` // for grid frames
+}
+
+// LoadFrames returns the frames of an animation. Inline and pixel animations
+// carry their own; file animations are read from Path, relative to baseDir;
+// url animations are fetched and cached for ttl (0 refetches every time, but
+// the last copy is still kept for when the network is unavailable).
+func LoadFrames(anim pet.AnimationConfig, baseDir string, ttl time.Duration) ([]pet.Frame, error) {
+	switch anim.Source {
+	case "file":
+		if anim.Path == "" {
+			return nil, fmt.Errorf("file animation has no path")
+		}
+		file := anim.Path
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(baseDir, file)
+		}
+		data, err := readLimited(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read frame file: %w", err)
+		}
+		if err := verifyChecksum(data, anim.SHA256); err != nil {
+			return nil, fmt.Errorf("frame file %s: %w", anim.Path, err)
+		}
+		return ParseFrames(data, filepath.Ext(file), anim.Delimiter)
+	case "url":
+		data, err := fetchFrames(anim.URL, anim.SHA256, ttl, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		u, _ := url.Parse(anim.URL)
+		return ParseFrames(data, path.Ext(u.Path), anim.Delimiter)
+	}
+	return anim.Frames, nil
+}
+
+// ParseFrames parses a frame file. A ".toml" extension selects a TOML frame
+// set ([[frames]] tables, as in pet.toml); anything else is plain text with
+// frames separated by lines holding only the delimiter.
+func ParseFrames(data []byte, ext, delimiter string) ([]pet.Frame, error) {
+	if strings.EqualFold(ext, ".toml") {
+		var set frameSet
+		if err := toml.Unmarshal(data, &set); err != nil {
+			return nil, fmt.Errorf("failed to parse frame set: %w", err)
+		}
+		if len(set.Frames) == 0 {
+			return nil, fmt.Errorf("frame set has no frames")
+		}
+		for i, frame := range set.Frames {
+			if len(frame.Grid) == 0 {
+				continue
+			}
+			pixels, err := pet.ExpandGrid(frame.Grid, set.Legend)
+			if err != nil {
+				return nil, fmt.Errorf("frame set frame %d: %w", i+1, err)
+			}
+			set.Frames[i].Pixels = pixels
+		}
+		return set.Frames, nil
+	}
+
+	if delimiter == "" {
+		delimiter = DefaultFrameDelimiter
+	}
+	var frames []pet.Frame
+	var lines []string
+	flush := func() {
+		// Blank lines around a delimiter belong to neither frame
+		for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+			lines = lines[1:]
+		}
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		if len(lines) > 0 {
+			frames = append(frames, pet.Frame{Art: strings.Join(lines, "\n") + "\n"})
+		}
+		lines = nil
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == delimiter {
+			flush()
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("frame file has no frames")
+	}
+	return frames, nil
+}
+
+// fetchFrames returns the content at rawURL, from the cache while it is
+// younger than ttl. When the fetch fails the last cached copy is used.
+func fetchFrames(rawURL, checksum string, ttl time.Duration, now time.Time) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid animation url '%s' (expected http or https)", rawURL)
+	}
+
+	cachePath, err := frameCachePath(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	cached, cacheErr := readLimited(cachePath)
+	if cacheErr == nil && verifyChecksum(cached, checksum) != nil {
+		// Pinned to a different checksum since it was cached
+		cached, cacheErr = nil, fmt.Errorf("cached copy does not match sha256")
+	}
+	if cacheErr == nil && ttl > 0 {
+		if info, err := os.Stat(cachePath); err == nil && now.Sub(info.ModTime()) < ttl {
+			return cached, nil
+		}
+	}
+
+	data, err := fetch(rawURL)
+	if err == nil {
+		err = verifyChecksum(data, checksum)
+	}
+	if err != nil {
+		if cacheErr == nil {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+
+	// An unwritable cache only costs a refetch next time
+	_ = writeFrameCache(cachePath, data)
+	return data, nil
+}
+
+func fetch(rawURL string) ([]byte, error) {
+	client := &http.Client{Timeout: fetchTimeout}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFrameFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFrameFileSize {
+		return nil, fmt.Errorf("larger than %d bytes", maxFrameFileSize)
+	}
+	return data, nil
+}
+
+// verifyChecksum checks data against a pinned hex sha256; an empty pin accepts anything
+func verifyChecksum(data []byte, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, checksum) {
+		return fmt.Errorf("sha256 mismatch: got %s, pinned %s", got, checksum)
+	}
+	return nil
+}
+
+func readLimited(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxFrameFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFrameFileSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", path, maxFrameFileSize)
+	}
+	return data, nil
+}
+
+// frameCachePath is the per-user cache file for an animation url
+func frameCachePath(rawURL string) (string, error) {
+	base, err := UserStateDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(base, "cache", "frames", hex.EncodeToString(sum[:8])), nil
+}
+
+func writeFrameCache(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create frame cache directory: %w", err)
+	}
+	// Write to a temporary file first so concurrent commands never read half a copy
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".frames-*")
+	if err != nil {
+		return fmt.Errorf("failed to write frame cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write frame cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write frame cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write frame cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+func TestLoadFrames(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"walk.txt":   " o\n---\n  o\n",
+		"empty.txt":  "---\n\n---\n",
+		"walk.toml":  "legend = { g = \"#00FF00\" }\n\n[[frames]]\ngrid = [\"g.\", \".g\"]\nms = 80\n",
+		"bad.toml":   "legend = { g = \"#00FF00\" }\n\n[[frames]]\ngrid = [\"gx\"]\n",
+		"nones.toml": "fps = 2\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]struct {
+		anim    pet.AnimationConfig
+		want    []pet.Frame
+		wantErr string
+	}{
+		"inline": {
+			anim: pet.AnimationConfig{Source: "inline", Frames: []pet.Frame{{Art: "o\n"}}},
+			want: []pet.Frame{{Art: "o\n"}},
+		},
+		"text file": {
+			anim: pet.AnimationConfig{Source: "file", Path: "walk.txt"},
+			want: []pet.Frame{{Art: " o\n"}, {Art: "  o\n"}},
+		},
+		"toml grid": {
+			anim: pet.AnimationConfig{Source: "file", Path: "walk.toml"},
+			want: []pet.Frame{{Grid: []string{"g.", ".g"}, Pixels: [][]string{{"#00FF00", ""}, {"", "#00FF00"}}, MS: 80}},
+		},
+		"bad legend symbol": {
+			anim:    pet.AnimationConfig{Source: "file", Path: "bad.toml"},
+			wantErr: "frame set frame 1: row 1 column 2: 'x' is not in the legend",
+		},
+		"no frames": {
+			anim:    pet.AnimationConfig{Source: "file", Path: "empty.txt"},
+			wantErr: "frame file has no frames",
+		},
+		"no toml frames": {
+			anim:    pet.AnimationConfig{Source: "file", Path: "nones.toml"},
+			wantErr: "frame set has no frames",
+		},
+		"missing file": {
+			anim:    pet.AnimationConfig{Source: "file", Path: "missing.txt"},
+			wantErr: "failed to read frame file",
+		},
+		"no path": {
+			anim:    pet.AnimationConfig{Source: "file"},
+			wantErr: "file animation has no path",
+		},
+		"checksum": {
+			anim:    pet.AnimationConfig{Source: "file", Path: "walk.txt", SHA256: "00"},
+			wantErr: "sha256 mismatch",
+		},
+		"bad url": {
+			anim:    pet.AnimationConfig{Source: "url", URL: "ftp://example.com/walk.txt"},
+			wantErr: "expected http or https",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := LoadFrames(tt.anim, dir, 0)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadFrames() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFrames() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadFrames() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFramesURL(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte("o\n---\no o\n"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		url     string
+		status  int
+		wantErr string
+		frames  int
+	}{
+		// Nothing is cached yet, so a failed fetch is an error
+		{"fetch error", server.URL + "/walk.txt", http.StatusInternalServerError, "unexpected status 500", 0},
+		{"fetched", server.URL + "/walk.txt", http.StatusOK, "", 2},
+		// Now the cached copy stands in for the failed fetch
+		{"cached", server.URL + "/walk.txt", http.StatusNotFound, "", 2},
+		{"unreachable", "http://127.0.0.1:1/walk.txt", http.StatusOK, "failed to fetch", 0},
+	}
+	for _, tt := range tests {
+		status = tt.status
+		frames, err := LoadFrames(pet.AnimationConfig{Source: "url", URL: tt.url}, "", 0)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: LoadFrames() error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || len(frames) != tt.frames {
+			t.Errorf("%s: LoadFrames() = %d frames, %v, want %d", tt.name, len(frames), err, tt.frames)
+		}
+	}
+}
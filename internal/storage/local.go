@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+// UserStateDir returns the directory for per-user data that must never be
+// committed: $XDG_STATE_HOME/familiar, or ~/.local/state/familiar
+func UserStateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "familiar"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "familiar"), nil
+}
+
+// localStatePath maps a familiar directory to its per-user state file
+func localStatePath(petDir string) (string, error) {
+	base, err := UserStateDir()
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(petDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve familiar directory: %w", err)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(base, "pets", hex.EncodeToString(sum[:8])+".toml"), nil
+}
+
+// LoadLocalState loads this user's state for the familiar in petDir.
+// A missing file yields an empty state.
+func LoadLocalState(petDir string) (*pet.LocalState, error) {
+	path, err := localStatePath(petDir)
+	if err != nil {
+		return nil, err
+	}
+
+	abs, _ := filepath.Abs(petDir)
+	local := &pet.LocalState{PetDir: abs}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return local, nil
+		}
+		return nil, fmt.Errorf("failed to read local state: %w", err)
+	}
+	if err := toml.Unmarshal(data, local); err != nil {
+		return nil, fmt.Errorf("failed to parse local state: %w", err)
+	}
+	return local, nil
+}
+
+// SaveLocalState writes this user's state for the familiar in petDir
+func SaveLocalState(petDir string, local *pet.LocalState) error {
+	path, err := localStatePath(petDir)
+	if err != nil {
+		return err
+	}
+
+	data, err := toml.Marshal(local)
+	if err != nil {
+		return fmt.Errorf("failed to marshal local state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create local state directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write local state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/sethgrid/familiar/internal/prompt"
+)
+
+// PromptCache is a computed prompt segment and the files it was computed from.
+// It lets 'familiar admin health' skip loading and decaying the familiar.
+type PromptCache struct {
+	Segment    prompt.Segment `toml:"segment"`
+	ComputedAt time.Time      `toml:"computedAt"`
+	ValidUntil time.Time      `toml:"validUntil"`
+	Inputs     []FileStamp    `toml:"inputs"`
+}
+
+// FileStamp identifies one version of a file by modification time and size
+type FileStamp struct {
+	Path    string `toml:"path"`
+	ModTime int64  `toml:"modTime"` // Unix nanoseconds, 0 when the file is missing
+	Size    int64  `toml:"size"`
+}
+
+// StampFiles stamps each path. Missing files are stamped too, so creating
+// one later invalidates the cache.
+func StampFiles(paths ...string) []FileStamp {
+	stamps := make([]FileStamp, len(paths))
+	for i, path := range paths {
+		stamps[i] = stampFile(path)
+	}
+	return stamps
+}
+
+func stampFile(path string) FileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileStamp{Path: path}
+	}
+	return FileStamp{Path: path, ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+}
+
+// Fresh reports whether the cached segment can be used at now: it has not
+// expired and none of its input files have changed
+func (c *PromptCache) Fresh(now time.Time) bool {
+	if now.Before(c.ComputedAt) || !now.Before(c.ValidUntil) {
+		return false
+	}
+	return Unchanged(c.Inputs)
+}
+
+// Unchanged reports whether every stamped file is as it was when stamped
+func Unchanged(stamps []FileStamp) bool {
+	for _, stamp := range stamps {
+		if stampFile(stamp.Path) != stamp {
+			return false
+		}
+	}
+	return true
+}
+
+// PromptInputs lists the files a prompt segment depends on, other than
+// per-user acknowledgements: the state, the config and this user's local state
+func PromptInputs(petDir, configPath, statePath string) ([]string, error) {
+	local, err := localStatePath(petDir)
+	if err != nil {
+		return nil, err
+	}
+	return []string{statePath, configPath, local}, nil
+}
+
+// promptCachePath sits next to the local state file for petDir
+func promptCachePath(petDir string) (string, error) {
+	path, err := localStatePath(petDir)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(path, ".toml") + ".prompt.toml", nil
+}
+
+// LoadPromptCache loads the cached prompt segment for the familiar in petDir
+func LoadPromptCache(petDir string) (*PromptCache, error) {
+	path, err := promptCachePath(petDir)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt cache: %w", err)
+	}
+	var c PromptCache
+	if err := toml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt cache: %w", err)
+	}
+	return &c, nil
+}
+
+// SavePromptCache writes the cached prompt segment for the familiar in petDir
+func SavePromptCache(petDir string, c *PromptCache) error {
+	path, err := promptCachePath(petDir)
+	if err != nil {
+		return err
+	}
+	data, err := toml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to marshal prompt cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create local state directory: %w", err)
+	}
+	// Write to a temporary file first so concurrent prompts never read half a cache
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".prompt-*")
+	if err != nil {
+		return fmt.Errorf("failed to write prompt cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write prompt cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write prompt cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write prompt cache: %w", err)
+	}
+	return nil
+}
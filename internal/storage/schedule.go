@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+// scheduleID matches the id key of a [[schedules]] entry and captures its
+// quoted value
+var scheduleID = regexp.MustCompile(`^\s*id\s*=\s*("(?:[^"\\]|\\.)*"|'[^']*')\s*(?:#.*)?$`)
+
+// AddSchedule writes s into a pet config as a new [[schedules]] entry, after
+// the others or above the animations. Like SetAnimation it edits the file as
+// text, so comments and values such as sleepDuration = "30m" are kept.
+func AddSchedule(configPath string, s pet.ScheduleConfig) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	content, section := string(data), formatSchedule(s)
+	if blocks := scheduleBlocks(content); len(blocks) > 0 {
+		lines := strings.Split(content, "\n")
+		end := blocks[len(blocks)-1].end
+		out := append(append([]string{}, lines[:end]...), "")
+		out = append(out, strings.Split(strings.TrimSuffix(section, "\n"), "\n")...)
+		content = strings.Join(append(out, lines[end:]...), "\n")
+	} else {
+		content = insertBeforeTable(content, "animations", section)
+	}
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// RemoveSchedule deletes the [[schedules]] entry with id from a pet config,
+// editing the file as text like AddSchedule
+func RemoveSchedule(configPath, id string) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	content := string(data)
+	for _, block := range scheduleBlocks(content) {
+		if block.id != id {
+			continue
+		}
+		lines := strings.Split(content, "\n")
+		start, end := block.start, block.end
+		// Drop one of the blank lines that separated it from its neighbours
+		if start > 0 && strings.TrimSpace(lines[start-1]) == "" && (end == len(lines) || strings.TrimSpace(lines[end]) == "") {
+			start--
+		}
+		updated := strings.Join(append(append([]string{}, lines[:start]...), lines[end:]...), "\n")
+		if err := os.WriteFile(configPath, []byte(updated), 0644); err != nil {
+			return fmt.Errorf("failed to write config file: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("no schedule with id '%s' in %s", id, configPath)
+}
+
+// scheduleBlock is the lines of one [[schedules]] entry, from its header up
+// to the blank lines and comments above whatever follows
+type scheduleBlock struct {
+	id         string
+	start, end int
+}
+
+// scheduleBlocks finds the [[schedules]] entries of content, in order
+func scheduleBlocks(content string) []scheduleBlock {
+	lines := strings.Split(content, "\n")
+	names := tableNames(content)
+	var blocks []scheduleBlock
+	for i, name := range names {
+		if name != "schedules" {
+			continue
+		}
+		block := scheduleBlock{start: i, end: len(lines)}
+		multiline := ""
+		for j := i + 1; j < len(lines); j++ {
+			if names[j] != "" {
+				block.end = j
+				break
+			}
+			if m := scheduleID.FindStringSubmatch(lines[j]); m != nil && multiline == "" && block.id == "" {
+				block.id = unquoteKey(m[1])
+			}
+			multiline = multilineState(lines[j], multiline)
+		}
+		// Comments and blank lines at the end belong to what follows
+		for block.end > i+1 {
+			trimmed := strings.TrimSpace(lines[block.end-1])
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				break
+			}
+			block.end--
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// unquoteKey returns the value of a basic "..." or literal '...' TOML string
+func unquoteKey(quoted string) string {
+	if strings.HasPrefix(quoted, "'") {
+		return strings.Trim(quoted, "'")
+	}
+	value, err := strconv.Unquote(quoted)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// formatSchedule renders s as a [[schedules]] entry, laid out like the README
+func formatSchedule(s pet.ScheduleConfig) string {
+	var b strings.Builder
+	b.WriteString("[[schedules]]\n")
+	fmt.Fprintf(&b, "id = %s\n", strconv.Quote(s.ID))
+	fmt.Fprintf(&b, "message = %s\n", strconv.Quote(s.Message))
+	for _, field := range []struct{ key, value string }{
+		{"cron", s.Cron},
+		{"every", s.Every},
+		{"timezone", s.Timezone},
+		{"severity", string(s.Severity)},
+		{"expires", s.Expires},
+		{"until", s.Until},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&b, "%s = %s\n", field.key, strconv.Quote(field.value))
+		}
+	}
+	return b.String()
+}
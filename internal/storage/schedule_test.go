@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+const scheduleConfig = `name = "Pip"
+sleepDuration = "30m" # a nap
+
+[[schedules]]
+id = 'standup'
+message = """
+Stand up
+id = "not-this"
+"""
+every = "weekday 09:30"
+
+# The familiar's default look
+[animations.default]
+source = "inline"
+fps = 1
+loops = 1
+`
+
+func TestAddRemoveSchedule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pet.toml")
+	if err := os.WriteFile(path, []byte(scheduleConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	added := []pet.ScheduleConfig{
+		{ID: "oncall", Message: `Rotate the "pager"`, Every: "monday 09:00", Timezone: "Europe/Berlin"},
+		{ID: "backup", Message: "Check backups", Cron: "0 9 * * 1", Severity: pet.SeverityWarn},
+	}
+	for _, s := range added {
+		if err := AddSchedule(path, s); err != nil {
+			t.Fatalf("AddSchedule() error = %v", err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// LoadPet handles sleepDuration strings; only the schedules are needed here
+	var config struct {
+		Schedules []pet.ScheduleConfig `toml:"schedules"`
+	}
+	if err := toml.Unmarshal(data, &config); err != nil {
+		t.Fatalf("Written config does not parse: %v\n%s", err, data)
+	}
+	var ids []string
+	for _, s := range config.Schedules {
+		ids = append(ids, s.ID)
+	}
+	if strings.Join(ids, " ") != "standup oncall backup" || config.Schedules[1] != added[0] || config.Schedules[2] != added[1] {
+		t.Errorf("Expected the schedules added after standup, got %+v", config.Schedules)
+	}
+	for _, want := range []string{`sleepDuration = "30m" # a nap`, "# The familiar's default look\n[animations.default]"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q kept in the written config:\n%s", want, data)
+		}
+	}
+
+	// Removing them all leaves the file as it was
+	for _, id := range []string{"oncall", "standup", "backup"} {
+		if err := RemoveSchedule(path, id); err != nil {
+			t.Fatalf("RemoveSchedule(%s) error = %v", id, err)
+		}
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(scheduleConfig, scheduleConfig[strings.Index(scheduleConfig, "[[schedules]]"):strings.Index(scheduleConfig, "# The")], "", 1)
+	if string(data) != want {
+		t.Errorf("Expected only the schedules removed, got:\n%s", data)
+	}
+
+	if err := RemoveSchedule(path, "not-this"); err == nil || !strings.Contains(err.Error(), "no schedule with id 'not-this'") {
+		t.Errorf("RemoveSchedule() of a missing id error = %v", err)
+	}
+}
@@ -40,6 +40,16 @@ func LoadPet(configPath, statePath string) (*pet.Pet, error) {
 		return nil, fmt.Errorf("failed to parse state file: %w", err)
 	}
 
+	// Migrate the single message field from older state files into the queue
+	if state.LegacyMessage != "" {
+		state.AddMessage(pet.Message{
+			Text:      state.LegacyMessage,
+			Severity:  pet.SeverityInfo,
+			CreatedAt: state.LastChecked,
+		})
+		state.LegacyMessage = ""
+	}
+
 	// Load config
 	configData, err := os.ReadFile(configPath)
 	if err != nil {
@@ -91,6 +101,13 @@ func LoadPet(configPath, statePath string) (*pet.Pet, error) {
 		}
 	}
 
+	if err := config.ExpandGrids(); err != nil {
+		return nil, fmt.Errorf("failed to read pixel grids in config file: %w", err)
+	}
+	if err := config.ValidatePalettes(); err != nil {
+		return nil, fmt.Errorf("failed to read palettes in config file: %w", err)
+	}
+
 	return &pet.Pet{
 		Config: config,
 		State:  state,
@@ -116,6 +133,21 @@ func SavePetState(p *pet.Pet, statePath string) error {
 	return nil
 }
 
+// SavePetConfig rewrites pet.toml from p.Config. Comments and formatting in
+// the existing file are not preserved, but grid frames stay grids.
+func SavePetConfig(p *pet.Pet, configPath string) error {
+	data, err := toml.Marshal(p.Config.Compact())
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
 // FindLibDir attempts to find the lib directory relative to the executable or source
 func FindLibDir() (string, error) {
 	return findLibDir()
@@ -292,6 +324,13 @@ func LoadTemplateConfig(petType string) (*pet.Pet, error) {
 		}
 	}
 
+	if err := templateConfig.ExpandGrids(); err != nil {
+		return nil, fmt.Errorf("failed to read pixel grids in template: %w", err)
+	}
+	if err := templateConfig.ValidatePalettes(); err != nil {
+		return nil, fmt.Errorf("failed to read palettes in template: %w", err)
+	}
+
 	// Create a minimal pet with just the config (no state needed for art preview)
 	return &pet.Pet{
 		Config: templateConfig,
@@ -0,0 +1,290 @@
+package term
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Depth is the number of colours an output supports
+type Depth int
+
+const (
+	DepthNone Depth = iota // no escape sequences at all
+	Depth16                // SGR 30-37 and 90-97, e.g. the Linux console
+	Depth256               // xterm 256-colour palette
+	DepthTrue              // 24-bit colour
+)
+
+func (d Depth) String() string {
+	switch d {
+	case Depth16:
+		return "16"
+	case Depth256:
+		return "256"
+	case DepthTrue:
+		return "truecolor"
+	}
+	return "none"
+}
+
+// Colour modes for --color
+const (
+	ColorAuto   = "auto"   // detect from the environment and whether output is a terminal
+	ColorAlways = "always" // colour even when output is not a terminal
+	ColorNever  = "never"
+)
+
+var colorMode = ColorAuto
+
+// SetColorMode sets the process-wide --color mode
+func SetColorMode(mode string) error {
+	switch mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		colorMode = mode
+		return nil
+	}
+	return fmt.Errorf("invalid color mode '%s' (expected auto, always or never)", mode)
+}
+
+// ColorDepth returns the colour depth to use for output written to f
+func ColorDepth(f *os.File) Depth {
+	return resolveDepth(colorMode, os.Getenv, IsTerminal(f))
+}
+
+// PromptColorDepth is ColorDepth for output a shell embeds in its prompt. It
+// reaches the terminal through a pipe, so it is treated as a terminal.
+func PromptColorDepth() Depth {
+	return resolveDepth(colorMode, os.Getenv, true)
+}
+
+func resolveDepth(mode string, getenv func(string) string, tty bool) Depth {
+	switch mode {
+	case ColorNever:
+		return DepthNone
+	case ColorAlways:
+		return max(envDepth(getenv), Depth16)
+	}
+	return DetectDepth(getenv, tty)
+}
+
+// DetectDepth works out the colour depth from NO_COLOR, FORCE_COLOR, COLORTERM
+// and TERM. Output that is not a terminal gets no colour unless FORCE_COLOR is set.
+func DetectDepth(getenv func(string) string, tty bool) Depth {
+	if force, ok := forceDepth(getenv("FORCE_COLOR")); ok {
+		return force
+	}
+	if getenv("NO_COLOR") != "" || !tty {
+		return DepthNone
+	}
+	return envDepth(getenv)
+}
+
+// forceDepth interprets FORCE_COLOR: 0 or false disables colour, 1-3 select
+// 16, 256 or 24-bit colour, and any other value forces at least 16 colours
+func forceDepth(v string) (Depth, bool) {
+	switch strings.ToLower(v) {
+	case "":
+		return DepthNone, false
+	case "0", "false":
+		return DepthNone, true
+	case "2":
+		return Depth256, true
+	case "3":
+		return DepthTrue, true
+	}
+	return Depth16, true
+}
+
+// envDepth reads the terminal's depth from COLORTERM and TERM
+func envDepth(getenv func(string) string) Depth {
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return DepthTrue
+	}
+	t := strings.ToLower(getenv("TERM"))
+	switch {
+	case t == "dumb":
+		return DepthNone
+	case strings.Contains(t, "truecolor") || strings.Contains(t, "direct"):
+		return DepthTrue
+	case strings.Contains(t, "256color"):
+		return Depth256
+	}
+	return Depth16
+}
+
+// Color is a foreground colour parsed from SGR parameters
+type Color struct {
+	Depth   Depth // Depth16 for a basic colour, Depth256 or DepthTrue
+	Index   int   // palette index for Depth16 (0-15) and Depth256
+	R, G, B int
+}
+
+// ParseSGR finds the foreground colour in SGR parameters such as "32",
+// "1;93", "38;5;208" or "38;2;255;136;0"
+func ParseSGR(params string) (Color, bool) {
+	parts := strings.Split(params, ";")
+	for i := 0; i < len(parts); i++ {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return Color{}, false
+		}
+		switch {
+		case n >= 30 && n <= 37:
+			return paletteColor(Depth16, n-30), true
+		case n >= 90 && n <= 97:
+			return paletteColor(Depth16, n-90+8), true
+		case n == 38 && i+2 < len(parts) && parts[i+1] == "5":
+			index, err := strconv.Atoi(parts[i+2])
+			if err != nil || index < 0 || index > 255 {
+				return Color{}, false
+			}
+			return paletteColor(Depth256, index), true
+		case n == 38 && i+4 < len(parts) && parts[i+1] == "2":
+			var rgb [3]int
+			for j := range rgb {
+				v, err := strconv.Atoi(parts[i+2+j])
+				if err != nil || v < 0 || v > 255 {
+					return Color{}, false
+				}
+				rgb[j] = v
+			}
+			return Color{Depth: DepthTrue, R: rgb[0], G: rgb[1], B: rgb[2]}, true
+		}
+	}
+	return Color{}, false
+}
+
+func paletteColor(depth Depth, index int) Color {
+	r, g, b := PaletteRGB(index)
+	return Color{Depth: depth, Index: index, R: r, G: g, B: b}
+}
+
+// Hex formats the colour as "#rrggbb"
+func (c Color) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// basicPalette holds the xterm defaults for the 16 basic colours
+var basicPalette = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the channel values of the 6x6x6 colour cube (indices 16-231)
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// PaletteRGB returns the xterm RGB value of a 256-colour palette index
+func PaletteRGB(index int) (r, g, b int) {
+	switch {
+	case index < 16:
+		c := basicPalette[max(index, 0)]
+		return c[0], c[1], c[2]
+	case index < 232:
+		i := index - 16
+		return cubeLevels[i/36], cubeLevels[i/6%6], cubeLevels[i%6]
+	default:
+		v := 8 + (min(index, 255)-232)*10
+		return v, v, v
+	}
+}
+
+// Nearest256 returns the 256-colour index closest to an RGB colour, from the
+// colour cube or the grey ramp
+func Nearest256(r, g, b int) int {
+	level := func(v int) int {
+		best := 0
+		for i, l := range cubeLevels {
+			if abs(v-l) < abs(v-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	ri, gi, bi := level(r), level(g), level(b)
+	cube := 16 + 36*ri + 6*gi + bi
+
+	grey := (r + g + b) / 3
+	greyIndex := 232 + min(max((grey-3)/10, 0), 23)
+
+	if distance(r, g, b, greyIndex) < distance(r, g, b, cube) {
+		return greyIndex
+	}
+	return cube
+}
+
+// Nearest16 returns the basic colour index (0-15) closest to an RGB colour
+func Nearest16(r, g, b int) int {
+	best := 0
+	for i := 1; i < 16; i++ {
+		if distance(r, g, b, i) < distance(r, g, b, best) {
+			best = i
+		}
+	}
+	return best
+}
+
+func distance(r, g, b, index int) int {
+	pr, pg, pb := PaletteRGB(index)
+	// Weighted for the eye's sensitivity to green
+	return 2*(r-pr)*(r-pr) + 4*(g-pg)*(g-pg) + 3*(b-pb)*(b-pb)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// FG returns SGR parameters for an RGB foreground colour at depth, or "" for DepthNone
+func FG(r, g, b int, depth Depth) string {
+	return colorParams(r, g, b, depth, false)
+}
+
+// BG is FG for the background
+func BG(r, g, b int, depth Depth) string {
+	return colorParams(r, g, b, depth, true)
+}
+
+func colorParams(r, g, b int, depth Depth, background bool) string {
+	base := 38
+	if background {
+		base = 48
+	}
+	switch depth {
+	case DepthTrue:
+		return fmt.Sprintf("%d;2;%d;%d;%d", base, r, g, b)
+	case Depth256:
+		return fmt.Sprintf("%d;5;%d", base, Nearest256(r, g, b))
+	case Depth16:
+		index := Nearest16(r, g, b)
+		code := base - 8 + index // 30-37, or 40-47 for the background
+		if index >= 8 {
+			code = base + 52 + index - 8 // 90-97, or 100-107
+		}
+		return strconv.Itoa(code)
+	}
+	return ""
+}
+
+// DownsampleSGR rewrites the foreground colour in SGR parameters to fit depth.
+// Colours the output already supports are returned unchanged; DepthNone
+// returns "".
+func DownsampleSGR(params string, depth Depth) string {
+	if depth == DepthNone || params == "" {
+		return ""
+	}
+	c, ok := ParseSGR(params)
+	if !ok || c.Depth <= depth {
+		return params
+	}
+	return FG(c.R, c.G, c.B, depth)
+}
+
+// Luminance returns the perceived brightness of an RGB colour, 0-255
+func Luminance(r, g, b int) int {
+	return (299*r + 587*g + 114*b) / 1000
+}
@@ -0,0 +1,142 @@
+package term
+
+import "testing"
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestDetectDepth(t *testing.T) {
+	tests := []struct {
+		name string
+		vars map[string]string
+		tty  bool
+		want Depth
+	}{
+		{"truecolor", map[string]string{"COLORTERM": "truecolor", "TERM": "xterm-256color"}, true, DepthTrue},
+		{"24bit", map[string]string{"COLORTERM": "24bit"}, true, DepthTrue},
+		{"256", map[string]string{"TERM": "xterm-256color"}, true, Depth256},
+		{"direct", map[string]string{"TERM": "xterm-direct"}, true, DepthTrue},
+		{"linux console", map[string]string{"TERM": "linux"}, true, Depth16},
+		{"dumb", map[string]string{"TERM": "dumb"}, true, DepthNone},
+		{"not a terminal", map[string]string{"COLORTERM": "truecolor"}, false, DepthNone},
+		{"NO_COLOR", map[string]string{"NO_COLOR": "1", "COLORTERM": "truecolor"}, true, DepthNone},
+		{"FORCE_COLOR without terminal", map[string]string{"FORCE_COLOR": "1"}, false, Depth16},
+		{"FORCE_COLOR over NO_COLOR", map[string]string{"FORCE_COLOR": "3", "NO_COLOR": "1"}, false, DepthTrue},
+		{"FORCE_COLOR=2", map[string]string{"FORCE_COLOR": "2"}, false, Depth256},
+		{"FORCE_COLOR=0", map[string]string{"FORCE_COLOR": "0", "COLORTERM": "truecolor"}, true, DepthNone},
+	}
+	for _, tt := range tests {
+		if got := DetectDepth(env(tt.vars), tt.tty); got != tt.want {
+			t.Errorf("%s: DetectDepth = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveDepth(t *testing.T) {
+	vars := env(map[string]string{"TERM": "xterm-256color"})
+	if got := resolveDepth(ColorNever, vars, true); got != DepthNone {
+		t.Errorf("never: got %s", got)
+	}
+	if got := resolveDepth(ColorAlways, vars, false); got != Depth256 {
+		t.Errorf("always: got %s, want 256", got)
+	}
+	if got := resolveDepth(ColorAlways, env(map[string]string{"TERM": "dumb"}), false); got != Depth16 {
+		t.Errorf("always on a dumb terminal: got %s, want 16", got)
+	}
+	if got := resolveDepth(ColorAuto, vars, false); got != DepthNone {
+		t.Errorf("auto without a terminal: got %s", got)
+	}
+	if err := SetColorMode("sometimes"); err == nil {
+		t.Error("Expected an error for an invalid color mode")
+	}
+}
+
+func TestNearest(t *testing.T) {
+	tests := []struct {
+		r, g, b int
+		want256 int
+		want16  int
+	}{
+		{255, 0, 0, 196, 9},
+		{0, 0, 0, 16, 0},
+		{255, 255, 255, 231, 15},
+		{128, 128, 128, 244, 8},
+		{255, 135, 0, 208, 3},
+	}
+	for _, tt := range tests {
+		if got := Nearest256(tt.r, tt.g, tt.b); got != tt.want256 {
+			t.Errorf("Nearest256(%d,%d,%d) = %d, want %d", tt.r, tt.g, tt.b, got, tt.want256)
+		}
+		if got := Nearest16(tt.r, tt.g, tt.b); got != tt.want16 {
+			t.Errorf("Nearest16(%d,%d,%d) = %d, want %d", tt.r, tt.g, tt.b, got, tt.want16)
+		}
+	}
+}
+
+func TestFG(t *testing.T) {
+	tests := []struct {
+		depth Depth
+		fg    string
+		bg    string
+	}{
+		{DepthTrue, "38;2;255;0;0", "48;2;255;0;0"},
+		{Depth256, "38;5;196", "48;5;196"},
+		{Depth16, "91", "101"},
+		{DepthNone, "", ""},
+	}
+	for _, tt := range tests {
+		if got := FG(255, 0, 0, tt.depth); got != tt.fg {
+			t.Errorf("FG at %s = %q, want %q", tt.depth, got, tt.fg)
+		}
+		if got := BG(255, 0, 0, tt.depth); got != tt.bg {
+			t.Errorf("BG at %s = %q, want %q", tt.depth, got, tt.bg)
+		}
+	}
+	if got := FG(0, 0, 205, Depth16); got != "34" {
+		t.Errorf("FG blue at 16 = %q, want 34", got)
+	}
+}
+
+func TestParseSGR(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Color
+		ok   bool
+	}{
+		{"32", Color{Depth: Depth16, Index: 2, R: 0, G: 205, B: 0}, true},
+		{"1;93", Color{Depth: Depth16, Index: 11, R: 255, G: 255, B: 0}, true},
+		{"38;5;208", Color{Depth: Depth256, Index: 208, R: 255, G: 135, B: 0}, true},
+		{"38;2;1;2;3", Color{Depth: DepthTrue, R: 1, G: 2, B: 3}, true},
+		{"38;5;300", Color{}, false},
+		{"bold", Color{}, false},
+		{"1", Color{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseSGR(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseSGR(%q) = %+v, %v, want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDownsampleSGR(t *testing.T) {
+	tests := []struct {
+		in    string
+		depth Depth
+		want  string
+	}{
+		{"38;2;255;0;0", Depth256, "38;5;196"},
+		{"38;2;255;0;0", Depth16, "91"},
+		{"38;5;208", Depth16, "33"},
+		{"38;5;208", DepthTrue, "38;5;208"},
+		{"35", Depth16, "35"},
+		{"35", DepthNone, ""},
+		{"1", Depth16, "1"},
+	}
+	for _, tt := range tests {
+		if got := DownsampleSGR(tt.in, tt.depth); got != tt.want {
+			t.Errorf("DownsampleSGR(%q, %s) = %q, want %q", tt.in, tt.depth, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package term
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Graphics protocols for pixel art. Blocks and braille are text and work
+// everywhere; the others send real images.
+const (
+	GraphicsAuto    = "auto"    // detect from the environment
+	GraphicsBlocks  = "blocks"  // half-block characters, 2 pixels per cell
+	GraphicsBraille = "braille" // braille dots, 8 pixels per cell in one colour
+	GraphicsKitty   = "kitty"   // kitty graphics protocol (kitty, Ghostty)
+	GraphicsITerm   = "iterm"   // iTerm2 inline images (iTerm2, WezTerm)
+	GraphicsSixel   = "sixel"   // DEC sixel (foot, mlterm, contour, xterm -ti vt340)
+)
+
+// DefaultCell is the cell size in pixels assumed when the terminal does not
+// report one
+var DefaultCell = [2]int{10, 20}
+
+var graphicsMode = GraphicsAuto
+
+// SetGraphicsMode sets the process-wide graphics protocol, from --graphics or
+// the pet's graphics setting. An empty mode is auto.
+func SetGraphicsMode(mode string) error {
+	switch mode {
+	case "":
+		graphicsMode = GraphicsAuto
+		return nil
+	case GraphicsAuto, GraphicsBlocks, GraphicsBraille, GraphicsKitty, GraphicsITerm, GraphicsSixel:
+		graphicsMode = mode
+		return nil
+	}
+	return fmt.Errorf("invalid graphics '%s' (expected auto, blocks, braille, kitty, iterm or sixel)", mode)
+}
+
+// GraphicsMode returns the graphics protocol as set, before detection
+func GraphicsMode() string {
+	return graphicsMode
+}
+
+// GraphicsProtocol returns the protocol to draw pixel art written to f with.
+// Images need a terminal and colour, so anything else gets blocks; braille
+// is text and is kept. A kitty or sixel protocol that was only detected is
+// confirmed with the terminal first (see ConfirmGraphics); one that was set
+// with --graphics or the pet's graphics setting is used as it is.
+func GraphicsProtocol(f *os.File) string {
+	confirm := func(protocol string) bool { return ConfirmGraphics(os.Stdin, f, protocol) }
+	return resolveGraphics(graphicsMode, os.Getenv, IsTerminal(f) && ColorDepth(f) != DepthNone, confirm)
+}
+
+func resolveGraphics(mode string, getenv func(string) string, capable bool, confirm func(string) bool) string {
+	switch {
+	case mode == GraphicsBraille:
+		return GraphicsBraille
+	case !capable:
+		return GraphicsBlocks
+	case mode == GraphicsAuto:
+		detected := DetectGraphics(getenv)
+		if (detected == GraphicsKitty || detected == GraphicsSixel) && !confirm(detected) {
+			return GraphicsBlocks
+		}
+		return detected
+	}
+	return mode
+}
+
+// confirmed caches ConfirmGraphics by protocol, as the terminal does not change
+var (
+	confirmedMu sync.Mutex
+	confirmed   = map[string]bool{}
+)
+
+// ConfirmGraphics asks the terminal on in and out whether it draws kitty or
+// sixel images, so a guess from the environment, such as a KITTY_WINDOW_ID
+// carried over ssh into another terminal, never sends escapes it would print.
+// The kitty query is answered by terminals with the protocol; sixel support is
+// in the device attributes every terminal reports. A terminal that does not
+// answer has neither. Keys typed while it waits are lost.
+func ConfirmGraphics(in, out *os.File, protocol string) bool {
+	confirmedMu.Lock()
+	defer confirmedMu.Unlock()
+	if ok, seen := confirmed[protocol]; seen {
+		return ok
+	}
+	ok := false
+	if IsTTY(in) && IsTTY(out) {
+		request := "\033[c"
+		if protocol == GraphicsKitty {
+			// A 1x1 image query, then the device attributes to end the reply
+			request = "\033_Gi=31,s=1,v=1,a=q,t=d,f=24;AAAA\033\\" + request
+		}
+		ok = parseGraphicsReply(protocol, query(in, out, request, func(reply string) bool {
+			return deviceAttributes.MatchString(reply)
+		}))
+	}
+	confirmed[protocol] = ok
+	return ok
+}
+
+// deviceAttributes matches a primary device attributes report, ESC [ ? 62 ; 4 c
+var deviceAttributes = regexp.MustCompile(`\033\[\?([0-9;]*)c`)
+
+// parseGraphicsReply reports whether the reply to ConfirmGraphics's request
+// says the terminal has protocol
+func parseGraphicsReply(protocol, reply string) bool {
+	switch protocol {
+	case GraphicsKitty:
+		return strings.Contains(reply, "\033_Gi=31;OK")
+	case GraphicsSixel:
+		m := deviceAttributes.FindStringSubmatch(reply)
+		if m == nil {
+			return false
+		}
+		for _, attr := range strings.Split(m[1], ";") {
+			if attr == "4" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DetectGraphics guesses the terminal's image protocol from TERM, TERM_PROGRAM
+// and the variables terminals export. Multiplexers such as tmux and screen do
+// not pass images through, so they get blocks.
+func DetectGraphics(getenv func(string) string) string {
+	t := strings.ToLower(getenv("TERM"))
+	program := strings.ToLower(getenv("TERM_PROGRAM"))
+	switch {
+	case getenv("TMUX") != "" || getenv("STY") != "" || strings.HasPrefix(t, "screen") || strings.HasPrefix(t, "tmux"):
+		return GraphicsBlocks
+	case t == "xterm-kitty" || getenv("KITTY_WINDOW_ID") != "" || t == "xterm-ghostty" || program == "ghostty":
+		return GraphicsKitty
+	case program == "iterm.app" || getenv("LC_TERMINAL") == "iTerm2" || program == "wezterm":
+		return GraphicsITerm
+	case strings.HasPrefix(t, "foot") || strings.HasPrefix(t, "mlterm") || strings.Contains(t, "sixel") || program == "contour":
+		return GraphicsSixel
+	}
+	return GraphicsBlocks
+}
+
+// CellSize returns the size in pixels of a character cell of the terminal
+// attached to f, or DefaultCell when it does not say
+func CellSize(f *os.File) (width, height int) {
+	if w, h := windowCell(f); w > 0 && h > 0 {
+		return w, h
+	}
+	return DefaultCell[0], DefaultCell[1]
+}
@@ -0,0 +1,74 @@
+package term
+
+import "testing"
+
+func TestDetectGraphics(t *testing.T) {
+	tests := []struct {
+		name string
+		vars map[string]string
+		want string
+	}{
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, GraphicsKitty},
+		{"kitty over ssh", map[string]string{"TERM": "xterm-256color", "KITTY_WINDOW_ID": "1"}, GraphicsKitty},
+		{"ghostty", map[string]string{"TERM": "xterm-ghostty"}, GraphicsKitty},
+		{"iTerm2", map[string]string{"TERM_PROGRAM": "iTerm.app"}, GraphicsITerm},
+		{"iTerm2 over ssh", map[string]string{"LC_TERMINAL": "iTerm2"}, GraphicsITerm},
+		{"wezterm", map[string]string{"TERM_PROGRAM": "WezTerm"}, GraphicsITerm},
+		{"foot", map[string]string{"TERM": "foot-extra"}, GraphicsSixel},
+		{"mlterm", map[string]string{"TERM": "mlterm"}, GraphicsSixel},
+		{"tmux in kitty", map[string]string{"TERM": "tmux-256color", "KITTY_WINDOW_ID": "1", "TMUX": "/tmp/tmux"}, GraphicsBlocks},
+		{"xterm", map[string]string{"TERM": "xterm-256color"}, GraphicsBlocks},
+	}
+	for _, tt := range tests {
+		if got := DetectGraphics(env(tt.vars)); got != tt.want {
+			t.Errorf("%s: DetectGraphics = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveGraphics(t *testing.T) {
+	kitty := env(map[string]string{"TERM": "xterm-kitty"})
+	yes := func(string) bool { return true }
+	no := func(string) bool { return false }
+	if got := resolveGraphics(GraphicsAuto, kitty, true, yes); got != GraphicsKitty {
+		t.Errorf("auto in kitty = %s", got)
+	}
+	if got := resolveGraphics(GraphicsAuto, kitty, true, no); got != GraphicsBlocks {
+		t.Errorf("Expected blocks when the terminal does not confirm kitty, got %s", got)
+	}
+	if got := resolveGraphics(GraphicsAuto, env(map[string]string{"TERM_PROGRAM": "WezTerm"}), true, no); got != GraphicsITerm {
+		t.Errorf("Expected iTerm images without a query, got %s", got)
+	}
+	if got := resolveGraphics(GraphicsSixel, kitty, true, no); got != GraphicsSixel {
+		t.Errorf("Expected the setting to override detection unconfirmed, got %s", got)
+	}
+	if got := resolveGraphics(GraphicsKitty, kitty, false, yes); got != GraphicsBlocks {
+		t.Errorf("Expected blocks without a colour terminal, got %s", got)
+	}
+	if got := resolveGraphics(GraphicsBraille, kitty, false, no); got != GraphicsBraille {
+		t.Errorf("Expected braille to work without a terminal, got %s", got)
+	}
+	if err := SetGraphicsMode("png"); err == nil {
+		t.Error("Expected an error for an unknown protocol")
+	}
+}
+
+func TestParseGraphicsReply(t *testing.T) {
+	tests := []struct {
+		protocol, reply string
+		want            bool
+	}{
+		{GraphicsKitty, "\033_Gi=31;OK\033\\\033[?62;c", true},
+		{GraphicsKitty, "\033_Gi=31;ENOTSUPPORTED:\033\\\033[?62;c", false},
+		{GraphicsKitty, "\033[?62;4;22c", false},
+		{GraphicsSixel, "\033[?62;4;22c", true},
+		{GraphicsSixel, "\033[?64;1;2;6;9;15;18;21;22c", false},
+		{GraphicsSixel, "\033[?1;2c", false},
+		{GraphicsSixel, "", false},
+	}
+	for _, tt := range tests {
+		if got := parseGraphicsReply(tt.protocol, tt.reply); got != tt.want {
+			t.Errorf("parseGraphicsReply(%s, %q) = %v, want %v", tt.protocol, tt.reply, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package term
+
+import "os"
+
+func windowWidth(f *os.File) int {
+	return 0
+}
+
+func windowCell(f *os.File) (int, int) {
+	return 0, 0
+}
+
+func windowRows(f *os.File) int {
+	return 0
+}
+
+func notifyResize(c chan<- os.Signal) {}
+
+func cursorRow(in, out *os.File) int {
+	return 0
+}
+
+func query(in, out *os.File, request string, done func(reply string) bool) string {
+	return ""
+}
+
+func isTTY(f *os.File) bool {
+	return IsTerminal(f)
+}
@@ -0,0 +1,101 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package term
+
+import (
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+type winsize struct {
+	Row, Col, Xpixel, Ypixel uint16
+}
+
+func windowSize(f *os.File) (winsize, bool) {
+	var ws winsize
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	return ws, errno == 0
+}
+
+func windowWidth(f *os.File) int {
+	ws, ok := windowSize(f)
+	if !ok {
+		return 0
+	}
+	return int(ws.Col)
+}
+
+func windowRows(f *os.File) int {
+	ws, ok := windowSize(f)
+	if !ok {
+		return 0
+	}
+	return int(ws.Row)
+}
+
+// notifyResize relays SIGWINCH, sent when the window changes size, to c
+func notifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
+
+// windowCell returns the size of a character cell in pixels, or zero when the
+// terminal does not report its pixel size
+func windowCell(f *os.File) (int, int) {
+	ws, ok := windowSize(f)
+	if !ok || ws.Col == 0 || ws.Row == 0 {
+		return 0, 0
+	}
+	return int(ws.Xpixel / ws.Col), int(ws.Ypixel / ws.Row)
+}
+
+func termios(f *os.File, req uintptr, t *syscall.Termios) bool {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, uintptr(unsafe.Pointer(t)))
+	return errno == 0
+}
+
+// isTTY reports whether f has terminal modes to get
+func isTTY(f *os.File) bool {
+	var t syscall.Termios
+	return termios(f, getTermios, &t)
+}
+
+// cursorRow asks for the cursor position on out and reads the reply from in
+func cursorRow(in, out *os.File) int {
+	reply := query(in, out, "\033[6n", func(reply string) bool { return strings.Contains(reply, "R") })
+	return parseCursorReply(reply)
+}
+
+// query turns off line buffering and echo on in, writes request to out and
+// reads the reply until done says it is complete, waiting at most 0.2s for
+// each part of it
+func query(in, out *os.File, request string, done func(reply string) bool) string {
+	var saved syscall.Termios
+	if !termios(in, getTermios, &saved) {
+		return ""
+	}
+	raw := saved
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO
+	raw.Cc[syscall.VMIN] = 0
+	raw.Cc[syscall.VTIME] = 2
+	if !termios(in, setTermios, &raw) {
+		return ""
+	}
+	defer termios(in, setTermios, &saved)
+
+	if _, err := out.WriteString(request); err != nil {
+		return ""
+	}
+	var reply []byte
+	buf := make([]byte, 64)
+	for len(reply) < 256 && !done(string(reply)) {
+		n, err := in.Read(buf)
+		if n == 0 || err != nil {
+			break
+		}
+		reply = append(reply, buf[:n]...)
+	}
+	return string(reply)
+}
@@ -0,0 +1,171 @@
+package term
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultWidth is used when the terminal width cannot be determined
+const DefaultWidth = 80
+
+// IsTerminal reports whether f is attached to a terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// IsTTY reports whether f is a real terminal, one that has terminal modes.
+// Unlike IsTerminal it is false for other character devices such as /dev/null.
+func IsTTY(f *os.File) bool {
+	return isTTY(f)
+}
+
+// IsRedirected reports whether f is a pipe or a regular file, as stdin is for
+// "cmd | familiar" or "familiar < file". A terminal, /dev/null or a closed
+// stdin under cron or a hook is not.
+func IsRedirected(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeNamedPipe != 0 || info.Mode().IsRegular()
+}
+
+// Width returns the column count of the terminal attached to f. $COLUMNS
+// takes precedence; DefaultWidth is returned when neither is available.
+func Width(f *os.File) int {
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
+	if cols := windowWidth(f); cols > 0 {
+		return cols
+	}
+	return DefaultWidth
+}
+
+// Height returns the row count of the terminal attached to f. $LINES takes
+// precedence; zero is returned when neither is available.
+func Height(f *os.File) int {
+	if rows, err := strconv.Atoi(os.Getenv("LINES")); err == nil && rows > 0 {
+		return rows
+	}
+	return windowRows(f)
+}
+
+// NotifyResize relays to c each time the terminal is resized, until
+// signal.Stop(c). Where resizes cannot be watched it does nothing.
+func NotifyResize(c chan<- os.Signal) {
+	notifyResize(c)
+}
+
+// CursorRow asks the terminal on in and out which row the cursor is on, 1
+// being the top, and returns zero when it does not answer. Keys typed while
+// it waits are lost.
+func CursorRow(in, out *os.File) int {
+	if !IsTTY(in) || !IsTTY(out) {
+		return 0
+	}
+	return cursorRow(in, out)
+}
+
+// parseCursorReply reads the row from a cursor position report, ESC [ row ; col R
+func parseCursorReply(reply string) int {
+	i := strings.LastIndex(reply, "\033[")
+	if i < 0 {
+		return 0
+	}
+	end := strings.IndexByte(reply[i:], 'R')
+	if end < 0 {
+		return 0
+	}
+	fields := strings.SplitN(reply[i+2:i+end], ";", 2)
+	if len(fields) != 2 {
+		return 0
+	}
+	row, err := strconv.Atoi(fields[0])
+	if err != nil || row < 1 {
+		return 0
+	}
+	return row
+}
+
+// StripANSI removes CSI (colour, cursor), OSC (hyperlink, title) and DCS or
+// APC (sixel, kitty images) escape sequences
+func StripANSI(s string) string {
+	if !strings.Contains(s, "\033") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\033' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '[':
+			// CSI: parameters, then a final byte in 0x40-0x7e
+			j := i + 2
+			for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+				j++
+			}
+			i = j
+		case ']':
+			// OSC: terminated by BEL or ESC \
+			j := i + 2
+			for j < len(s) && s[j] != '\a' && !(s[j] == '\033' && j+1 < len(s) && s[j+1] == '\\') {
+				j++
+			}
+			if j < len(s) && s[j] == '\033' {
+				j++
+			}
+			i = j
+		case 'P', '_', '^', 'X':
+			// DCS, APC, PM and SOS: terminated by ESC \
+			j := i + 2
+			for j < len(s) && !(s[j] == '\033' && j+1 < len(s) && s[j+1] == '\\') {
+				j++
+			}
+			i = j + 1
+		default:
+			i++
+		}
+	}
+	return b.String()
+}
+
+// VisibleWidth returns the number of columns s occupies once escape sequences
+// are removed, counting wide runes (CJK, most emoji) as two columns
+func VisibleWidth(s string) int {
+	width := 0
+	for _, r := range StripANSI(s) {
+		width += RuneWidth(r)
+	}
+	return width
+}
+
+// RuneWidth approximates the columns a rune occupies: 0 for combining marks and
+// joiners, 2 for East Asian wide characters and emoji, 1 otherwise
+func RuneWidth(r rune) int {
+	switch {
+	case r == 0x200d || (r >= 0xfe00 && r <= 0xfe0f) || unicode.Is(unicode.Mn, r):
+		return 0
+	case r >= 0x1100 && r <= 0x115f,
+		r >= 0x2e80 && r <= 0xa4cf,
+		r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff,
+		r >= 0xfe30 && r <= 0xfe4f,
+		r >= 0xff00 && r <= 0xff60,
+		r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f300 && r <= 0x1f64f,
+		r >= 0x1f900 && r <= 0x1f9ff,
+		r >= 0x1fa70 && r <= 0x1faff,
+		r >= 0x20000 && r <= 0x3fffd:
+		return 2
+	}
+	return 1
+}
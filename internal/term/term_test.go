@@ -0,0 +1,57 @@
+package term
+
+import "testing"
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"\033[1mbold\033[0m", "bold"},
+		{"\033[38;2;1;2;3m▀\033[0m", "▀"},
+		{"\033]8;;https://example.com\033\\link\033]8;;\033\\", "link"},
+		{"\033]0;title\a after", " after"},
+		{"\033_Ga=p,i=1;\033\\  ", "  "},
+		{"\0337\033Pq#0;2;100;0;0#0~\033\\\0338x", "x"},
+	}
+	for _, tt := range tests {
+		if got := StripANSI(tt.in); got != tt.want {
+			t.Errorf("StripANSI(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVisibleWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"hello", 5},
+		{"\033[32m●\033[0m", 1},
+		{"🐾 ok", 5},
+		{"猫", 2},
+		{"é", 1},
+	}
+	for _, tt := range tests {
+		if got := VisibleWidth(tt.in); got != tt.want {
+			t.Errorf("VisibleWidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseCursorReply(t *testing.T) {
+	tests := map[string]int{
+		"\033[12;40R": 12,
+		"x\033[1;1Ry": 1,
+		"\033[12;40":  0,
+		"\033[;40R":   0,
+		"":            0,
+		"\033[0;1R":   0,
+	}
+	for reply, want := range tests {
+		if got := parseCursorReply(reply); got != want {
+			t.Errorf("parseCursorReply(%q) = %d, want %d", reply, got, want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package watch
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+// Hit is a watch that matched at least one changed file
+type Hit struct {
+	Watch pet.WatchConfig
+	Files []string
+}
+
+// Evaluate returns the watches matching any of the changed files, in config order
+func Evaluate(watches []pet.WatchConfig, changed []string) []Hit {
+	var hits []Hit
+	for _, w := range watches {
+		var files []string
+		for _, f := range changed {
+			for _, pattern := range w.Paths {
+				if Match(pattern, f) {
+					files = append(files, f)
+					break
+				}
+			}
+		}
+		if len(files) > 0 {
+			sort.Strings(files)
+			hits = append(hits, Hit{Watch: w, Files: files})
+		}
+	}
+	return hits
+}
+
+// Match reports whether a slash-separated file path matches a glob pattern.
+// Patterns use path.Match syntax plus "**" for any number of directories.
+// A pattern without a slash matches the file name in any directory.
+func Match(pattern, file string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	file = strings.TrimPrefix(file, "./")
+
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(file))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+func matchSegments(pattern, file []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// "**" swallows zero or more directories
+			for i := 0; i <= len(file); i++ {
+				if matchSegments(pattern[1:], file[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(file) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], file[0]); !ok {
+			return false
+		}
+		pattern, file = pattern[1:], file[1:]
+	}
+	return len(file) == 0
+}
+
+// RenderMessage fills the placeholders of a watch's message template:
+// {{FILES}} (comma-separated), {{COUNT}}, {{FROM}} and {{TO}} (short commit hashes)
+func RenderMessage(hit Hit, from, to string) string {
+	text := hit.Watch.Message
+	if text == "" {
+		text = "Watched files changed: {{FILES}}"
+	}
+	text = strings.ReplaceAll(text, "{{FILES}}", strings.Join(hit.Files, ", "))
+	text = strings.ReplaceAll(text, "{{COUNT}}", strconv.Itoa(len(hit.Files)))
+	text = strings.ReplaceAll(text, "{{FROM}}", shortHash(from))
+	text = strings.ReplaceAll(text, "{{TO}}", shortHash(to))
+	return text
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,57 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/sethgrid/familiar/internal/pet"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{"config/*.yaml", "config/app.yaml", true},
+		{"config/*.yaml", "config/sub/app.yaml", false},
+		{"config/**/*.yaml", "config/sub/deep/app.yaml", true},
+		{"config/**/*.yaml", "config/app.yaml", true},
+		{"**/go.mod", "go.mod", true},
+		{"**/go.mod", "tools/go.mod", true},
+		{"*.yaml", "config/app.yml", false},
+		{".env.example", "services/api/.env.example", true},
+		{"docker-compose*.yml", "docker-compose.override.yml", true},
+		{"./Makefile", "Makefile", true},
+		{"src/*.go", "lib/main.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateAndRender(t *testing.T) {
+	watches := []pet.WatchConfig{
+		{Paths: []string{"config/*.toml"}, Message: "{{COUNT}} config file(s) changed: {{FILES}} ({{FROM}}..{{TO}})"},
+		{Paths: []string{"docs/**"}, Message: "docs changed"},
+		{Paths: []string{"*.sql"}},
+	}
+	changed := []string{"config/b.toml", "README.md", "config/a.toml", "db/001.sql"}
+
+	hits := Evaluate(watches, changed)
+	if len(hits) != 2 {
+		t.Fatalf("Expected 2 hits, got %d: %+v", len(hits), hits)
+	}
+
+	got := RenderMessage(hits[0], "0123456789abcdef", "fedcba9876543210")
+	want := "2 config file(s) changed: config/a.toml, config/b.toml (0123456..fedcba9)"
+	if got != want {
+		t.Errorf("RenderMessage = %q, want %q", got, want)
+	}
+
+	if got := RenderMessage(hits[1], "a", "b"); got != "Watched files changed: db/001.sql" {
+		t.Errorf("Default message = %q", got)
+	}
+}